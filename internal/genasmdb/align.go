@@ -0,0 +1,153 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("alignment", "alignment.json", "write the %s forms requiring aligned memory and their alignment as JSON to `file`", []*AlignmentForm{}, x86AlignmentForm, armAlignmentForm)
+}
+
+// Alignment represents the alignment of the memory address required by an instruction form,
+// the form faults if the address isn't aligned.
+type Alignment struct {
+	// Operand is the index of the memory operand, or -1 for the stack of the ARM "push" and "pop".
+	Operand int `json:"operand"`
+
+	// Bytes is the required alignment of the address in bytes, a power of two.
+	Bytes int `json:"bytes"`
+}
+
+// Check returns the error of the address which isn't aligned to a.Bytes, or nil.
+func (a *Alignment) Check(addr uint64) error {
+	if addr&uint64(a.Bytes-1) != 0 {
+		return fmt.Errorf("address %#x not aligned to %d bytes", addr, a.Bytes)
+	}
+	return nil
+}
+
+// x86AlignedNames is the list of the VEX and EVEX forms requiring the alignment of the memory operand to its width,
+// the aligned moves and the non-temporal moves. The other VEX and EVEX forms accept any alignment.
+var x86AlignedNames = map[string]bool{
+	"vmovaps": true, "vmovapd": true, "vmovdqa": true, "vmovdqa32": true, "vmovdqa64": true,
+	"vmovntps": true, "vmovntpd": true, "vmovntdq": true, "vmovntdqa": true,
+}
+
+// x86UnalignedNames is the list of the legacy SSE forms accepting any alignment of the 128-bit memory operand.
+var x86UnalignedNames = map[string]bool{
+	"movups": true, "movupd": true, "movdqu": true, "lddqu": true,
+	"pcmpestri": true, "pcmpestrm": true, "pcmpistri": true, "pcmpistrm": true,
+}
+
+// x86FixedAlignments is the alignment in bytes of the memory operand of the forms requiring the fixed alignment,
+// like the 16 bytes of "fxsave" and the 64 bytes of the destination of "movdir64b".
+var x86FixedAlignments = map[string]int{
+	"cmpxchg16b": 16,
+	"fxsave":     16, "fxsave64": 16, "fxrstor": 16, "fxrstor64": 16,
+	"xsave": 64, "xsave64": 64, "xsavec": 64, "xsavec64": 64, "xsaveopt": 64, "xsaveopt64": 64,
+	"xsaves": 64, "xsaves64": 64, "xrstor": 64, "xrstor64": 64, "xrstors": 64, "xrstors64": 64,
+	"movdir64b": 64, "enqcmd": 64, "enqcmds": 64,
+}
+
+// Alignment returns the alignment of the memory address required by the instruction, or nil if the form
+// doesn't access memory or accepts any alignment.
+//
+// The legacy SSE forms of the XMM registers require the alignment of the 128-bit memory operand unless listed as unaligned, like "movups",
+// the VEX and EVEX forms only for the aligned and the non-temporal moves, and some forms require the fixed alignment.
+// The alignment checking of the other accesses, enabled by the AC flag, isn't modeled.
+func (x *X86) Alignment(inst *X86Instruction) (*Alignment, error) {
+	name := inst.Names()[0]
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	op, err := inst.ParseOpCode()
+	if err != nil {
+		return nil, err
+	}
+
+	sse := false
+	for _, o := range ops {
+		for _, kind := range o.Kinds {
+			sse = sse || x86RegClass(kind) == "xmm"
+		}
+	}
+	for _, o := range ops {
+		if !o.IsMem() || o.Implicit {
+			continue
+		}
+		if bytes, ok := x86FixedAlignments[name]; ok {
+			return &Alignment{Operand: o.Index, Bytes: bytes}, nil
+		}
+		for _, kind := range o.Kinds {
+			size := x86MemSizes[kind]
+			if size < 16 {
+				continue
+			}
+			if x86AlignedNames[name] || sse && op.Prefix == "" && size == 16 && !x86UnalignedNames[name] {
+				return &Alignment{Operand: o.Index, Bytes: size}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Alignment returns the alignment of the memory address required by the instruction, or nil if the form
+// doesn't access memory or accepts any alignment.
+//
+// The exclusive and the acquire and release forms, like "ldrex" and "stl", require the natural alignment of
+// the access, and the forms accessing several words, like "ldm", "push", "ldrd" and "swp", the word alignment.
+// The single loads and stores, like "ldr", accept any alignment unless the alignment checking is enabled.
+func (a *Arm) Alignment(inst *ArmInstruction) (*Alignment, error) {
+	accesses, err := a.MemoryAccesses(inst)
+	if err != nil || len(accesses) == 0 {
+		return nil, err
+	}
+	access := accesses[0]
+
+	mnemonic, _ := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	switch {
+	case strings.HasPrefix(mnemonic, "ldaex"), strings.HasPrefix(mnemonic, "stlex"),
+		strings.HasPrefix(mnemonic, "ldrex"), strings.HasPrefix(mnemonic, "strex"),
+		strings.HasPrefix(mnemonic, "lda"), strings.HasPrefix(mnemonic, "stl"):
+		return &Alignment{Operand: access.Operand, Bytes: access.Widths[0] / 8}, nil
+	case access.Repeated, access.Widths[0] == 64, mnemonic == "swp", mnemonic == "push", mnemonic == "pop":
+		return &Alignment{Operand: access.Operand, Bytes: 4}, nil
+	}
+	return nil, nil
+}
+
+// AlignmentForm represents an instruction form requiring the aligned memory.
+type AlignmentForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "RM", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode    string     `json:"opcode"`
+	Alignment *Alignment `json:"alignment"`
+}
+
+// x86AlignmentForm returns the form of inst if it requires the aligned memory, or nil.
+func x86AlignmentForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	align, err := x.Alignment(inst)
+	if err != nil || align == nil {
+		return nil, err
+	}
+	return &AlignmentForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Alignment: align}, nil
+}
+
+// armAlignmentForm returns the form of inst if it requires the aligned memory, or nil.
+func armAlignmentForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	align, err := a.Alignment(inst)
+	if err != nil || align == nil {
+		return nil, err
+	}
+	return &AlignmentForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Alignment: align}, nil
+}
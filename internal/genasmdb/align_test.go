@@ -0,0 +1,62 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86Alignment(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *Alignment
+	}{
+		// the legacy SSE forms
+		{"addps", "X:~xmm, ~xmm/m128", &Alignment{Operand: 1, Bytes: 16}},
+		{"add", "X:~r32,~r32/m32", nil},
+		// x86UnalignedNames
+		{"movups", "W:xmm/m128, xmm", nil},
+		// x86AlignedNames
+		{"vmovaps", "W:ymm/m256, ymm", &Alignment{Operand: 0, Bytes: 32}},
+		{"vaddps", "W:ymm,~ymm,~ymm/m256", nil},
+		// x86FixedAlignments
+		{"fxsave", "W:mem", &Alignment{Operand: 0, Bytes: 16}},
+		{"movdir64b", "W:es:r64, m512", &Alignment{Operand: 0, Bytes: 64}},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.Alignment(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Alignment(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
+
+func TestArmAlignment(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     *Alignment
+	}{
+		// the exclusive and the acquire and release forms
+		{"ldrex", "Rd!=PC, [Rn!=PC]", ArmA32, &Alignment{Operand: 1, Bytes: 4}},
+		{"ldaexb", "Rd!=PC, [Rn!=PC]", ArmA32, &Alignment{Operand: 1, Bytes: 1}},
+		// the forms accessing several words
+		{"ldm", "[Rn!=PC]{!}, RdList", ArmT32, &Alignment{Operand: 0, Bytes: 4}},
+		{"ldrd", "Rd!=XX, Rd2!=XX  , [Rn!=PC, #+/-ImmZ*4]{!}", ArmT32, &Alignment{Operand: 2, Bytes: 4}},
+		{"swp", "Rd!=PC, Rs!=PC, [Rn!=PC]", ArmA32, &Alignment{Operand: 2, Bytes: 4}},
+		{"push", "Rs!=SP", ArmA32, &Alignment{Operand: -1, Bytes: 4}},
+		{"ldr", "Rd!=HI, [Rn!=HI, #ImmZ*4]", ArmT16, nil},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.Alignment(testArmForm(t, tt.name, tt.operands, tt.arch))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Alignment(%s %s %s) = %+v, %v, want %+v", tt.arch, tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
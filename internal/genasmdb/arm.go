@@ -3,4 +3,148 @@
 
 package main
 
-// TODO(zchee): implements types.
+// armdata.js
+//
+// ARM instruction-set data.
+//
+// License
+//
+// Public Domain.
+//
+//
+// INSTRUCTION TUPLE
+//
+// Each instruction tuple consists of 5 strings:
+//
+//   [0] - Instruction name.
+//   [1] - Instruction operands.
+//   [2] - Instruction type (specifies instruction's layout and architecture as well).
+//   [3] - Instruction opcode (fields separated by '|' forming the instruction word or halfword).
+//   [4] - Instruction metadata - CPU requirements, APSR (read/write), and other metadata.
+//
+// The fields should match ARM instruction reference manual as possible, however,
+// it's allowed to make changes that make parsing easier and data more consistent.
+//
+//
+// INSTRUCTION OPERANDS
+//
+// Instruction operands contain standard operand field(s) as defined by ARM
+// instruction reference, and also additional metadata that is defined by
+// ARM, but in notes section (instead of instruction format section). Additional
+// data include:
+//
+//   - "R?!=HI" - The register cannot be R8..R15 (most T16 instructions).
+//   - "R?!=PC" - The register cannot be R15 (PC).
+//   - "R?!=SP" - The register cannot be R13 (SP).
+//   - "R?!=XX" - The register cannot be R13 (SP) or R15 (PC).
+//   - "??<=07" - The register must be from 0..7  (some ASIMD instructions).
+//   - "??<=15" - The register must be from 0..15 (some ASIMD instructions).
+//
+// Also, all instructions that use T16 layout were normalized into 3 operand
+// form to make these compatible with T32 and A32 architectures. These are easily
+// recognizable as they always share the first two operands.
+//
+//
+// WHAT IS MISSING
+//
+// The architectures list "A64", but armdata.js doesn't define any A64 instruction
+// yet, only the A64 register classes ("w", "x" and "v"). The A64 forms are loaded
+// the same way once they are added to the data.
+
+// Arm instruction sets, the type of the instruction tuple.
+const (
+	// ArmT16 is the 16-bit Thumb instruction set.
+	ArmT16 = "T16"
+	// ArmT32 is the 32-bit Thumb-2 instruction set.
+	ArmT32 = "T32"
+	// ArmA32 is the 32-bit ARM instruction set.
+	ArmA32 = "A32"
+	// ArmA64 is the 64-bit AArch64 instruction set.
+	ArmA64 = "A64"
+)
+
+// Arm represents an ARM instruction set data.
+type Arm struct {
+	Architectures []string                    `json:"architectures"`
+	CPULevels     []*ArmCPULevel              `json:"cpuLevels"`
+	Extensions    []*ArmExtension             `json:"extensions"`
+	Attributes    []*ArmAttribute             `json:"attributes"`
+	SpecialRegs   []*ArmSpecialReg            `json:"specialRegs"`
+	Shortcuts     []*ArmShortcut              `json:"shortcuts"`
+	Registers     map[string]*ArmRegisterData `json:"registers"`
+	Instructions  [][5]string                 `json:"instructions,omitempty"`
+}
+
+// ArmCPULevel represents an ARM architecture version, like "ARMv7".
+type ArmCPULevel struct {
+	Name string `json:"name"`
+}
+
+// ArmExtension represents a available extension, instruction can specify extension in metadata.
+type ArmExtension struct {
+	Name string `json:"name"`
+
+	// From is the architecture version the extension is part of, like "ARMv8+".
+	From string `json:"from"`
+}
+
+// ArmAttribute represents a available attribute, instruction can specify attribute in metadata.
+type ArmAttribute struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Doc  string `json:"doc"`
+}
+
+// ArmSpecialReg represents a special registers (and their parts) that instructions can read/write to/from.
+type ArmSpecialReg struct {
+	Name  string `json:"name"`
+	Group string `json:"group"`
+	Doc   string `json:"doc"`
+}
+
+// ArmShortcut represents a shortcuts that can be used inside instruction's metadata, these shortcuts then expand to the expand key.
+type ArmShortcut struct {
+	Name   string `json:"name"`
+	Expand string `json:"expand"`
+}
+
+// ArmRegisterData represents an ARM register class data, like "r" and "x".
+type ArmRegisterData struct {
+	Names []string `json:"names"`
+	Kind  string   `json:"kind"`
+	Any   string   `json:"any,omitempty"`
+}
+
+// ArmInstruction represents an ARM instruction set.
+type ArmInstruction struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitempty"`
+
+	// Arch is the instruction set of the encoding, one of ArmT16, ArmT32, ArmA32 and ArmA64.
+	Arch string `json:"arch"`
+
+	OpCode   string `json:"opcode"`
+	Metadata string `json:"metadata"`
+}
+
+// newArmInstruction returns the typed instruction of the instruction tuple.
+func newArmInstruction(tuple [5]string) ArmInstruction {
+	return ArmInstruction{
+		Name:     tuple[0],
+		Operands: tuple[1],
+		Arch:     tuple[2],
+		OpCode:   tuple[3],
+		Metadata: tuple[4],
+	}
+}
+
+// armInstructionsOf returns the instructions of the instruction set arch, like ArmA64.
+func armInstructionsOf(insts []ArmInstruction, arch string) []ArmInstruction {
+	var forms []ArmInstruction
+	for _, inst := range insts {
+		if inst.Arch == arch {
+			forms = append(forms, inst)
+		}
+	}
+	return forms
+}
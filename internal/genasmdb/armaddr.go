@@ -0,0 +1,189 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+)
+
+// ARM load/store addressing modes.
+const (
+	// ArmAddrOffset is the offset addressing, "[Rn, offset]", the address is the base plus the offset
+	// and the base register is unchanged.
+	ArmAddrOffset = "offset"
+	// ArmAddrPreIndex is the pre-indexed addressing, "[Rn, offset]!", the address is the base plus the offset
+	// and it's written back to the base register.
+	ArmAddrPreIndex = "pre"
+	// ArmAddrPostIndex is the post-indexed addressing, "[Rn], offset", the address is the base
+	// and the base plus the offset is written back to the base register.
+	ArmAddrPostIndex = "post"
+	// ArmAddrLiteral is the PC relative literal addressing, "[PC, #offset]" or "label", the address is
+	// the PC aligned to 4 bytes plus the offset.
+	ArmAddrLiteral = "literal"
+)
+
+// ArmAddressing represents the addressing mode of the memory operand.
+//
+// armdata.js writes the offset, pre-indexed and post-indexed forms encoded with the "P" and "W" opcode bits
+// as the single "[Rn, offset]{!}" memory operand, and the unprivileged post-indexed forms, like "ldrt",
+// as "[Rn, offset]!".
+type ArmAddressing struct {
+	// Modes is the list of the addressing modes the memory operand is encoded with.
+	Modes []string `json:"modes"`
+
+	// Base is the base register element of the memory operand.
+	Base *ArmOperand `json:"base"`
+
+	// Imm is the immediate offset element, or nil.
+	Imm *ArmOperand `json:"imm,omitzero"`
+
+	// Index is the register offset element, or nil.
+	Index *ArmOperand `json:"index,omitzero"`
+
+	// Shift is the shift or the extend of the register offset, like "LSL #Shift", or nil.
+	Shift *ArmOperand `json:"shift,omitzero"`
+
+	// IndexBit, AddBit and WritebackBit are the opcode fields selecting the addressing mode, "P", "U" and "W",
+	// or empty if the opcode has no such field. "P" selects the offset or pre-indexed addressing (1)
+	// or the post-indexed addressing (0), "U" adds (1) or subtracts (0) the offset, and "W" writes back the address.
+	IndexBit     string `json:"indexBit,omitzero"`
+	AddBit       string `json:"addBit,omitzero"`
+	WritebackBit string `json:"writebackBit,omitzero"`
+
+	// thumb reports whether the form is T32, whose post-indexed addressing sets "W" unlike A32,
+	// where "P" 0 and "W" 1 select the unprivileged forms, like "ldrt".
+	thumb bool
+}
+
+// newArmAddressing returns the addressing mode of the memory operand op encoded with enc of the instruction set arch.
+//
+// The memory operand of the load and store multiple instructions has only the base register,
+// the written back address is the base plus or minus the size of the transferred registers.
+func newArmAddressing(op *ArmOperand, enc *ArmEncoding, arch string) (*ArmAddressing, error) {
+	if op.Type != ArmOperandMem || len(op.Mem) == 0 {
+		return nil, fmt.Errorf("operand %q is not a memory operand", op.Data)
+	}
+
+	addr := &ArmAddressing{Base: op.Mem[0], thumb: arch == ArmT32}
+	for _, elem := range op.Mem[1:] {
+		switch {
+		case elem.Type == ArmOperandImm && addr.Imm == nil && addr.Index == nil:
+			addr.Imm = elem
+		case elem.Type == ArmOperandReg && addr.Imm == nil && addr.Index == nil:
+			addr.Index = elem
+		case elem.Type == ArmOperandShift && addr.Index != nil && addr.Shift == nil:
+			addr.Shift = elem
+		default:
+			return nil, fmt.Errorf("unexpected element %q of memory operand %q", elem.Data, op.Data)
+		}
+	}
+	for _, f := range enc.Fields {
+		switch f.Name {
+		case "P":
+			addr.IndexBit = f.Name
+		case "U":
+			addr.AddBit = f.Name
+		case "W":
+			addr.WritebackBit = f.Name
+		}
+	}
+
+	offset := addr.Imm != nil || addr.Index != nil
+	switch {
+	case addr.isLiteral():
+		addr.Modes = []string{ArmAddrLiteral}
+	case addr.IndexBit != "" && addr.WritebackBit != "":
+		addr.Modes = []string{ArmAddrOffset, ArmAddrPreIndex, ArmAddrPostIndex}
+	case op.Writeback == "!" && offset:
+		addr.Modes = []string{ArmAddrPostIndex}
+	case op.Writeback == "!":
+		addr.Modes = []string{ArmAddrPreIndex}
+	case op.Writeback == "{!}":
+		addr.Modes = []string{ArmAddrOffset, ArmAddrPreIndex}
+	default:
+		addr.Modes = []string{ArmAddrOffset}
+	}
+
+	return addr, nil
+}
+
+// isLiteral reports whether the base register is constrained to the PC.
+func (addr *ArmAddressing) isLiteral() bool {
+	for _, c := range addr.Base.Constraints {
+		if c.Op == "==" && c.Value == "PC" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMode reports whether the memory operand is encoded with the addressing mode.
+func (addr *ArmAddressing) HasMode(mode string) bool {
+	for _, m := range addr.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Address returns the accessed address and the value written back to the base register of the addressing mode,
+// from the base register value and the offset, which is the immediate or the shifted register offset with the sign applied.
+// The written back value is the base register value if the mode has no writeback.
+//
+// The base of the literal addressing is the PC value read by the instruction, it's aligned down to 4 bytes.
+func (addr *ArmAddressing) Address(mode string, base, offset uint32) (address, writeback uint32, err error) {
+	if !addr.HasMode(mode) {
+		return 0, 0, fmt.Errorf("memory operand has no %s addressing mode", mode)
+	}
+	switch mode {
+	case ArmAddrOffset:
+		return base + offset, base, nil
+	case ArmAddrPreIndex:
+		return base + offset, base + offset, nil
+	case ArmAddrPostIndex:
+		return base, base + offset, nil
+	case ArmAddrLiteral:
+		return base&^3 + offset, base, nil
+	}
+	return 0, 0, fmt.Errorf("invalid addressing mode %q", mode)
+}
+
+// Encode places the "P", "U" and "W" bits of the addressing mode into the instruction word of enc.
+// The offset is subtracted if sub is true.
+//
+// The fixed bits of the encoding are not changed, so the word of the form encoded with a single mode is returned as is.
+// The post-indexed addressing clears "W" in A32 and sets it in T32.
+func (addr *ArmAddressing) Encode(enc *ArmEncoding, word uint32, mode string, sub bool) (uint32, error) {
+	if !addr.HasMode(mode) {
+		return 0, fmt.Errorf("memory operand has no %s addressing mode", mode)
+	}
+	if addr.AddBit != "" {
+		u := uint32(1)
+		if sub {
+			u = 0
+		}
+		word = enc.SetField(word, addr.AddBit, u)
+	}
+	if addr.IndexBit == "" || addr.WritebackBit == "" {
+		return word, nil
+	}
+
+	var p, w uint32
+	switch mode {
+	case ArmAddrOffset:
+		p, w = 1, 0
+	case ArmAddrPreIndex:
+		p, w = 1, 1
+	case ArmAddrPostIndex:
+		p, w = 0, 0
+		if addr.thumb {
+			w = 1
+		}
+	}
+	word = enc.SetField(word, addr.IndexBit, p)
+	word = enc.SetField(word, addr.WritebackBit, w)
+
+	return word, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "testing"
+
+// testArmAddressing returns the addressing mode of the memory operand of the form and its encoding.
+func testArmAddressing(t *testing.T, name, operands, arch string) (*ArmAddressing, *ArmEncoding) {
+	t.Helper()
+	inst := testArmForm(t, name, operands, arch)
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := inst.ParseEncoding()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range ops {
+		if op.Addr != nil {
+			return op.Addr, enc
+		}
+	}
+	t.Fatalf("%s %s %s has no memory operand", arch, name, operands)
+	return nil, nil
+}
+
+func TestArmAddressingAddress(t *testing.T) {
+	ldr, _ := testArmAddressing(t, "ldr", "Rd    , [Rn    , #+/-ImmZ]{!}", ArmA32)
+	literal, _ := testArmAddressing(t, "ldr", "Rd    , [Rn==PC, #+/-ImmZ]", ArmT32)
+	tests := []struct {
+		addr          *ArmAddressing
+		mode          string
+		base          uint32
+		wantAddr      uint32
+		wantWriteback uint32
+	}{
+		{ldr, ArmAddrOffset, 0x1000, 0x1008, 0x1000},
+		{ldr, ArmAddrPreIndex, 0x1000, 0x1008, 0x1008},
+		{ldr, ArmAddrPostIndex, 0x1000, 0x1000, 0x1008},
+		{literal, ArmAddrLiteral, 0x1002, 0x1008, 0x1002},
+	}
+	for _, tt := range tests {
+		addr, writeback, err := tt.addr.Address(tt.mode, tt.base, 8)
+		if err != nil || addr != tt.wantAddr || writeback != tt.wantWriteback {
+			t.Errorf("Address(%s, %#x, 8) = %#x, %#x, %v, want %#x, %#x", tt.mode, tt.base, addr, writeback, err, tt.wantAddr, tt.wantWriteback)
+		}
+	}
+
+	if _, _, err := literal.Address(ArmAddrOffset, 0x1000, 8); err == nil {
+		t.Errorf("Address(%s) of the literal addressing, want error", ArmAddrOffset)
+	}
+}
+
+func TestArmAddressingEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		mode     string
+		sub      bool
+		want     uint32
+	}{
+		// the "P", "U" and "W" bits 24, 23 and 21 of A32
+		{"ldr", "Rd    , [Rn    , #+/-ImmZ]{!}", ArmA32, ArmAddrOffset, false, 0x01800000},
+		{"ldr", "Rd    , [Rn    , #+/-ImmZ]{!}", ArmA32, ArmAddrPreIndex, false, 0x01a00000},
+		{"ldr", "Rd    , [Rn    , #+/-ImmZ]{!}", ArmA32, ArmAddrPostIndex, true, 0},
+		{"ldr", "Rd    , [Rn    , +/-Rm!=PC, {Sop #Shift}]{!}", ArmA32, ArmAddrPostIndex, false, 0x00800000},
+		{"ldrt", "Rd!=PC, [Rn!=PC, #+/-ImmZ]!", ArmA32, ArmAddrPostIndex, false, 0x00800000},
+		// the "P", "U" and "W" bits 10, 9 and 8 of T32
+		{"ldr", "Rd    , [Rn!=PC, #+/-ImmZ]{!}", ArmT32, ArmAddrOffset, false, 0x600},
+		{"ldr", "Rd    , [Rn!=PC, #+/-ImmZ]{!}", ArmT32, ArmAddrPreIndex, true, 0x500},
+		{"ldr", "Rd    , [Rn!=PC, #+/-ImmZ]{!}", ArmT32, ArmAddrPostIndex, false, 0x300},
+		{"ldr", "Rd    , [Rn==PC, #+/-ImmZ]", ArmT32, ArmAddrLiteral, false, 0x00800000},
+		{"ldr", "Rd    , [Rn==PC, #+/-ImmZ]", ArmT32, ArmAddrLiteral, true, 0},
+	}
+	for _, tt := range tests {
+		addr, enc := testArmAddressing(t, tt.name, tt.operands, tt.arch)
+		if got, err := addr.Encode(enc, 0, tt.mode, tt.sub); err != nil || got != tt.want {
+			t.Errorf("Encode(%s %s %s, %s, %t) = %#x, %v, want %#x", tt.arch, tt.name, tt.operands, tt.mode, tt.sub, got, err, tt.want)
+		}
+	}
+
+	addr, enc := testArmAddressing(t, "ldrt", "Rd!=PC, [Rn!=PC, #+/-ImmZ]!", ArmA32)
+	if got, err := addr.Encode(enc, 0, ArmAddrOffset, false); err == nil {
+		t.Errorf("Encode(A32 ldrt, %s) = %#x, want error", ArmAddrOffset, got)
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "json", "aliases.json", "write the ARM aliases and their preferred disassembly conditions JSON to `file`", &emitterFunc{name: "arm-aliases", fn: func(m *Model, w io.Writer) error {
+		return writeArmAliases(w, m.Arm, m.ArmInstructions)
+	}, out: []*ArmAlias(nil)})
+}
+
+// ArmAliasCond represents a condition on the opcode fields of the aliased instruction, like "Rn==31" and "immr==imms+1".
+type ArmAliasCond struct {
+	// Field is the opcode field name of the aliased instruction, like "Rn".
+	Field string `json:"field"`
+
+	// Op is the comparison, one of "==", "!=", "<", "<=", ">" and ">=".
+	Op string `json:"op"`
+
+	// Value is the compared value, the number or the other field optionally with the added number, like "31" and "imms+1".
+	Value string `json:"value"`
+}
+
+// String returns the condition as written in the alias rules, like "Rn==31".
+func (c ArmAliasCond) String() string {
+	return c.Field + c.Op + c.Value
+}
+
+// armAliasCondRe matches the alias condition, like "Rn==31" and "immr==imms+1".
+var armAliasCondRe = regexp.MustCompile(`^([A-Za-z]\w*'?)(==|!=|<=|>=|<|>)([\w']+(?:\+\d+)?)$`)
+
+// parseArmAliasConds parses the ',' separated conditions which all must hold, like "Rn==31, imm6==0".
+func parseArmAliasConds(s string) ([]ArmAliasCond, error) {
+	var conds []ArmAliasCond
+	for _, f := range strings.Split(s, ",") {
+		m := armAliasCondRe.FindStringSubmatch(strings.TrimSpace(f))
+		if m == nil {
+			return nil, fmt.Errorf("invalid alias condition %q", f)
+		}
+		conds = append(conds, ArmAliasCond{Field: m[1], Op: m[2], Value: m[3]})
+	}
+	return conds, nil
+}
+
+// ArmAlias represents the alias instruction and the conditions under which it's the preferred disassembly
+// of the aliased instruction, like "mov Wd, Wm" of "orr Wd, WZR, Wm".
+type ArmAlias struct {
+	// Name and Operands are the alias mnemonic and operands, like "lsl" and "Rd, Rn, #Shift".
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Of and OfOperands are the aliased instruction mnemonic and operands, like "mov" and "Rd, Rn, Sop #Shift".
+	Of         string `json:"of"`
+	OfOperands string `json:"ofOperands,omitzero"`
+
+	// Archs is the list of the instruction sets of the alias, like ArmA32 and ArmT32.
+	Archs []string `json:"archs"`
+
+	// When is the list of the alternative conditions on the aliased instruction fields, the alias is the preferred
+	// disassembly if all conditions of any alternative hold. The alias is always preferred if When is empty.
+	When [][]ArmAliasCond `json:"when,omitzero"`
+
+	// FlagSetting reports whether the alias applies to the flag-setting "S" forms too, like "lslS" of "movS".
+	FlagSetting bool `json:"flagSetting,omitzero"`
+
+	// AsmOnly reports whether the alias is only accepted by the assembler and never the preferred disassembly,
+	// like the PSEUDO_OF forms of armdata.js and the aliases encoded exactly as the aliased instruction.
+	AsmOnly bool `json:"asmOnly,omitzero"`
+
+	// Note is the additional condition which can't be expressed by the field conditions, like "BitCount(RsList)>1".
+	Note string `json:"note,omitzero"`
+}
+
+// isAliasOf reports whether the alias applies to the instruction form of arch with the mnemonic name and the operands.
+// Any form of the mnemonic matches if operands is empty.
+func (alias *ArmAlias) isAliasOf(arch, name, operands string) bool {
+	if !containsString(alias.Archs, arch) {
+		return false
+	}
+	if operands != "" && armOperandShape(operands) != armOperandShape(alias.OfOperands) {
+		return false
+	}
+	mnemonic, _ := splitArmName(name)
+	of, _ := splitArmName(alias.Of)
+	return mnemonic == of || alias.FlagSetting && mnemonic == of+"S"
+}
+
+// armAliasRule is the alias rule of the Arm ARM, the conditions are the ';' separated alternatives.
+type armAliasRule struct {
+	archs          []string
+	name, operands string
+	of, ofOperands string
+	when           string
+	flagSetting    bool
+	note           string
+}
+
+var (
+	armA32T32 = []string{ArmA32, ArmT32}
+	armA64    = []string{ArmA64}
+)
+
+// armAliasRules is a list of the alias rules of the Arm ARM in the order of the preference, the first preferred alias
+// of the instruction is its preferred disassembly.
+//
+// The A32 and T32 rules use the opcode field names of armdata.js and are checked against the instruction forms.
+// The A64 rules use the field names of the Arm ARM, armdata.js has no A64 instruction yet.
+// The "sf" field selects the 32-bit (0) or the 64-bit (1) variant.
+var armAliasRules = []armAliasRule{
+	// A32 and T32
+	{archs: armA32T32, name: "lsl", operands: "Rd, Rn, #Shift", of: "mov", ofOperands: "Rd, Rn, Sop #Shift", when: "Sop==0, Shift!=0", flagSetting: true},
+	{archs: armA32T32, name: "lsr", operands: "Rd, Rn, #Shift", of: "mov", ofOperands: "Rd, Rn, Sop #Shift", when: "Sop==1", flagSetting: true},
+	{archs: armA32T32, name: "asr", operands: "Rd, Rn, #Shift", of: "mov", ofOperands: "Rd, Rn, Sop #Shift", when: "Sop==2", flagSetting: true},
+	{archs: armA32T32, name: "ror", operands: "Rd, Rn, #Shift", of: "mov", ofOperands: "Rd, Rn, Sop #Shift", when: "Sop==3, Shift!=0", flagSetting: true},
+	{archs: armA32T32, name: "rrx", operands: "Rd, Rn", of: "mov", ofOperands: "Rd, Rn, Sop #Shift", when: "Sop==3, Shift==0", flagSetting: true},
+	{archs: []string{ArmA32}, name: "lsl", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rs", when: "Sop==0", flagSetting: true},
+	{archs: []string{ArmA32}, name: "lsr", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rs", when: "Sop==1", flagSetting: true},
+	{archs: []string{ArmA32}, name: "asr", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rs", when: "Sop==2", flagSetting: true},
+	{archs: []string{ArmA32}, name: "ror", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rs", when: "Sop==3", flagSetting: true},
+	{archs: []string{ArmT32}, name: "lsl", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rm", when: "Sop==0", flagSetting: true},
+	{archs: []string{ArmT32}, name: "lsr", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rm", when: "Sop==1", flagSetting: true},
+	{archs: []string{ArmT32}, name: "asr", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rm", when: "Sop==2", flagSetting: true},
+	{archs: []string{ArmT32}, name: "ror", operands: "Rd, Rn, Rm", of: "mov", ofOperands: "Rd, Rn, Sop Rm", when: "Sop==3", flagSetting: true},
+	{archs: armA32T32, name: "push", operands: "RsList", of: "stmdb", ofOperands: "[Rn]{!}, RsList", when: "Rn==13, W==1", note: "BitCount(RsList)>1"},
+	{archs: armA32T32, name: "pop", operands: "RdList", of: "ldm", ofOperands: "[Rn]{!}, RdList", when: "Rn==13, W==1", note: "BitCount(RdList)>1"},
+	{archs: armA32T32, name: "push", operands: "Rs", of: "str", ofOperands: "Rs, [Rn, #+/-ImmZ]{!}", when: "Rn==13, P==1, U==0, W==1, ImmZ==4"},
+	{archs: armA32T32, name: "pop", operands: "Rd", of: "ldr", ofOperands: "Rd, [Rn, #+/-ImmZ]{!}", when: "Rn==13, P==0, U==1, W==0, ImmZ==4"},
+
+	// A64
+	{archs: armA64, name: "mov", operands: "Rd, Rm", of: "orr", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rn==31, shift==0, imm6==0"},
+	{archs: armA64, name: "mov", operands: "Rd|SP, Rn|SP", of: "add", ofOperands: "Rd|SP, Rn|SP, #imm{, shift}", when: "sh==0, imm12==0, Rd==31; sh==0, imm12==0, Rn==31"},
+	{archs: armA64, name: "mov", operands: "Rd, #imm", of: "movz", ofOperands: "Rd, #imm{, LSL #shift}", when: "imm16!=0; hw==0"},
+	{archs: armA64, name: "mov", operands: "Rd, #imm", of: "movn", ofOperands: "Rd, #imm{, LSL #shift}", when: "sf==1, imm16!=0; sf==1, hw==0; sf==0, imm16!=0, imm16!=65535; sf==0, hw==0, imm16!=65535"},
+	{archs: armA64, name: "mov", operands: "Rd|SP, #imm", of: "orr", ofOperands: "Rd|SP, Rn, #imm", when: "Rn==31", note: "!MoveWidePreferred(sf, N, imms, immr)"},
+	{archs: armA64, name: "mvn", operands: "Rd, Rm{, shift #amount}", of: "orn", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rn==31"},
+	{archs: armA64, name: "cmp", operands: "Rn, Rm{, shift #amount}", of: "subs", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rd==31"},
+	{archs: armA64, name: "cmp", operands: "Rn|SP, #imm{, shift}", of: "subs", ofOperands: "Rd, Rn|SP, #imm{, shift}", when: "Rd==31"},
+	{archs: armA64, name: "cmp", operands: "Rn|SP, Rm{, extend {#amount}}", of: "subs", ofOperands: "Rd, Rn|SP, Rm{, extend {#amount}}", when: "Rd==31"},
+	{archs: armA64, name: "cmn", operands: "Rn, Rm{, shift #amount}", of: "adds", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rd==31"},
+	{archs: armA64, name: "cmn", operands: "Rn|SP, #imm{, shift}", of: "adds", ofOperands: "Rd, Rn|SP, #imm{, shift}", when: "Rd==31"},
+	{archs: armA64, name: "cmn", operands: "Rn|SP, Rm{, extend {#amount}}", of: "adds", ofOperands: "Rd, Rn|SP, Rm{, extend {#amount}}", when: "Rd==31"},
+	{archs: armA64, name: "tst", operands: "Rn, Rm{, shift #amount}", of: "ands", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rd==31"},
+	{archs: armA64, name: "tst", operands: "Rn, #imm", of: "ands", ofOperands: "Rd, Rn, #imm", when: "Rd==31"},
+	{archs: armA64, name: "neg", operands: "Rd, Rm{, shift #amount}", of: "sub", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rn==31"},
+	{archs: armA64, name: "negs", operands: "Rd, Rm{, shift #amount}", of: "subs", ofOperands: "Rd, Rn, Rm{, shift #amount}", when: "Rn==31"},
+	{archs: armA64, name: "ngc", operands: "Rd, Rm", of: "sbc", ofOperands: "Rd, Rn, Rm", when: "Rn==31"},
+	{archs: armA64, name: "ngcs", operands: "Rd, Rm", of: "sbcs", ofOperands: "Rd, Rn, Rm", when: "Rn==31"},
+	{archs: armA64, name: "lsl", operands: "Rd, Rn, #shift", of: "ubfm", ofOperands: "Rd, Rn, #immr, #imms", when: "sf==0, imms!=31, immr==imms+1; sf==1, imms!=63, immr==imms+1"},
+	{archs: armA64, name: "lsr", operands: "Rd, Rn, #shift", of: "ubfm", ofOperands: "Rd, Rn, #immr, #imms", when: "sf==0, imms==31; sf==1, imms==63"},
+	{archs: armA64, name: "uxtb", operands: "Wd, Wn", of: "ubfm", ofOperands: "Rd, Rn, #immr, #imms", when: "sf==0, immr==0, imms==7"},
+	{archs: armA64, name: "uxth", operands: "Wd, Wn", of: "ubfm", ofOperands: "Rd, Rn, #immr, #imms", when: "sf==0, immr==0, imms==15"},
+	{archs: armA64, name: "ubfiz", operands: "Rd, Rn, #lsb, #width", of: "ubfm", ofOperands: "Rd, Rn, #immr, #imms", when: "imms<immr"},
+	{archs: armA64, name: "ubfx", operands: "Rd, Rn, #lsb, #width", of: "ubfm", ofOperands: "Rd, Rn, #immr, #imms", when: "imms>=immr"},
+	{archs: armA64, name: "asr", operands: "Rd, Rn, #shift", of: "sbfm", ofOperands: "Rd, Rn, #immr, #imms", when: "sf==0, imms==31; sf==1, imms==63"},
+	{archs: armA64, name: "sxtb", operands: "Rd, Wn", of: "sbfm", ofOperands: "Rd, Rn, #immr, #imms", when: "immr==0, imms==7"},
+	{archs: armA64, name: "sxth", operands: "Rd, Wn", of: "sbfm", ofOperands: "Rd, Rn, #immr, #imms", when: "immr==0, imms==15"},
+	{archs: armA64, name: "sxtw", operands: "Xd, Wn", of: "sbfm", ofOperands: "Rd, Rn, #immr, #imms", when: "sf==1, immr==0, imms==31"},
+	{archs: armA64, name: "sbfiz", operands: "Rd, Rn, #lsb, #width", of: "sbfm", ofOperands: "Rd, Rn, #immr, #imms", when: "imms<immr"},
+	{archs: armA64, name: "sbfx", operands: "Rd, Rn, #lsb, #width", of: "sbfm", ofOperands: "Rd, Rn, #immr, #imms", when: "imms>=immr"},
+	{archs: armA64, name: "bfc", operands: "Rd, #lsb, #width", of: "bfm", ofOperands: "Rd, Rn, #immr, #imms", when: "Rn==31, imms<immr"},
+	{archs: armA64, name: "bfi", operands: "Rd, Rn, #lsb, #width", of: "bfm", ofOperands: "Rd, Rn, #immr, #imms", when: "imms<immr"},
+	{archs: armA64, name: "bfxil", operands: "Rd, Rn, #lsb, #width", of: "bfm", ofOperands: "Rd, Rn, #immr, #imms", when: "imms>=immr"},
+	{archs: armA64, name: "ror", operands: "Rd, Rs, #shift", of: "extr", ofOperands: "Rd, Rn, Rm, #lsb", when: "Rn==Rm"},
+	{archs: armA64, name: "cset", operands: "Rd, cond", of: "csinc", ofOperands: "Rd, Rn, Rm, cond", when: "Rm==31, Rn==31, cond<=13"},
+	{archs: armA64, name: "cinc", operands: "Rd, Rn, cond", of: "csinc", ofOperands: "Rd, Rn, Rm, cond", when: "Rm!=31, Rn==Rm, cond<=13"},
+	{archs: armA64, name: "csetm", operands: "Rd, cond", of: "csinv", ofOperands: "Rd, Rn, Rm, cond", when: "Rm==31, Rn==31, cond<=13"},
+	{archs: armA64, name: "cinv", operands: "Rd, Rn, cond", of: "csinv", ofOperands: "Rd, Rn, Rm, cond", when: "Rm!=31, Rn==Rm, cond<=13"},
+	{archs: armA64, name: "cneg", operands: "Rd, Rn, cond", of: "csneg", ofOperands: "Rd, Rn, Rm, cond", when: "Rn==Rm, cond<=13"},
+	{archs: armA64, name: "mul", operands: "Rd, Rn, Rm", of: "madd", ofOperands: "Rd, Rn, Rm, Ra", when: "Ra==31"},
+	{archs: armA64, name: "mneg", operands: "Rd, Rn, Rm", of: "msub", ofOperands: "Rd, Rn, Rm, Ra", when: "Ra==31"},
+	{archs: armA64, name: "smull", operands: "Xd, Wn, Wm", of: "smaddl", ofOperands: "Xd, Wn, Wm, Xa", when: "Ra==31"},
+	{archs: armA64, name: "smnegl", operands: "Xd, Wn, Wm", of: "smsubl", ofOperands: "Xd, Wn, Wm, Xa", when: "Ra==31"},
+	{archs: armA64, name: "umull", operands: "Xd, Wn, Wm", of: "umaddl", ofOperands: "Xd, Wn, Wm, Xa", when: "Ra==31"},
+	{archs: armA64, name: "umnegl", operands: "Xd, Wn, Wm", of: "umsubl", ofOperands: "Xd, Wn, Wm, Xa", when: "Ra==31"},
+	{archs: armA64, name: "nop", of: "hint", ofOperands: "#imm", when: "CRm==0, op2==0"},
+	{archs: armA64, name: "yield", of: "hint", ofOperands: "#imm", when: "CRm==0, op2==1"},
+	{archs: armA64, name: "wfe", of: "hint", ofOperands: "#imm", when: "CRm==0, op2==2"},
+	{archs: armA64, name: "wfi", of: "hint", ofOperands: "#imm", when: "CRm==0, op2==3"},
+	{archs: armA64, name: "sev", of: "hint", ofOperands: "#imm", when: "CRm==0, op2==4"},
+	{archs: armA64, name: "sevl", of: "hint", ofOperands: "#imm", when: "CRm==0, op2==5"},
+}
+
+// armOperandShapeRe matches the parts of the operands ignored by the operand shape, the constraints, the braces and the spaces.
+var armOperandShapeRe = regexp.MustCompile(`(?:!=|==|<=|>=)\w+|[{}\s]`)
+
+// armOperandShape returns the operands without the constraints and the optional braces,
+// like "Rd,Rn,Sop#Shift" for "Rd!=XX, Rn!=XX, {Sop #Shift}".
+func armOperandShape(s string) string {
+	return armOperandShapeRe.ReplaceAllString(s, "")
+}
+
+// hasArmForm reports whether insts has the form of the mnemonic and the operand shape in arch.
+func hasArmForm(insts []ArmInstruction, arch, mnemonic, operands string) bool {
+	shape := armOperandShape(operands)
+	for _, inst := range insts {
+		if m, _ := splitArmName(inst.Name); inst.Arch == arch && m == mnemonic && armOperandShape(inst.Operands) == shape {
+			return true
+		}
+	}
+	return false
+}
+
+// newArmAlias returns the alias of the rule, or nil if the A32 or T32 forms of the rule aren't in insts,
+// like in the reduced -arm-data, and -strict isn't set.
+func newArmAlias(rule *armAliasRule, insts []ArmInstruction) (*ArmAlias, error) {
+	alias := &ArmAlias{
+		Name:        rule.name,
+		Operands:    rule.operands,
+		Of:          rule.of,
+		OfOperands:  rule.ofOperands,
+		Archs:       rule.archs,
+		FlagSetting: rule.flagSetting,
+		Note:        rule.note,
+	}
+	if rule.when != "" {
+		for _, alt := range strings.Split(rule.when, ";") {
+			conds, err := parseArmAliasConds(alt)
+			if err != nil {
+				return nil, err
+			}
+			alias.When = append(alias.When, conds)
+		}
+	}
+
+	for _, arch := range rule.archs {
+		if arch == ArmA64 {
+			continue
+		}
+		if !hasArmForm(insts, arch, rule.name, rule.operands) {
+			return nil, skipArmAlias(fmt.Errorf("no %s form of alias %s %s", arch, rule.name, rule.operands))
+		}
+		if !hasArmForm(insts, arch, rule.of, rule.ofOperands) {
+			return nil, skipArmAlias(fmt.Errorf("no %s form of %s %s aliased by %s", arch, rule.of, rule.ofOperands, rule.name))
+		}
+	}
+
+	return alias, nil
+}
+
+// skipArmAlias returns err of the alias whose form isn't in the model under -strict,
+// or logs it and returns nil to skip the alias.
+func skipArmAlias(err error) error {
+	if *flagStrict {
+		return err
+	}
+	log.Printf("arm aliases: skipping: %v", err)
+	return nil
+}
+
+// armDataAliases returns the aliases of the ALIAS_OF and PSEUDO_OF instruction forms of insts.
+//
+// The aliased form is the form of the same instruction set whose encoding contains the alias encoding,
+// the conditions are the fixed bits and the repeated fields of the alias encoding, like "Vm==Vn" of
+// "vmov Dd, Dn" encoded as "vorr Dd, Dn, Dn". The alias without any condition is only accepted by the assembler.
+func armDataAliases(arm *Arm, insts []ArmInstruction) ([]*ArmAlias, error) {
+	var aliases []*ArmAlias
+	for i := range insts {
+		x := &insts[i]
+		attrs := arm.ParseMetadata(x.Metadata).Attributes
+		of, isAlias := attrs["ALIAS_OF"]
+		pseudo, isPseudo := attrs["PSEUDO_OF"]
+		if !isAlias && !isPseudo {
+			continue
+		}
+		if isPseudo {
+			of = pseudo
+		}
+
+		xenc, err := x.ParseEncoding()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", x.Name, x.Operands, err)
+		}
+		var y *ArmInstruction
+		var yenc *ArmEncoding
+		for j := range insts {
+			if m, _ := splitArmName(insts[j].Name); m != of || insts[j].Arch != x.Arch {
+				continue
+			}
+			enc, err := insts[j].ParseEncoding()
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", insts[j].Name, insts[j].Operands, err)
+			}
+			if enc.Match(xenc.Value) && xenc.Mask&enc.Mask == enc.Mask {
+				y, yenc = &insts[j], enc
+				break
+			}
+		}
+		if y == nil {
+			if err := skipArmAlias(fmt.Errorf("no %s form of %s aliased by %s %s", x.Arch, of, x.Name, x.Operands)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		alias := &ArmAlias{
+			Name:       x.Name,
+			Operands:   x.Operands,
+			Of:         y.Name,
+			OfOperands: y.Operands,
+			Archs:      []string{x.Arch},
+			AsmOnly:    isPseudo,
+		}
+		if conds := armAliasConds(xenc, yenc); len(conds) > 0 && !isPseudo {
+			alias.When = [][]ArmAliasCond{conds}
+		} else {
+			alias.AsmOnly = true
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// armAliasConds returns the conditions on the fields of the aliased encoding y under which it encodes the alias encoding x.
+func armAliasConds(x, y *ArmEncoding) []ArmAliasCond {
+	// xfield returns the field of x at the bit, or nil if the bit is fixed
+	xfield := func(bit int) *ArmBitField {
+		for i := range x.Fields {
+			if f := &x.Fields[i]; f.Lo <= bit && bit <= f.Hi {
+				return f
+			}
+		}
+		return nil
+	}
+
+	var conds []ArmAliasCond
+	seen := make(map[string]bool)
+	add := func(c ArmAliasCond) {
+		if !seen[c.String()] {
+			seen[c.String()] = true
+			conds = append(conds, c)
+		}
+	}
+	for _, yf := range y.Fields {
+		xf := xfield(yf.Lo)
+		switch {
+		case xf == nil:
+			mask := uint32(1)<<(yf.Hi-yf.Lo+1) - 1
+			if x.Mask>>yf.Lo&mask == mask {
+				add(ArmAliasCond{Field: yf.Name, Op: "==", Value: strconv.FormatUint(uint64(y.Field(x.Value, yf.Name)), 10)})
+			}
+		case xf.Name != yf.Name:
+			// the field of x placed at the other field of y, like "Vn" of "vmov" placed at "Vm" of "vorr"
+			for _, other := range y.Fields {
+				if other.Name == yf.Name {
+					continue
+				}
+				if of := xfield(other.Lo); of != nil && of.Name == xf.Name && !seen[other.Name+"=="+yf.Name] {
+					add(ArmAliasCond{Field: yf.Name, Op: "==", Value: other.Name})
+					break
+				}
+			}
+		}
+	}
+	return conds
+}
+
+// armAliases returns the aliases of the alias rules and the ALIAS_OF and PSEUDO_OF forms of insts.
+func armAliases(arm *Arm, insts []ArmInstruction) ([]*ArmAlias, error) {
+	aliases := make([]*ArmAlias, 0, len(armAliasRules))
+	for i := range armAliasRules {
+		alias, err := newArmAlias(&armAliasRules[i], insts)
+		if err != nil {
+			return nil, err
+		}
+		if alias != nil {
+			aliases = append(aliases, alias)
+		}
+	}
+	data, err := armDataAliases(arm, insts)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(aliases, data...), nil
+}
+
+// writeArmAliases writes the ARM aliases as JSON to w.
+func writeArmAliases(w io.Writer, arm *Arm, insts []ArmInstruction) error {
+	aliases, err := armAliases(arm, insts)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, "arm aliases", aliases)
+}
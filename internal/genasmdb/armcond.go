@@ -0,0 +1,200 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "json", "conds.json", "write the ARM condition codes and the conditional instruction forms JSON to `file`", &emitterFunc{name: "arm-conds", fn: func(m *Model, w io.Writer) error {
+		return writeArmConditions(w, m.ArmInstructions)
+	}, out: ArmConditionCodes{}})
+}
+
+// ArmCondition is the 4-bit ARM condition code.
+type ArmCondition uint8
+
+// ARM condition codes in the encoding order.
+const (
+	ArmCondEQ ArmCondition = iota // equal
+	ArmCondNE                     // not equal
+	ArmCondCS                     // carry set, unsigned higher or same ("hs")
+	ArmCondCC                     // carry clear, unsigned lower ("lo")
+	ArmCondMI                     // minus, negative
+	ArmCondPL                     // plus, positive or zero
+	ArmCondVS                     // overflow
+	ArmCondVC                     // no overflow
+	ArmCondHI                     // unsigned higher
+	ArmCondLS                     // unsigned lower or same
+	ArmCondGE                     // signed greater than or equal
+	ArmCondLT                     // signed less than
+	ArmCondGT                     // signed greater than
+	ArmCondLE                     // signed less than or equal
+	ArmCondAL                     // always
+	ArmCondNV                     // always, A64 only, unconditional instruction space in A32
+)
+
+// ArmConditions is a list of the condition code names in the encoding order.
+var ArmConditions = []string{
+	"eq", "ne", "cs", "cc", "mi", "pl", "vs", "vc",
+	"hi", "ls", "ge", "lt", "gt", "le", "al", "nv",
+}
+
+// String returns the name of the condition code, like "eq".
+func (c ArmCondition) String() string {
+	if int(c) < len(ArmConditions) {
+		return ArmConditions[c]
+	}
+	return fmt.Sprintf("ArmCondition(%d)", uint8(c))
+}
+
+// Inverse returns the inverse condition code, like "ne" for "eq".
+//
+// The inverse of "al" is "nv", which is not a real inverse, "nv" is also always true in A64.
+func (c ArmCondition) Inverse() ArmCondition {
+	return c ^ 1
+}
+
+// Flags returns the APSR flags the condition code depends on, like "Z" for "eq" and "N", "Z" and "V" for "gt".
+func (c ArmCondition) Flags() []string {
+	switch c &^ 1 {
+	case ArmCondEQ:
+		return []string{"Z"}
+	case ArmCondCS:
+		return []string{"C"}
+	case ArmCondMI:
+		return []string{"N"}
+	case ArmCondVS:
+		return []string{"V"}
+	case ArmCondHI:
+		return []string{"C", "Z"}
+	case ArmCondGE:
+		return []string{"N", "V"}
+	case ArmCondGT:
+		return []string{"N", "Z", "V"}
+	}
+	return nil
+}
+
+// Eval reports whether the condition code holds for the APSR flags.
+func (c ArmCondition) Eval(n, z, cf, v bool) bool {
+	var r bool
+	switch c &^ 1 {
+	case ArmCondEQ:
+		r = z
+	case ArmCondCS:
+		r = cf
+	case ArmCondMI:
+		r = n
+	case ArmCondVS:
+		r = v
+	case ArmCondHI:
+		r = cf && !z
+	case ArmCondGE:
+		r = n == v
+	case ArmCondGT:
+		r = !z && n == v
+	case ArmCondAL:
+		return true
+	}
+	if c&1 != 0 {
+		return !r
+	}
+	return r
+}
+
+// How the instruction accepts the condition code.
+const (
+	// ArmCondField is the instruction with the condition code field in the opcode, like the A32 instructions and the T16 "b".
+	ArmCondField = "field"
+	// ArmCondIT is the Thumb instruction made conditional by the preceding IT instruction.
+	ArmCondIT = "it"
+)
+
+// IsConditional reports whether the instruction has the condition code field in the opcode.
+func (inst *ArmInstruction) IsConditional() bool {
+	for _, f := range strings.Split(inst.OpCode, "|") {
+		if strings.TrimSpace(f) == "Cond" {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsCondition returns how the instruction accepts the condition code, ArmCondField or ArmCondIT,
+// or empty if the instruction is unconditional.
+//
+// The Thumb instruction is conditional inside the IT block unless it's executed unconditionally, like "bkpt".
+// The A64 conditional instructions, like "b.cond" and "csel", take the condition code as the "Cond" operand,
+// armdata.js has no A64 instruction yet.
+func (inst *ArmInstruction) AcceptsCondition() (string, error) {
+	if inst.IsConditional() {
+		return ArmCondField, nil
+	}
+	if inst.Arch != ArmT16 && inst.Arch != ArmT32 {
+		return "", nil
+	}
+	it, err := parseArmIT(inst.Metadata)
+	if err != nil {
+		return "", err
+	}
+	if (it.In || it.Last) && !it.Uncond {
+		return ArmCondIT, nil
+	}
+	return "", nil
+}
+
+// ArmConditionInfo represents the condition code and its properties.
+type ArmConditionInfo struct {
+	Name    string   `json:"name"`
+	Code    int      `json:"code"`
+	Inverse string   `json:"inverse"`
+	Flags   []string `json:"flags,omitzero"`
+}
+
+// ArmConditionalForm represents the instruction form accepting the condition code.
+type ArmConditionalForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+	Arch     string `json:"arch"`
+
+	// Cond is how the form accepts the condition code, ArmCondField or ArmCondIT.
+	Cond string `json:"cond"`
+}
+
+// ArmConditionCodes represents the condition codes and the instruction forms accepting them.
+type ArmConditionCodes struct {
+	Conditions []*ArmConditionInfo   `json:"conditions"`
+	Forms      []*ArmConditionalForm `json:"forms"`
+}
+
+// writeArmConditions writes the condition codes and the instruction forms accepting them as JSON to w.
+func writeArmConditions(w io.Writer, insts []ArmInstruction) error {
+	v := &ArmConditionCodes{}
+	for i := range ArmConditions {
+		c := ArmCondition(i)
+		v.Conditions = append(v.Conditions, &ArmConditionInfo{
+			Name:    c.String(),
+			Code:    i,
+			Inverse: c.Inverse().String(),
+			Flags:   c.Flags(),
+		})
+	}
+	for i := range insts {
+		inst := &insts[i]
+		cond, err := inst.AcceptsCondition()
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+		}
+		if cond == "" {
+			continue
+		}
+		v.Forms = append(v.Forms, &ArmConditionalForm{Name: inst.Name, Operands: inst.Operands, Arch: inst.Arch, Cond: cond})
+	}
+
+	return writeJSON(w, "arm conditions", v)
+}
@@ -0,0 +1,36 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "testing"
+
+func TestArmConditionEval(t *testing.T) {
+	// the flags are N, Z, C and V
+	tests := []struct {
+		cond          ArmCondition
+		n, z, cf, v   bool
+		want, inverse bool
+	}{
+		{ArmCondEQ, false, true, false, false, true, false},
+		{ArmCondCS, false, false, true, false, true, false},
+		{ArmCondMI, false, false, false, false, false, true},
+		{ArmCondVS, false, false, false, true, true, false},
+		{ArmCondHI, false, false, true, false, true, false},
+		{ArmCondHI, false, true, true, false, false, true},
+		{ArmCondGE, true, false, false, true, true, false},
+		{ArmCondGE, true, false, false, false, false, true},
+		{ArmCondGT, false, true, false, false, false, true},
+		{ArmCondGT, true, false, false, true, true, false},
+		{ArmCondAL, false, false, false, false, true, true},
+	}
+	for _, tt := range tests {
+		if got := tt.cond.Eval(tt.n, tt.z, tt.cf, tt.v); got != tt.want {
+			t.Errorf("%s.Eval(%t, %t, %t, %t) = %t, want %t", tt.cond, tt.n, tt.z, tt.cf, tt.v, got, tt.want)
+		}
+		inv := tt.cond.Inverse()
+		if got := inv.Eval(tt.n, tt.z, tt.cf, tt.v); got != tt.inverse {
+			t.Errorf("%s.Eval(%t, %t, %t, %t) = %t, want %t", inv, tt.n, tt.z, tt.cf, tt.v, got, tt.inverse)
+		}
+	}
+}
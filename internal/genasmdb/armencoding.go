@@ -0,0 +1,284 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ArmBitField represents a part of the opcode field placed in the instruction word.
+//
+// The field can be split into several parts, like "ImmZ:1|...|ImmZ:3|...|ImmZ:8",
+// each part places the bits Shift..Shift+Hi-Lo of the field value at the bits Hi..Lo of the instruction word.
+type ArmBitField struct {
+	// Name is the field name as written in the opcode, like "Rd", "ImmZ", "Width-1" and "Vd'".
+	Name string `json:"name"`
+
+	// Hi and Lo are the bit positions in the instruction word.
+	Hi int `json:"hi"`
+	Lo int `json:"lo"`
+
+	// Shift is the position of the lowest bit of the part in the field value.
+	Shift int `json:"shift"`
+}
+
+// ArmEncoding represents a parsed ARM opcode bit-pattern.
+type ArmEncoding struct {
+	// Data is the opcode string as written in armdata.js.
+	Data string `json:"data"`
+
+	// Width is the width of the instruction word in bits, 16 for T16 and 32 for the others.
+	// The T32 instruction word has the first halfword in the high 16 bits.
+	Width int `json:"width"`
+
+	// Mask and Value are the fixed bits of the opcode, the word w matches the encoding if w&Mask == Value.
+	Mask  uint32 `json:"mask"`
+	Value uint32 `json:"value"`
+
+	Fields []ArmBitField `json:"fields,omitzero"`
+}
+
+// armBitsRe matches the opcode part of the single bits, like "0101", "PU1W" and "XYZ1".
+// Each letter is the 1-bit field named by the letter.
+var armBitsRe = regexp.MustCompile(`^[01A-Z]+$`)
+
+// armFieldRe matches the opcode field, like "Rd", "ImmZ:8", "RelS[16:11]", "RelS[19]" and "Width-1:5".
+var armFieldRe = regexp.MustCompile(`^('?[A-Za-z][\w-]*'?)(?::(\d+)|\[(\d+)(?::(\d+))?\])?$`)
+
+// armFieldWidth returns the width of the opcode field written without the width, like "Rn" and "Vd'".
+//
+// The registers, the condition code and the ASIMD "CMode" are 4 bits, the ASIMD element size "Sz" is 2 bits,
+// the extra register bit, like "Vd'" and "'Vn", and the other fields are 1 bit.
+func armFieldWidth(name string) int {
+	switch {
+	case strings.Contains(name, "'"):
+		return 1
+	case name == "Cond", name == "CMode", name[0] == 'R', name[0] == 'V':
+		return 4
+	case name == "Sz":
+		return 2
+	}
+	return 1
+}
+
+// armArchWidth returns the width of the instruction word of the instruction set arch.
+func armArchWidth(arch string) int {
+	if arch == ArmT16 {
+		return 16
+	}
+	return 32
+}
+
+// ParseEncoding parses the instruction opcode bit-pattern.
+func (inst *ArmInstruction) ParseEncoding() (*ArmEncoding, error) {
+	return parseArmEncoding(inst.OpCode, armArchWidth(inst.Arch))
+}
+
+// parseArmEncoding parses the '|' separated ARM opcode bit-pattern of the width bits.
+func parseArmEncoding(s string, width int) (*ArmEncoding, error) {
+	enc := &ArmEncoding{
+		Data:  s,
+		Width: width,
+	}
+
+	// the parts are placed from the most significant bit
+	type part struct {
+		name           string
+		width          int
+		fieldHi, field int // explicit bits of the field value, field is -1 if not specified
+	}
+	var parts []part
+	for _, tok := range strings.Split(s, "|") {
+		tok = strings.TrimSpace(tok)
+		switch {
+		case tok == "":
+			return nil, fmt.Errorf("empty field in %q", s)
+
+		case armBitsRe.MatchString(tok):
+			for _, c := range tok {
+				parts = append(parts, part{name: string(c), width: 1, field: -1})
+			}
+
+		default:
+			m := armFieldRe.FindStringSubmatch(tok)
+			if m == nil {
+				return nil, fmt.Errorf("invalid field %q in %q", tok, s)
+			}
+			p := part{name: m[1], field: -1}
+			switch {
+			case m[2] != "":
+				p.width, _ = strconv.Atoi(m[2])
+			case m[3] != "":
+				hi, _ := strconv.Atoi(m[3])
+				lo := hi
+				if m[4] != "" {
+					lo, _ = strconv.Atoi(m[4])
+				}
+				if hi < lo {
+					return nil, fmt.Errorf("invalid bit-range %q in %q", tok, s)
+				}
+				p.width, p.fieldHi, p.field = hi-lo+1, hi, lo
+			default:
+				p.width = armFieldWidth(p.name)
+			}
+			parts = append(parts, p)
+		}
+	}
+
+	pos := width
+	for _, p := range parts {
+		pos -= p.width
+		if pos < 0 {
+			break
+		}
+		switch p.name {
+		case "0":
+			enc.Mask |= 1 << pos
+		case "1":
+			enc.Mask |= 1 << pos
+			enc.Value |= 1 << pos
+		default:
+			enc.Fields = append(enc.Fields, ArmBitField{Name: p.name, Hi: pos + p.width - 1, Lo: pos, Shift: p.field})
+		}
+	}
+	if pos != 0 {
+		return nil, fmt.Errorf("opcode %q is %d bits, want %d bits", s, width-pos, width)
+	}
+
+	// the parts without the explicit bits are concatenated in order, the last part is the lowest bits of the field value
+	shifts := make(map[string]int)
+	for i := len(enc.Fields) - 1; i >= 0; i-- {
+		f := &enc.Fields[i]
+		if f.Shift >= 0 {
+			continue
+		}
+		f.Shift = shifts[f.Name]
+		shifts[f.Name] += f.Hi - f.Lo + 1
+	}
+
+	return enc, nil
+}
+
+// String returns the '|' separated opcode bit-pattern of enc, like "Cond|0010|100S|Rn|Rd|ImmA:12".
+//
+// The runs of the fixed bits are joined, and the field part is written with the explicit bits only if its value bits
+// aren't the ones of the parts concatenated in order.
+func (enc *ArmEncoding) String() string {
+	// the explicit bits of each field, in the reverse order of the parts like the parser
+	explicit := make([]bool, len(enc.Fields))
+	shifts := make(map[string]int)
+	for i := len(enc.Fields) - 1; i >= 0; i-- {
+		f := enc.Fields[i]
+		if f.Shift != shifts[f.Name] {
+			explicit[i] = true
+			continue
+		}
+		shifts[f.Name] += f.Hi - f.Lo + 1
+	}
+
+	var toks []string
+	bits := ""
+	i := 0
+	for pos := enc.Width - 1; pos >= 0; pos-- {
+		if enc.Mask&(1<<pos) != 0 {
+			bits += strconv.Itoa(int(enc.Value >> pos & 1))
+			continue
+		}
+		if bits != "" {
+			toks, bits = append(toks, bits), ""
+		}
+		if i >= len(enc.Fields) || enc.Fields[i].Hi != pos {
+			// the bit is neither fixed nor of a field
+			toks = append(toks, "?")
+			continue
+		}
+		f := enc.Fields[i]
+		width := f.Hi - f.Lo + 1
+		switch {
+		case explicit[i] && width == 1:
+			toks = append(toks, fmt.Sprintf("%s[%d]", f.Name, f.Shift))
+		case explicit[i]:
+			toks = append(toks, fmt.Sprintf("%s[%d:%d]", f.Name, f.Shift+width-1, f.Shift))
+		case width == armFieldWidth(f.Name) && (len(f.Name) == 1 || !armBitsRe.MatchString(f.Name)):
+			toks = append(toks, f.Name)
+		default:
+			toks = append(toks, fmt.Sprintf("%s:%d", f.Name, width))
+		}
+		pos = f.Lo
+		i++
+	}
+	if bits != "" {
+		toks = append(toks, bits)
+	}
+	return strings.Join(toks, "|")
+}
+
+// Match reports whether the instruction word matches the fixed bits of the encoding.
+func (enc *ArmEncoding) Match(word uint32) bool {
+	return word&enc.Mask == enc.Value
+}
+
+// Field extracts the value of the named field from the instruction word.
+func (enc *ArmEncoding) Field(word uint32, name string) uint32 {
+	var v uint32
+	for _, f := range enc.Fields {
+		if f.Name == name {
+			v |= (word >> f.Lo & (1<<(f.Hi-f.Lo+1) - 1)) << f.Shift
+		}
+	}
+	return v
+}
+
+// SetField places the value of the named field into the instruction word.
+func (enc *ArmEncoding) SetField(word uint32, name string, v uint32) uint32 {
+	for _, f := range enc.Fields {
+		if f.Name == name {
+			mask := uint32(1<<(f.Hi-f.Lo+1)-1) << f.Lo
+			word = word&^mask | (v>>f.Shift<<f.Lo)&mask
+		}
+	}
+	return word
+}
+
+// validateOpCode returns the problems of the opcode grammar of inst and of the agreement of the opcode with the
+// operands, the opcode parses to the width of the instruction set and each immediate operand names a field of the opcode.
+func (inst *ArmInstruction) validateOpCode() []string {
+	if _, err := inst.ParseEncoding(); err != nil {
+		return []string{err.Error()}
+	}
+	// the operands which don't parse are the problems of the operand grammar
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil
+	}
+
+	var probs []string
+	var check func(op *ArmOperand)
+	check = func(op *ArmOperand) {
+		for _, elem := range op.Mem {
+			check(elem)
+		}
+		if op.Amount != nil {
+			check(op.Amount)
+		}
+		if op.Type != ArmOperandImm && op.Type != ArmOperandRel && op.Type != ArmOperandCond {
+			return
+		}
+		// the literal value, like "#0", and the special register, like "#APSR", aren't encoded
+		if _, err := strconv.Atoi(op.Field); err == nil || strings.ToUpper(op.Field) == op.Field {
+			return
+		}
+		if bits, _ := armFieldBits(inst.OpCode, op.Field); bits == 0 {
+			probs = append(probs, fmt.Sprintf("immediate %s has no opcode field", op.Data))
+		}
+	}
+	for _, op := range ops {
+		check(op)
+	}
+
+	return probs
+}
@@ -0,0 +1,172 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"io"
+	"sort"
+)
+
+func init() {
+	registerEmitter("arm", "json", "features.json", "write the ARM FEAT_* features and the instruction forms requiring them JSON to `file`", &emitterFunc{name: "arm-features", fn: func(m *Model, w io.Writer) error {
+		return writeArmFeatures(w, m.Arm, m.ArmInstructions)
+	}, out: []*ArmFeature(nil)})
+}
+
+// armFeatures maps the extension name of the instruction metadata to the official FEAT_* names of the Arm ARM.
+//
+// The VFP versions are all part of FEAT_FP since ARMv8. The A64 extensions are listed by the names
+// the asmjit A64 data uses, armdata.js has no A64 instruction yet. The extensions without the FEAT_* name,
+// like "IDIVA" and "MP", are not listed.
+var armFeatures = map[string][]string{
+	"ASIMD":      {"FEAT_AdvSIMD"},
+	"VFPv2":      {"FEAT_FP"},
+	"VFPv3":      {"FEAT_FP"},
+	"VFPv3_FP16": {"FEAT_FP"},
+	"VFPv4":      {"FEAT_FP"},
+	"AES":        {"FEAT_AES"},
+	"SHA1":       {"FEAT_SHA1"},
+	"SHA256":     {"FEAT_SHA256"},
+	"CRC32":      {"FEAT_CRC32"},
+	"SECURITY":   {"FEAT_EL3"},
+
+	// A64
+	"BF16":    {"FEAT_BF16"},
+	"BTI":     {"FEAT_BTI"},
+	"DOTPROD": {"FEAT_DotProd"},
+	"FCMA":    {"FEAT_FCMA"},
+	"FHM":     {"FEAT_FHM"},
+	"FP16":    {"FEAT_FP16"},
+	"FRINTTS": {"FEAT_FRINTTS"},
+	"I8MM":    {"FEAT_I8MM"},
+	"JSCVT":   {"FEAT_JSCVT"},
+	"LRCPC":   {"FEAT_LRCPC"},
+	"LRCPC2":  {"FEAT_LRCPC2"},
+	"LSE":     {"FEAT_LSE"},
+	"MTE":     {"FEAT_MTE"},
+	"PAUTH":   {"FEAT_PAuth"},
+	"RDM":     {"FEAT_RDM"},
+	"SHA3":    {"FEAT_SHA3"},
+	"SHA512":  {"FEAT_SHA512"},
+	"SM4":     {"FEAT_SM4", "FEAT_SM3"},
+	"SVE":     {"FEAT_SVE"},
+	"SVE2":    {"FEAT_SVE2"},
+}
+
+// Features returns the FEAT_* names required by the instruction of meta.
+func (meta *ArmMetadata) Features() []string {
+	var feats []string
+	seen := make(map[string]bool)
+	for _, ext := range meta.Extensions {
+		for _, feat := range armFeatures[ext] {
+			if !seen[feat] {
+				seen[feat] = true
+				feats = append(feats, feat)
+			}
+		}
+	}
+	return feats
+}
+
+// HasFeature reports whether the instruction of meta requires the FEAT_* feature.
+func (meta *ArmMetadata) HasFeature(feat string) bool {
+	for _, f := range meta.Features() {
+		if f == feat {
+			return true
+		}
+	}
+	return false
+}
+
+// HasExtension reports whether the instruction of meta requires the extension.
+func (meta *ArmMetadata) HasExtension(ext string) bool {
+	for _, e := range meta.Extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// HasExtension reports whether the instruction of meta requires the extension.
+func (meta *X86Metadata) HasExtension(ext string) bool {
+	for _, e := range meta.Extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// InstructionsWithFeature returns the instructions requiring the FEAT_* feature, like "FEAT_AES".
+func (a *Arm) InstructionsWithFeature(insts []ArmInstruction, feat string) []ArmInstruction {
+	var forms []ArmInstruction
+	for _, inst := range insts {
+		if a.ParseMetadata(inst.Metadata).HasFeature(feat) {
+			forms = append(forms, inst)
+		}
+	}
+	return forms
+}
+
+// InstructionsWithExtension returns the instructions requiring the extension, like "CRC32".
+func (a *Arm) InstructionsWithExtension(insts []ArmInstruction, ext string) []ArmInstruction {
+	var forms []ArmInstruction
+	for _, inst := range insts {
+		if a.ParseMetadata(inst.Metadata).HasExtension(ext) {
+			forms = append(forms, inst)
+		}
+	}
+	return forms
+}
+
+// InstructionsWithExtension returns the instructions requiring the extension, like "AVX512_F".
+func (x *X86) InstructionsWithExtension(insts []X86Instruction, ext string) []X86Instruction {
+	var forms []X86Instruction
+	for _, inst := range insts {
+		if x.ParseMetadata(inst.Metadata).HasExtension(ext) {
+			forms = append(forms, inst)
+		}
+	}
+	return forms
+}
+
+// ArmFeature represents the FEAT_* feature and the instruction forms requiring it.
+type ArmFeature struct {
+	Name string `json:"name"`
+
+	// Extensions is the list of the metadata extensions mapped to the feature.
+	Extensions []string `json:"extensions"`
+
+	Instructions []ArmInstruction `json:"instructions"`
+}
+
+// writeArmFeatures writes the FEAT_* features of insts and the instruction forms requiring them as JSON to w.
+//
+// The features without any instruction form are omitted.
+func writeArmFeatures(w io.Writer, arm *Arm, insts []ArmInstruction) error {
+	exts := make(map[string][]string)
+	for ext, feats := range armFeatures {
+		for _, feat := range feats {
+			exts[feat] = append(exts[feat], ext)
+		}
+	}
+	names := make([]string, 0, len(exts))
+	for feat := range exts {
+		names = append(names, feat)
+	}
+	sort.Strings(names)
+
+	var features []*ArmFeature
+	for _, feat := range names {
+		forms := arm.InstructionsWithFeature(insts, feat)
+		if len(forms) == 0 {
+			continue
+		}
+		sort.Strings(exts[feat])
+		features = append(features, &ArmFeature{Name: feat, Extensions: exts[feat], Instructions: forms})
+	}
+
+	return writeJSON(w, "arm features", features)
+}
@@ -0,0 +1,151 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"math/bits"
+)
+
+// EncodeArmImmA encodes v as the A32 modified immediate constant, the "ImmA" opcode field,
+// the 8-bit value rotated right by twice the 4-bit rotation, "rotation:imm8".
+//
+// The smallest rotation is used, as the Arm ARM requires for the canonical encoding.
+// It reports false if v isn't encodable.
+func EncodeArmImmA(v uint32) (imm12 uint32, ok bool) {
+	for rot := uint32(0); rot < 16; rot++ {
+		if imm8 := bits.RotateLeft32(v, int(rot*2)); imm8 <= 0xff {
+			return rot<<8 | imm8, true
+		}
+	}
+	return 0, false
+}
+
+// DecodeArmImmA decodes the A32 modified immediate constant of the "ImmA" opcode field.
+func DecodeArmImmA(imm12 uint32) uint32 {
+	return bits.RotateLeft32(imm12&0xff, -int(imm12>>8&0xf)*2)
+}
+
+// EncodeArmImmC encodes v as the T32 modified immediate constant, the "ImmC" opcode field "i:imm3:imm8".
+//
+// The constant is either the byte "abcdefgh" replicated by the pattern of "i:imm3" 0-3, like "00XY00XY",
+// or the byte "1bcdefgh" rotated right by the 5-bit rotation 8-31 of "i:imm3:a".
+// It reports false if v isn't encodable.
+func EncodeArmImmC(v uint32) (imm12 uint32, ok bool) {
+	b, b2 := v&0xff, v>>8&0xff
+	switch {
+	case v == b:
+		return b, true
+	case v == b<<16|b:
+		return 1<<8 | b, true
+	case v == b2<<24|b2<<8:
+		return 2<<8 | b2, true
+	case v == b*0x01010101:
+		return 3<<8 | b, true
+	}
+	for rot := uint32(8); rot < 32; rot++ {
+		if imm8 := bits.RotateLeft32(v, int(rot)); imm8 >= 0x80 && imm8 <= 0xff {
+			return rot<<7 | imm8&0x7f, true
+		}
+	}
+	return 0, false
+}
+
+// DecodeArmImmC decodes the T32 modified immediate constant of the "ImmC" opcode field.
+func DecodeArmImmC(imm12 uint32) uint32 {
+	b := imm12 & 0xff
+	switch imm12 >> 8 & 0xf {
+	case 0:
+		return b
+	case 1:
+		return b<<16 | b
+	case 2:
+		return b<<24 | b<<8
+	case 3:
+		return b * 0x01010101
+	}
+	return bits.RotateLeft32(0x80|imm12&0x7f, -int(imm12>>7&0x1f))
+}
+
+// armOnes returns the value of the n low bits set.
+func armOnes(n int) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<n - 1
+}
+
+// armRotateRight rotates right the low size bits of v by r.
+func armRotateRight(v uint64, r, size int) uint64 {
+	r %= size
+	if r == 0 {
+		return v
+	}
+	return (v>>r | v<<(size-r)) & armOnes(size)
+}
+
+// EncodeArmBitmask encodes v as the A64 logical immediate of the register of size 32 or 64 bits,
+// the "N:immr:imms" fields of the bitmask.
+//
+// The bitmask is the element of 2, 4, 8, 16, 32 or 64 bits replicated to the register size, the element is
+// the run of 1-bits rotated right by "immr", "imms" encodes the element size and the number of 1-bits minus 1.
+// The smallest element is used. It reports false if v isn't encodable, the values of all 0-bits and all 1-bits never are.
+func EncodeArmBitmask(v uint64, size int) (n, immr, imms uint32, ok bool) {
+	if size != 32 && size != 64 {
+		return 0, 0, 0, false
+	}
+	if size == 32 && v>>32 != 0 {
+		return 0, 0, 0, false
+	}
+
+	for esize := 2; esize <= size; esize *= 2 {
+		elem := v & armOnes(esize)
+		replicated := uint64(0)
+		for i := 0; i < size; i += esize {
+			replicated |= elem << i
+		}
+		if replicated != v {
+			continue
+		}
+
+		ones := bits.OnesCount64(elem)
+		if ones == 0 || ones == esize {
+			return 0, 0, 0, false
+		}
+		for r := 0; r < esize; r++ {
+			if armRotateRight(armOnes(ones), r, esize) != elem {
+				continue
+			}
+			imms = uint32(^(2*esize-1)&0x3f) | uint32(ones-1)
+			if esize == 64 {
+				n, imms = 1, uint32(ones-1)
+			}
+			return n, uint32(r), imms, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// DecodeArmBitmask decodes the A64 logical immediate of the "N:immr:imms" fields for the register of size 32 or 64 bits.
+// It reports false if the fields are a reserved encoding.
+func DecodeArmBitmask(n, immr, imms uint32, size int) (uint64, bool) {
+	if size != 32 && size != 64 || size == 32 && n != 0 {
+		return 0, false
+	}
+	length := bits.Len32(n<<6|^imms&0x3f) - 1
+	if length < 1 {
+		return 0, false
+	}
+	esize := 1 << length
+	levels := uint32(esize - 1)
+	if imms&levels == levels {
+		return 0, false
+	}
+
+	elem := armRotateRight(armOnes(int(imms&levels)+1), int(immr&levels), esize)
+	var v uint64
+	for i := 0; i < size; i += esize {
+		v |= elem << i
+	}
+	return v, true
+}
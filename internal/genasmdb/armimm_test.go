@@ -0,0 +1,130 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "testing"
+
+func TestEncodeArmImmA(t *testing.T) {
+	tests := []struct {
+		v    uint32
+		want uint32
+		ok   bool
+	}{
+		{0, 0x000, true},
+		{0xff, 0x0ff, true},
+		// the rotated values, the smallest rotation is used
+		{0xff000000, 0x4ff, true},
+		{0xf000000f, 0x2ff, true},
+		{0x104, 0xf41, true},
+		{0x3fc, 0xfff, true},
+		// the odd rotations and the values wider than 8 bits
+		{0x1fe, 0, false},
+		{0x101, 0, false},
+		{0xffffffff, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := EncodeArmImmA(tt.v)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("EncodeArmImmA(%#x) = %#x, %t, want %#x, %t", tt.v, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	for imm12 := uint32(0); imm12 < 1<<12; imm12++ {
+		v := DecodeArmImmA(imm12)
+		enc, ok := EncodeArmImmA(v)
+		if !ok || DecodeArmImmA(enc) != v {
+			t.Errorf("EncodeArmImmA(DecodeArmImmA(%#x) = %#x) = %#x, %t", imm12, v, enc, ok)
+		}
+	}
+}
+
+func TestEncodeArmImmC(t *testing.T) {
+	tests := []struct {
+		v    uint32
+		want uint32
+		ok   bool
+	}{
+		{0xab, 0x0ab, true},
+		// the replicated bytes
+		{0x00ab00ab, 0x1ab, true},
+		{0xab00ab00, 0x2ab, true},
+		{0xabababab, 0x3ab, true},
+		// the rotated "1bcdefgh" bytes
+		{0x80000000, 0x400, true},
+		{0xff000000, 0x47f, true},
+		{0x000003fc, 0xf7f, true},
+		{0x00ab00ac, 0, false},
+		{0x101, 0, false},
+		{0x1fe00001, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := EncodeArmImmC(tt.v)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("EncodeArmImmC(%#x) = %#x, %t, want %#x, %t", tt.v, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	for imm12 := uint32(0); imm12 < 1<<12; imm12++ {
+		v := DecodeArmImmC(imm12)
+		enc, ok := EncodeArmImmC(v)
+		if !ok || DecodeArmImmC(enc) != v {
+			t.Errorf("EncodeArmImmC(DecodeArmImmC(%#x) = %#x) = %#x, %t", imm12, v, enc, ok)
+		}
+	}
+}
+
+func TestEncodeArmBitmask(t *testing.T) {
+	tests := []struct {
+		v          uint64
+		size       int
+		n          uint32
+		immr, imms uint32
+		ok         bool
+	}{
+		{0x5555555555555555, 64, 0, 0, 0x3c, true},
+		{0xaaaaaaaaaaaaaaaa, 64, 0, 1, 0x3c, true},
+		{0x0f0f0f0f, 32, 0, 0, 0x33, true},
+		{0xf0f0f0f0, 32, 0, 4, 0x33, true},
+		{0x00ff00ff00ff00ff, 64, 0, 0, 0x27, true},
+		{0xff, 64, 1, 0, 7, true},
+		{0x8000000000000000, 64, 1, 1, 0, true},
+		{0x7fffffff, 32, 0, 0, 0x1e, true},
+		{0xfffffffe, 32, 0, 31, 0x1e, true},
+		// all 0-bits and all 1-bits, the runs which aren't contiguous and the wrong sizes
+		{0, 64, 0, 0, 0, false},
+		{0xffffffffffffffff, 64, 0, 0, 0, false},
+		{0xffffffff, 32, 0, 0, 0, false},
+		{0x1234, 64, 0, 0, 0, false},
+		{0x100000000, 32, 0, 0, 0, false},
+		{0xff, 16, 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		n, immr, imms, ok := EncodeArmBitmask(tt.v, tt.size)
+		if n != tt.n || immr != tt.immr || imms != tt.imms || ok != tt.ok {
+			t.Errorf("EncodeArmBitmask(%#x, %d) = %d, %#x, %#x, %t, want %d, %#x, %#x, %t", tt.v, tt.size, n, immr, imms, ok, tt.n, tt.immr, tt.imms, tt.ok)
+		}
+	}
+
+	count := 0
+	for _, size := range []int{32, 64} {
+		for n := uint32(0); n < 2; n++ {
+			for immr := uint32(0); immr < 64; immr++ {
+				for imms := uint32(0); imms < 64; imms++ {
+					v, ok := DecodeArmBitmask(n, immr, imms, size)
+					if !ok {
+						continue
+					}
+					count++
+					n2, immr2, imms2, ok := EncodeArmBitmask(v, size)
+					if got, _ := DecodeArmBitmask(n2, immr2, imms2, size); !ok || got != v {
+						t.Errorf("EncodeArmBitmask(DecodeArmBitmask(%d, %#x, %#x, %d) = %#x) = %d, %#x, %#x, %t", n, immr, imms, size, v, n2, immr2, imms2, ok)
+					}
+				}
+			}
+		}
+	}
+	if count == 0 {
+		t.Error("DecodeArmBitmask decodes no fields")
+	}
+}
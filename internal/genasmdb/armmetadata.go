@@ -0,0 +1,115 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+// ArmMetadata represents a parsed ARM instruction metadata.
+type ArmMetadata struct {
+	// CPULevels is the list of required or deprecating architecture versions, like "ARMv6T2+" and "ARMv8-".
+	CPULevels []string `json:"cpuLevels,omitzero"`
+
+	// Extensions is the list of required CPU extensions, like "ASIMD" and "CRC32".
+	Extensions []string `json:"extensions,omitzero"`
+
+	// Attributes maps the attribute name to its value. Flag attributes have an empty value.
+	Attributes stringMap `json:"attributes,omitzero"`
+
+	// SpecialRegs maps the special register name to its access, like "APSR.N": "W".
+	SpecialRegs stringMap `json:"specialRegs,omitzero"`
+}
+
+// ParseMetadata parses the instruction metadata with the ARM definitions.
+func (a *Arm) ParseMetadata(s string) *ArmMetadata {
+	meta := &ArmMetadata{}
+	for _, field := range strings.Fields(s) {
+		name, value := field, ""
+		if i := strings.IndexByte(field, '='); i >= 0 {
+			name, value = field[:i], field[i+1:]
+		}
+		a.addMetadata(meta, name, value)
+	}
+
+	return meta
+}
+
+// String returns the metadata string of meta with the shortcuts expanded, the CPU levels, the extensions,
+// and the sorted attributes and special registers, like "ARMv6T2+ ASIMD APSR.N=W".
+func (meta *ArmMetadata) String() string {
+	var fields []string
+	fields = append(fields, meta.CPULevels...)
+	fields = append(fields, meta.Extensions...)
+	fields = append(fields, meta.Attributes.metadataFields()...)
+	fields = append(fields, meta.SpecialRegs.metadataFields()...)
+	return strings.Join(fields, " ")
+}
+
+// addMetadata adds the name and value to meta with expanding shortcuts.
+func (a *Arm) addMetadata(meta *ArmMetadata, name, value string) {
+	if expand := a.shortcut(name); expand != "" {
+		for _, n := range expandShortcut(expand) {
+			a.addMetadata(meta, n, value)
+		}
+		return
+	}
+
+	switch {
+	case a.hasCPULevel(strings.TrimRight(name, "+-")):
+		meta.CPULevels = append(meta.CPULevels, name)
+	case a.hasExtension(name):
+		meta.Extensions = append(meta.Extensions, name)
+	case a.hasSpecialReg(name):
+		if meta.SpecialRegs == nil {
+			meta.SpecialRegs = make(map[string]string)
+		}
+		meta.SpecialRegs[name] = value
+	default:
+		if meta.Attributes == nil {
+			meta.Attributes = make(map[string]string)
+		}
+		meta.Attributes[name] = value
+	}
+}
+
+// shortcut returns the expansion of the name shortcut, or empty if name is not a shortcut.
+func (a *Arm) shortcut(name string) string {
+	for _, sc := range a.Shortcuts {
+		if sc.Name == name {
+			return sc.Expand
+		}
+	}
+	return ""
+}
+
+// hasCPULevel reports whether the name is a known architecture version.
+func (a *Arm) hasCPULevel(name string) bool {
+	for _, level := range a.CPULevels {
+		if level.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExtension reports whether the name is a known extension.
+func (a *Arm) hasExtension(name string) bool {
+	for _, ext := range a.Extensions {
+		if ext.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSpecialReg reports whether the name is a known special register.
+func (a *Arm) hasSpecialReg(name string) bool {
+	for _, reg := range a.SpecialRegs {
+		if reg.Name == name {
+			return true
+		}
+	}
+	return false
+}
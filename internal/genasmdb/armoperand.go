@@ -0,0 +1,482 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ArmOperandType represents a type of ARM operand.
+type ArmOperandType uint8
+
+const (
+	// ArmOperandReg is a register operand, like "Rd" and "Dm".
+	ArmOperandReg ArmOperandType = iota + 1
+	// ArmOperandRegList is a register list operand, like "RdList".
+	ArmOperandRegList
+	// ArmOperandMem is a memory operand, like "[Rn, #+/-ImmZ]".
+	ArmOperandMem
+	// ArmOperandImm is an immediate operand, like "#ImmZ".
+	ArmOperandImm
+	// ArmOperandRel is a PC relative offset operand, like "#RelS*2".
+	ArmOperandRel
+	// ArmOperandShift is a shifted or extended register operand, like "LSL #Shift" and "Sop Rs".
+	ArmOperandShift
+	// ArmOperandCond is a condition code operand, like "#FirstCond".
+	ArmOperandCond
+)
+
+// String returns the name of the operand type.
+func (t ArmOperandType) String() string {
+	switch t {
+	case ArmOperandReg:
+		return "reg"
+	case ArmOperandRegList:
+		return "reglist"
+	case ArmOperandMem:
+		return "mem"
+	case ArmOperandImm:
+		return "imm"
+	case ArmOperandRel:
+		return "rel"
+	case ArmOperandShift:
+		return "shift"
+	case ArmOperandCond:
+		return "cond"
+	}
+	return fmt.Sprintf("ArmOperandType(%d)", uint8(t))
+}
+
+// ArmConstraint represents a restriction of the operand, like "!=PC", "<=15" and "==Dn+1".
+type ArmConstraint struct {
+	// Op is the comparison, one of "!=", "==", "<=" and ">=".
+	Op string `json:"op"`
+
+	// Value is the compared value, like "PC", "HI", "15" or the other field "Dn+1".
+	Value string `json:"value"`
+}
+
+// ArmRange represents the inclusive range of the immediate operand value.
+type ArmRange struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// ArmOperand represents a parsed ARM instruction operand.
+type ArmOperand struct {
+	// Data is the operand string as written in armdata.js.
+	Data string `json:"data"`
+
+	// Index is the operand position in the instruction operands.
+	Index int `json:"index"`
+
+	Type ArmOperandType `json:"type"`
+
+	// Field is the opcode field name of the operand, like "Rd" and "ImmZ", or the literal value, like "0".
+	// It's the shift operation, like "LSL" or "Sop" for any of the shifts, for the shift operand.
+	Field string `json:"field,omitzero"`
+
+	// Class is the register class of the register and register list operand, like "r", "s", "d", "v",
+	// "w" and "x" for the A64 registers, and "z" and "p" for the SVE scalable vector and predicate registers.
+	Class string `json:"class,omitzero"`
+
+	Constraints []ArmConstraint `json:"constraints,omitzero"`
+
+	// Scale is the multiplier of the encoded immediate, like 4 for "#ImmZ*4", or 1.
+	Scale int `json:"scale"`
+
+	// Sign reports whether the immediate or register offset can be added or subtracted ("+/-").
+	Sign bool `json:"sign,omitzero"`
+
+	// Negative reports whether the immediate offset is subtracted ("-").
+	Negative bool `json:"negative,omitzero"`
+
+	// Extend reports whether the shift operand is a register extend, like "UXTW" and "SXTX".
+	Extend bool `json:"extend,omitzero"`
+
+	// Amount is the shift amount of the shift operand, either an immediate or a register.
+	// It's nil if the shift doesn't have the amount, like "RRX".
+	Amount *ArmOperand `json:"amount,omitzero"`
+
+	// Mem is the list of the elements of the memory operand, the base register followed by the offset and the shift.
+	Mem []*ArmOperand `json:"mem,omitzero"`
+
+	// Writeback is the writeback of the memory operand, "!" or "{!}" if it's optional.
+	Writeback string `json:"writeback,omitzero"`
+
+	// Addr is the addressing mode of the memory operand.
+	Addr *ArmAddressing `json:"addr,omitzero"`
+
+	// Optional reports whether the operand is optional ("{op}").
+	Optional bool `json:"optional,omitzero"`
+
+	// Element is the element size suffix of the SVE vector and predicate register, like "T" and "B" of "Zd.B".
+	Element string `json:"element,omitzero"`
+
+	// Predication is the predication of the SVE governing predicate, ArmPredZeroing ("Pg/Z") or ArmPredMerging ("Pg/M").
+	Predication string `json:"predication,omitzero"`
+
+	// Range is the range of the immediate and relative offset value, including the scale.
+	// It's nil if the immediate is encoded, like the modified immediate "ImmA" and the floating-point "ImmVFP".
+	Range *ArmRange `json:"range,omitzero"`
+}
+
+// armShifts is a list of shift operations.
+//
+// "Sop" is the shift operation encoded in the "Sop" opcode field.
+var armShifts = map[string]bool{
+	"LSL": true,
+	"LSR": true,
+	"ASR": true,
+	"ROR": true,
+	"RRX": true,
+	"Sop": true,
+}
+
+// armExtends is a list of register extend operations.
+var armExtends = map[string]bool{
+	"UXTB": true,
+	"UXTH": true,
+	"UXTW": true,
+	"UXTX": true,
+	"SXTB": true,
+	"SXTH": true,
+	"SXTW": true,
+	"SXTX": true,
+}
+
+// armOperandRe matches the operand field, like "ImmZ*4", "Rn!=PC", "Dn2==Dn+1", "0",
+// and the SVE registers, like "Zdn.T" and "Pg/M".
+var armOperandRe = regexp.MustCompile(`^([A-Za-z]\w*|\d+)(\*\d+)?((?:(?:!=|==|<=|>=)[\w+]+)*)(?:\.(\w+))?(?:/([ZM]))?$`)
+
+// armConstraintRe matches the single constraint of the operand field, like "!=PC".
+var armConstraintRe = regexp.MustCompile(`(!=|==|<=|>=)([\w+]+)`)
+
+// ParseOperands parses the instruction operands, computes the range of the immediates from the opcode fields
+// and the addressing mode of the memory operands.
+func (inst *ArmInstruction) ParseOperands() ([]*ArmOperand, error) {
+	ops, err := parseArmOperands(inst.Operands)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := inst.ParseEncoding()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		op.setRange(inst.OpCode)
+		if op.Type == ArmOperandMem {
+			if op.Addr, err = newArmAddressing(op, enc, inst.Arch); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ops, nil
+}
+
+// parseArmOperands parses the comma separated ARM operands string.
+//
+// The commas inside the memory operand brackets and the optional operand braces don't separate the operands.
+func parseArmOperands(s string) ([]*ArmOperand, error) {
+	fields, err := splitArmOperands(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse operands %q: %w", s, err)
+	}
+
+	ops := make([]*ArmOperand, len(fields))
+	for i, field := range fields {
+		op, err := parseArmOperand(field)
+		if err != nil {
+			return nil, fmt.Errorf("parse operand %d of %q: %w", i, s, err)
+		}
+		op.Index = i
+		ops[i] = op
+	}
+
+	return ops, nil
+}
+
+// splitArmOperands splits s by the commas outside of the brackets and braces.
+func splitArmOperands(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unmatched %q", s[i])
+			}
+		case ',':
+			if depth == 0 {
+				fields = append(fields, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unmatched bracket")
+	}
+
+	return append(fields, strings.TrimSpace(s[start:])), nil
+}
+
+// parseArmOperand parses the single ARM operand string.
+func parseArmOperand(s string) (*ArmOperand, error) {
+	data := strings.TrimSpace(s)
+	if !strings.HasPrefix(data, "[") {
+		return parseArmOperandElem(data)
+	}
+
+	op := &ArmOperand{
+		Data:  data,
+		Type:  ArmOperandMem,
+		Scale: 1,
+	}
+	end := strings.LastIndexByte(data, ']')
+	if end < 0 {
+		return nil, fmt.Errorf("unmatched '[' in %q", data)
+	}
+	switch wb := data[end+1:]; wb {
+	case "", "!", "{!}":
+		op.Writeback = wb
+	default:
+		return nil, fmt.Errorf("invalid writeback %q in %q", wb, data)
+	}
+
+	elems, err := splitArmOperands(data[1:end])
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range elems {
+		elem, err := parseArmOperandElem(e)
+		if err != nil {
+			return nil, fmt.Errorf("parse memory element %d: %w", i, err)
+		}
+		elem.Index = i
+		op.Mem = append(op.Mem, elem)
+	}
+	if len(op.Mem) == 0 || op.Mem[0].Type != ArmOperandReg {
+		return nil, fmt.Errorf("no base register in %q", data)
+	}
+
+	return op, nil
+}
+
+// parseArmOperandElem parses the ARM operand other than the memory operand, or the element of the memory operand.
+func parseArmOperandElem(s string) (*ArmOperand, error) {
+	op := &ArmOperand{
+		Data:  s,
+		Scale: 1,
+	}
+	main := s
+	if strings.HasPrefix(main, "{") {
+		if !strings.HasSuffix(main, "}") {
+			return nil, fmt.Errorf("unmatched '{' in %q", s)
+		}
+		op.Optional = true
+		main = strings.TrimSpace(main[1 : len(main)-1])
+	}
+	if main == "" {
+		return nil, fmt.Errorf("empty operand")
+	}
+
+	// the shift and the extend, like "LSL #Shift", "Sop Rs" and "UXTW {#Amount}"
+	if fields := strings.SplitN(main, " ", 2); armShifts[fields[0]] || armExtends[fields[0]] {
+		op.Type = ArmOperandShift
+		op.Field = fields[0]
+		op.Extend = armExtends[fields[0]]
+		if len(fields) == 2 {
+			amount, err := parseArmOperandElem(strings.TrimSpace(fields[1]))
+			if err != nil {
+				return nil, fmt.Errorf("parse shift amount of %q: %w", s, err)
+			}
+			op.Amount = amount
+		}
+		return op, nil
+	}
+
+	imm := strings.HasPrefix(main, "#")
+	main = strings.TrimPrefix(main, "#")
+	switch {
+	case strings.HasPrefix(main, "+/-"):
+		op.Sign = true
+		main = main[3:]
+	case strings.HasPrefix(main, "-"):
+		op.Negative = true
+		main = main[1:]
+	}
+
+	m := armOperandRe.FindStringSubmatch(main)
+	if m == nil {
+		return nil, fmt.Errorf("invalid operand %q", s)
+	}
+	op.Field = m[1]
+	if m[2] != "" {
+		scale, err := strconv.Atoi(m[2][1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid scale in %q: %w", s, err)
+		}
+		op.Scale = scale
+	}
+	for _, c := range armConstraintRe.FindAllStringSubmatch(m[3], -1) {
+		op.Constraints = append(op.Constraints, ArmConstraint{Op: c[1], Value: c[2]})
+	}
+	op.Element = m[4]
+	op.Predication = m[5]
+
+	switch {
+	case imm && strings.HasPrefix(op.Field, "Rel"):
+		op.Type = ArmOperandRel
+	case imm && strings.HasSuffix(op.Field, "Cond"):
+		op.Type = ArmOperandCond
+		op.Range = &ArmRange{Min: 0, Max: int64(ArmCondAL)}
+	case imm:
+		op.Type = ArmOperandImm
+		if n, err := strconv.ParseInt(op.Field, 10, 64); err == nil {
+			op.Range = &ArmRange{Min: n, Max: n}
+		}
+	case strings.HasSuffix(op.Field, "List"):
+		op.Type = ArmOperandRegList
+		op.Class = strings.ToLower(op.Field[:1])
+	case strings.IndexByte("RDSVCZPWX", op.Field[0]) >= 0:
+		op.Type = ArmOperandReg
+		op.Class = strings.ToLower(op.Field[:1])
+	default:
+		return nil, fmt.Errorf("unknown operand %q", s)
+	}
+
+	return op, nil
+}
+
+// armEncodedImms is a list of the immediate fields whose value is encoded and has no simple range.
+var armEncodedImms = map[string]bool{
+	"ImmA":   true, // modified immediate constant of A32
+	"ImmC":   true, // modified immediate constant of T32
+	"ImmN":   true, // ASIMD immediate encoded with the element size
+	"ImmV":   true, // ASIMD modified immediate constant
+	"ImmVFP": true, // VFP floating-point constant
+	"RelA":   true, // ADR modified immediate offset
+}
+
+// setRange sets the range of the immediate and relative offset operand from the width of its field in the opcode,
+// and narrows it by the constraints. The range of the memory elements and the shift amount is also set.
+func (op *ArmOperand) setRange(opcode string) {
+	for _, elem := range op.Mem {
+		elem.setRange(opcode)
+	}
+	if op.Amount != nil {
+		op.Amount.setRange(opcode)
+	}
+
+	if op.Range != nil || armEncodedImms[op.Field] {
+		return
+	}
+	if op.Type != ArmOperandImm && op.Type != ArmOperandRel {
+		return
+	}
+	bits, bias := armFieldBits(opcode, op.Field)
+	if bits == 0 || bits > 32 {
+		return
+	}
+
+	var r ArmRange
+	switch {
+	case op.Field == "RelS":
+		r.Min, r.Max = -(int64(1) << (bits - 1)), int64(1)<<(bits-1)-1
+	default:
+		r.Min, r.Max = 0, int64(1)<<bits-1
+	}
+	r.Min *= int64(op.Scale)
+	r.Max *= int64(op.Scale)
+	if op.Sign {
+		r.Min = -r.Max
+	}
+	if op.Negative {
+		r.Min, r.Max = -r.Max, -r.Min
+	}
+	r.Min += int64(bias)
+	r.Max += int64(bias)
+
+	for _, c := range op.Constraints {
+		n, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case c.Op == "<=" && n < r.Max:
+			r.Max = n
+		case c.Op == ">=" && n > r.Min:
+			r.Min = n
+		}
+	}
+	op.Range = &r
+}
+
+// armFieldBits returns the total width in bits of the field in the opcode,
+// like 12 for "ImmZ" of "1111|0|ImmZ:1|1|0000|0|Rn|0|ImmZ:3|Rd|ImmZ:8".
+//
+// The field with the bit-range, like "RelS[16:11]", is assumed to be as wide as its highest bit.
+// The field encoded with the bias, like "Width-1:5", returns the bias to be added to the encoded value.
+// It returns 0 bits if the field isn't in the opcode.
+func armFieldBits(opcode, field string) (bits, bias int) {
+	hi := 0
+	for _, f := range strings.Split(opcode, "|") {
+		f = strings.TrimSpace(f)
+		name, rest := f, ""
+		if i := strings.IndexAny(f, ":["); i >= 0 {
+			name, rest = f[:i], f[i:]
+		}
+		if strings.HasPrefix(name, field+"-") {
+			n, err := strconv.Atoi(strings.TrimPrefix(name, field+"-"))
+			if err != nil {
+				continue
+			}
+			name, bias = field, n
+		}
+		if name != field {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(rest, ":"):
+			n, err := strconv.Atoi(rest[1:])
+			if err == nil {
+				bits += n
+			}
+		case strings.HasPrefix(rest, "["):
+			r := strings.TrimSuffix(rest[1:], "]")
+			if i := strings.IndexByte(r, ':'); i >= 0 {
+				r = r[:i]
+			}
+			n, err := strconv.Atoi(r)
+			if err == nil && n+1 > hi {
+				hi = n + 1
+			}
+		}
+	}
+	if hi > bits {
+		bits = hi
+	}
+	return bits, bias
+}
+
+// validateOperands returns the problems of the operand grammar of inst, the brackets and braces are matched
+// and each operand is of a known type.
+func (inst *ArmInstruction) validateOperands() []string {
+	if _, err := parseArmOperands(inst.Operands); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
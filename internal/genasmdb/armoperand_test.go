@@ -0,0 +1,37 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "testing"
+
+func FuzzParseArmOperands(f *testing.F) {
+	for _, s := range []string{
+		"Rd!=PC, Rn, #ImmZ*4",
+		"Rt, [Rn, #+/-Imm8]{!}",
+		"Rt, [Rn], Rm, LSL #Shift",
+		"Dd, Dn, Dm",
+		"Zdn.T, Pg/M, Zdn.T, Zm.T",
+		"Rd, Rn, {#Imm}",
+		"Dn2==Dn+1",
+		"{RegList}",
+		"",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ops, err := parseArmOperands(s)
+		if err != nil {
+			return
+		}
+		fields, err := splitArmOperands(s)
+		if err != nil || len(ops) != len(fields) {
+			t.Fatalf("parseArmOperands(%q) = %d operands, want the %d fields %q, %v", s, len(ops), len(fields), fields, err)
+		}
+		for i, op := range ops {
+			if op == nil || op.Index != i {
+				t.Errorf("parseArmOperands(%q) operand %d = %+v, want index %d", s, i, op, i)
+			}
+		}
+	})
+}
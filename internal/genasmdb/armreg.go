@@ -0,0 +1,232 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "json", "regs.json", "write the ARM register classes JSON to `file`", &emitterFunc{name: "arm-regs", fn: func(m *Model, w io.Writer) error {
+		return writeArmRegClasses(w)
+	}, out: armRegClassMap(nil)})
+}
+
+// ArmRegClass represents an ARM register class, like "w" and "d".
+type ArmRegClass struct {
+	Name string `json:"name"`
+
+	// Kind is the register kind, "gp", "vec" or "pred".
+	Kind string `json:"kind"`
+
+	// Bits is the width of the register in bits. It's the maximum width for the scalable SVE registers.
+	Bits int `json:"bits"`
+
+	// Count is the number of the registers of the class.
+	Count int `json:"count"`
+
+	// Root is the class of the widest registers the registers of the class are part of, like "x" for "w",
+	// "q" for the A32 "s" and "d", and "z" for the A64 "v". It's the class itself for the widest registers.
+	Root string `json:"root"`
+
+	// Packed reports whether the registers are packed into the root register, like the two A32 "d" registers of
+	// a "q" register, instead of each register being the low bits of the root register of the same index.
+	Packed bool `json:"packed,omitzero"`
+
+	// ZeroExtends reports whether writing the register zeroes the rest of the root register, like writing "w" zeroes
+	// the upper half of "x". Writing the register which doesn't zero-extend depends on the previous root register value.
+	ZeroExtends bool `json:"zeroExtends,omitzero"`
+
+	// Scalable reports whether the width of the register is the implementation defined SVE vector length.
+	Scalable bool `json:"scalable,omitzero"`
+}
+
+// armRegClasses is a list of the register classes of each instruction set.
+//
+// The T16 and T32 instructions share the A32 registers. The A32 "d16-d31" registers require VFPv3-D32 or ASIMD.
+var armRegClasses = armRegClassMap{
+	ArmA32: {
+		{Name: "r", Kind: "gp", Bits: 32, Count: 16, Root: "r"},
+		{Name: "s", Kind: "vec", Bits: 32, Count: 32, Root: "q", Packed: true},
+		{Name: "d", Kind: "vec", Bits: 64, Count: 32, Root: "q", Packed: true},
+		{Name: "q", Kind: "vec", Bits: 128, Count: 16, Root: "q"},
+	},
+	ArmA64: {
+		{Name: "w", Kind: "gp", Bits: 32, Count: 32, Root: "x", ZeroExtends: true},
+		{Name: "x", Kind: "gp", Bits: 64, Count: 32, Root: "x"},
+		{Name: "wsp", Kind: "gp", Bits: 32, Count: 1, Root: "sp", ZeroExtends: true},
+		{Name: "sp", Kind: "gp", Bits: 64, Count: 1, Root: "sp"},
+		{Name: "b", Kind: "vec", Bits: 8, Count: 32, Root: "z", ZeroExtends: true},
+		{Name: "h", Kind: "vec", Bits: 16, Count: 32, Root: "z", ZeroExtends: true},
+		{Name: "s", Kind: "vec", Bits: 32, Count: 32, Root: "z", ZeroExtends: true},
+		{Name: "d", Kind: "vec", Bits: 64, Count: 32, Root: "z", ZeroExtends: true},
+		{Name: "q", Kind: "vec", Bits: 128, Count: 32, Root: "z", ZeroExtends: true},
+		{Name: "v", Kind: "vec", Bits: 128, Count: 32, Root: "z", ZeroExtends: true},
+		{Name: "z", Kind: "vec", Bits: 2048, Count: 32, Root: "z", Scalable: true},
+		{Name: "p", Kind: "pred", Bits: 256, Count: 16, Root: "p", Scalable: true},
+	},
+}
+
+// armRegArch returns the instruction set of the registers of arch, ArmA32 for the Thumb instruction sets.
+func armRegArch(arch string) string {
+	if arch == ArmT16 || arch == ArmT32 {
+		return ArmA32
+	}
+	return arch
+}
+
+// LookupArmRegClass returns the register class of arch named name, like "w", or nil if there is no such class.
+func LookupArmRegClass(arch, name string) *ArmRegClass {
+	for _, class := range armRegClasses[armRegArch(arch)] {
+		if class.Name == name {
+			return class
+		}
+	}
+	return nil
+}
+
+// ArmReg represents an ARM register, like "w5" and "s0".
+type ArmReg struct {
+	Class string `json:"class"`
+	Index int    `json:"index"`
+}
+
+// String returns the name of the register, like "w5". The single registers of the class are named by the class, like "sp".
+func (r ArmReg) String() string {
+	if r.Class == "wsp" || r.Class == "sp" {
+		return r.Class
+	}
+	return r.Class + strconv.Itoa(r.Index)
+}
+
+// armRegAliases maps the special register names to the registers of each instruction set.
+var armRegAliases = map[string]map[string]ArmReg{
+	ArmA32: {
+		"sb": {Class: "r", Index: 9},
+		"sl": {Class: "r", Index: 10},
+		"fp": {Class: "r", Index: 11},
+		"ip": {Class: "r", Index: 12},
+		"sp": {Class: "r", Index: 13},
+		"lr": {Class: "r", Index: 14},
+		"pc": {Class: "r", Index: 15},
+	},
+	ArmA64: {
+		"wzr": {Class: "w", Index: 31},
+		"xzr": {Class: "x", Index: 31},
+		"fp":  {Class: "x", Index: 29},
+		"lr":  {Class: "x", Index: 30},
+		"wsp": {Class: "wsp"},
+		"sp":  {Class: "sp"},
+	},
+}
+
+// ParseArmReg parses the register name of arch, like "w5", "s0", "lr" and "xzr", case-insensitive.
+//
+// The A64 "w31" and "x31" are the zero registers "wzr" and "xzr", the stack pointer is the separate "sp" and "wsp".
+func ParseArmReg(arch, name string) (ArmReg, error) {
+	regArch := armRegArch(arch)
+	s := strings.ToLower(name)
+	if r, ok := armRegAliases[regArch][s]; ok {
+		return r, nil
+	}
+
+	i := strings.IndexAny(s, "0123456789")
+	if i <= 0 {
+		return ArmReg{}, fmt.Errorf("invalid %s register %q", arch, name)
+	}
+	class := LookupArmRegClass(regArch, s[:i])
+	index, err := strconv.Atoi(s[i:])
+	if class == nil || err != nil || index >= class.Count || class.Count == 1 {
+		return ArmReg{}, fmt.Errorf("invalid %s register %q", arch, name)
+	}
+
+	return ArmReg{Class: class.Name, Index: index}, nil
+}
+
+// ArmRegSpan represents the bits of the root register occupied by the register, like the bits 0..31 of "x5" for "w5".
+type ArmRegSpan struct {
+	Root ArmReg `json:"root"`
+
+	// Lo and Hi are the lowest and the highest occupied bit of the root register.
+	Lo int `json:"lo"`
+	Hi int `json:"hi"`
+}
+
+// Span returns the bits of the root register occupied by r of arch.
+//
+// The A32 "s" and "d" registers are packed into the "q" registers, "s0" and "s1" are the low and the high half of "d0",
+// and "d0" and "d1" are the low and the high half of "q0". The A64 registers of the same index share the root register,
+// "s0" is the low 32 bits of "v0" and "z0".
+func (r ArmReg) Span(arch string) (ArmRegSpan, error) {
+	class := LookupArmRegClass(arch, r.Class)
+	if class == nil || r.Index < 0 || r.Index >= class.Count {
+		return ArmRegSpan{}, fmt.Errorf("invalid %s register %s", arch, r)
+	}
+
+	span := ArmRegSpan{Root: ArmReg{Class: class.Root, Index: r.Index}, Lo: 0, Hi: class.Bits - 1}
+	if class.Packed {
+		root := LookupArmRegClass(arch, class.Root)
+		perRoot := root.Bits / class.Bits
+		span.Root.Index = r.Index / perRoot
+		span.Lo = r.Index % perRoot * class.Bits
+		span.Hi = span.Lo + class.Bits - 1
+	}
+
+	return span, nil
+}
+
+// Overlaps reports whether the registers r and other of arch share any bit, like "w5" and "x5", and "s1" and "d0".
+func (r ArmReg) Overlaps(arch string, other ArmReg) (bool, error) {
+	a, err := r.Span(arch)
+	if err != nil {
+		return false, err
+	}
+	b, err := other.Span(arch)
+	if err != nil {
+		return false, err
+	}
+	return a.Root == b.Root && a.Lo <= b.Hi && b.Lo <= a.Hi, nil
+}
+
+// Aliases returns the other registers of arch overlapping r, like "x5" for "w5", and "s0", "s1" and "q0" for the A32 "d0".
+func (r ArmReg) Aliases(arch string) ([]ArmReg, error) {
+	if _, err := r.Span(arch); err != nil {
+		return nil, err
+	}
+
+	var aliases []ArmReg
+	for _, class := range armRegClasses[armRegArch(arch)] {
+		for i := 0; i < class.Count; i++ {
+			other := ArmReg{Class: class.Name, Index: i}
+			if other == r {
+				continue
+			}
+			if ok, _ := r.Overlaps(arch, other); ok {
+				aliases = append(aliases, other)
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// PartialWrite reports whether writing r of arch keeps the other bits of its root register,
+// so the write depends on the previous value of the root register, like writing the A32 "s0" merges into "d0".
+func (r ArmReg) PartialWrite(arch string) (bool, error) {
+	span, err := r.Span(arch)
+	if err != nil {
+		return false, err
+	}
+	class := LookupArmRegClass(arch, r.Class)
+	root := LookupArmRegClass(arch, span.Root.Class)
+
+	return class.Bits < root.Bits && !class.ZeroExtends, nil
+}
+
+// writeArmRegClasses writes the register classes of each instruction set as JSON to w.
+func writeArmRegClasses(w io.Writer) error {
+	return writeJSON(w, "arm register classes", armRegClasses)
+}
@@ -0,0 +1,94 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseArmReg(t *testing.T) {
+	tests := []struct {
+		arch string
+		name string
+		want string
+	}{
+		{ArmA64, "W5", "w5"},
+		{ArmA64, "xzr", "x31"},
+		{ArmA64, "lr", "x30"},
+		{ArmA64, "sp", "sp"},
+		{ArmA64, "z31", "z31"},
+		{ArmT32, "lr", "r14"},
+		{ArmA32, "d31", "d31"},
+		// the invalid registers
+		{ArmA32, "s32", ""},
+		{ArmA32, "w0", ""},
+		{ArmA64, "sp0", ""},
+		{ArmA64, "p16", ""},
+		{ArmA64, "x", ""},
+	}
+	for _, tt := range tests {
+		r, err := ParseArmReg(tt.arch, tt.name)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseArmReg(%s, %q) = %s, want error", tt.arch, tt.name, r)
+			}
+			continue
+		}
+		if err != nil || r.String() != tt.want {
+			t.Errorf("ParseArmReg(%s, %q) = %s, %v, want %s", tt.arch, tt.name, r, err, tt.want)
+		}
+	}
+}
+
+func TestArmRegAliases(t *testing.T) {
+	tests := []struct {
+		arch string
+		reg  ArmReg
+		want string
+	}{
+		// the A32 "s" and "d" registers packed into the "q" registers
+		{ArmA32, ArmReg{Class: "d", Index: 0}, "[s0 s1 q0]"},
+		{ArmA32, ArmReg{Class: "s", Index: 3}, "[d1 q0]"},
+		{ArmA32, ArmReg{Class: "q", Index: 1}, "[s4 s5 s6 s7 d2 d3]"},
+		{ArmA32, ArmReg{Class: "d", Index: 16}, "[q8]"},
+		{ArmA32, ArmReg{Class: "r", Index: 13}, "[]"},
+		// the A64 registers of the same index
+		{ArmA64, ArmReg{Class: "w", Index: 5}, "[x5]"},
+		{ArmA64, ArmReg{Class: "wsp"}, "[sp]"},
+		{ArmA64, ArmReg{Class: "s", Index: 0}, "[b0 h0 d0 q0 v0 z0]"},
+	}
+	for _, tt := range tests {
+		aliases, err := tt.reg.Aliases(tt.arch)
+		if got := fmt.Sprint(aliases); err != nil || got != tt.want {
+			t.Errorf("Aliases(%s %s) = %s, %v, want %s", tt.arch, tt.reg, got, err, tt.want)
+		}
+	}
+
+	if aliases, err := (ArmReg{Class: "q", Index: 16}).Aliases(ArmA32); err == nil {
+		t.Errorf("Aliases(A32 q16) = %s, want error", aliases)
+	}
+}
+
+func TestArmRegPartialWrite(t *testing.T) {
+	tests := []struct {
+		arch string
+		reg  ArmReg
+		want bool
+	}{
+		{ArmA32, ArmReg{Class: "s", Index: 0}, true},
+		{ArmA32, ArmReg{Class: "d", Index: 1}, true},
+		{ArmA32, ArmReg{Class: "q", Index: 0}, false},
+		{ArmT16, ArmReg{Class: "r", Index: 0}, false},
+		// the A64 writes zero the rest of the root register
+		{ArmA64, ArmReg{Class: "w", Index: 5}, false},
+		{ArmA64, ArmReg{Class: "s", Index: 0}, false},
+		{ArmA64, ArmReg{Class: "z", Index: 0}, false},
+	}
+	for _, tt := range tests {
+		if got, err := tt.reg.PartialWrite(tt.arch); err != nil || got != tt.want {
+			t.Errorf("PartialWrite(%s %s) = %t, %v, want %t", tt.arch, tt.reg, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "json", "simd.json", "write the ASIMD forms with the data types and the arrangements JSON to `file`", &emitterFunc{name: "arm-simd", fn: func(m *Model, w io.Writer) error {
+		return writeArmSIMD(w, m.Arm, m.ArmInstructions)
+	}, out: []*ArmSIMDForm(nil)})
+}
+
+// ArmElementType represents an ASIMD element data type, like "s16" and "f32".
+type ArmElementType struct {
+	// Kind is the element kind, one of "i" (integer of any signedness, "x" in armdata.js), "s", "u", "f", "p",
+	// "any" for the untyped bitwise operation, or empty for the data type specifying only the size, like ".32".
+	Kind string `json:"kind,omitzero"`
+
+	// Sizes is the list of accepted element sizes in bits, like 8, 16 and 32 for "x8-32".
+	Sizes []int `json:"sizes,omitzero"`
+}
+
+// parseArmDataType parses the data type suffix of the instruction name, like "x8-32", "f32|x32" and "any".
+func parseArmDataType(s string) ([]ArmElementType, error) {
+	var types []ArmElementType
+	for _, alt := range strings.Split(strings.TrimSpace(s), "|") {
+		if alt == "any" {
+			types = append(types, ArmElementType{Kind: "any"})
+			continue
+		}
+
+		var t ArmElementType
+		if alt != "" && strings.IndexByte("xsufp", alt[0]) >= 0 {
+			t.Kind = alt[:1]
+			if t.Kind == "x" {
+				t.Kind = "i"
+			}
+			alt = alt[1:]
+		}
+		lo, hi := alt, alt
+		if i := strings.IndexByte(alt, '-'); i >= 0 {
+			lo, hi = alt[:i], strings.TrimLeft(alt[i+1:], "xsufp") // "x8-x32" has the kind repeated
+		}
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data type %q", s)
+		}
+		to, err := strconv.Atoi(hi)
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid data type %q", s)
+		}
+		for size := from; size <= to; size *= 2 {
+			t.Sizes = append(t.Sizes, size)
+		}
+		types = append(types, t)
+	}
+
+	return types, nil
+}
+
+// splitArmName splits the instruction name into the mnemonic and the data type suffixes,
+// like "vcvt" and ["f32", "s32"] for "vcvt.f32.s32". The placeholder "<dt>" suffix is dropped.
+func splitArmName(name string) (mnemonic string, suffixes []string) {
+	parts := strings.Split(name, ".")
+	for _, p := range parts[1:] {
+		if p = strings.TrimSpace(p); p != "" && p != "<dt>" {
+			suffixes = append(suffixes, p)
+		}
+	}
+	return parts[0], suffixes
+}
+
+// armVectorBits is the width in bits of the vector register classes of the ASIMD instruction operands.
+//
+// The "V" registers of the ASIMD forms are the 128-bit Q registers.
+var armVectorBits = map[string]int{
+	"s": 32,
+	"d": 64,
+	"v": 128,
+}
+
+// armArrangementSuffixes maps the element size to the arrangement suffix, like "B" for 8 bits.
+var armArrangementSuffixes = map[int]string{
+	8:  "B",
+	16: "H",
+	32: "S",
+	64: "D",
+}
+
+// armArrangement returns the arrangement specifier of the vector of bits wide with the element size, like "8B" and "2D".
+func armArrangement(bits, size int) string {
+	return strconv.Itoa(bits/size) + armArrangementSuffixes[size]
+}
+
+// ArmSIMDOperand represents the shape of the ASIMD instruction register operand.
+type ArmSIMDOperand struct {
+	Index int `json:"index"`
+
+	// Class is the register class, "s", "d" or "v" for the Q register.
+	Class string `json:"class"`
+
+	// Bits is the width of the register in bits.
+	Bits int `json:"bits"`
+
+	// Sizes is the list of the element sizes of the operand in bits.
+	Sizes []int `json:"sizes,omitzero"`
+
+	// Arrangements is the list of the arrangement specifiers of the vector operand for each element size,
+	// like "8B" and "16B". It's empty for the scalar S register operand.
+	Arrangements []string `json:"arrangements,omitzero"`
+
+	// Indexed reports whether the operand is a single element selected by the following "#Idx" operand.
+	Indexed bool `json:"indexed,omitzero"`
+}
+
+// ArmSIMDForm represents the ASIMD instruction form with the data types and the operand shapes.
+type ArmSIMDForm struct {
+	Name     string `json:"name"`
+	Mnemonic string `json:"mnemonic"`
+	Operands string `json:"operands,omitzero"`
+	Arch     string `json:"arch"`
+
+	// DataTypes is the list of the data types for each data type suffix of the name,
+	// the destination type followed by the source type for the conversions, like "f32" and "s32" for "vcvt.f32.s32".
+	DataTypes [][]ArmElementType `json:"dataTypes,omitzero"`
+
+	// Widen and Narrow report whether the destination elements are twice or half as wide as the source elements.
+	Widen  bool `json:"widen,omitzero"`
+	Narrow bool `json:"narrow,omitzero"`
+
+	Shapes []*ArmSIMDOperand `json:"shapes,omitzero"`
+}
+
+// armSIMDSizes returns the union of the element sizes of the data types.
+// The data types without the size, like "any", have the 8-bit elements.
+func armSIMDSizes(types []ArmElementType) []int {
+	var sizes []int
+	for _, t := range types {
+		ts := t.Sizes
+		if len(ts) == 0 {
+			ts = []int{8}
+		}
+		for _, size := range ts {
+			if !containsInt(sizes, size) {
+				sizes = append(sizes, size)
+			}
+		}
+	}
+	return sizes
+}
+
+// newArmSIMDForm returns the ASIMD form of inst.
+//
+// The widening instructions name the narrow source elements. The narrowing instructions name either the narrow
+// destination or the wide source elements in armdata.js, like "vaddhn.x8-32" and "vqmovn.x16-64", so
+// narrowDst reports whether the mnemonic names the narrow destination elements.
+func newArmSIMDForm(arm *Arm, inst *ArmInstruction, narrowDst bool) (*ArmSIMDForm, error) {
+	meta := arm.ParseMetadata(inst.Metadata)
+	mnemonic, suffixes := splitArmName(inst.Name)
+	form := &ArmSIMDForm{
+		Name:     inst.Name,
+		Mnemonic: mnemonic,
+		Operands: inst.Operands,
+		Arch:     inst.Arch,
+	}
+	_, form.Widen = meta.Attributes["VEC_WIDEN"]
+	_, form.Narrow = meta.Attributes["VEC_NARROW"]
+	for _, suffix := range suffixes {
+		types, err := parseArmDataType(suffix)
+		if err != nil {
+			return nil, err
+		}
+		form.DataTypes = append(form.DataTypes, types)
+	}
+
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		bits, ok := armVectorBits[op.Class]
+		if op.Type != ArmOperandReg || !ok {
+			continue
+		}
+		shape := &ArmSIMDOperand{
+			Index:   op.Index,
+			Class:   op.Class,
+			Bits:    bits,
+			Indexed: i+1 < len(ops) && ops[i+1].Field == "Idx",
+		}
+
+		if len(form.DataTypes) > 0 {
+			types := form.DataTypes[0]
+			if op.Index > 0 && len(form.DataTypes) > 1 {
+				types = form.DataTypes[1]
+			}
+			for _, size := range armSIMDSizes(types) {
+				switch {
+				case form.Widen && bits == 128:
+					size *= 2
+				case form.Narrow && narrowDst && bits == 128:
+					size *= 2
+				case form.Narrow && !narrowDst && bits == 64:
+					size /= 2
+				}
+				shape.Sizes = append(shape.Sizes, size)
+			}
+		}
+		if bits > 32 {
+			for _, size := range shape.Sizes {
+				if size <= bits {
+					shape.Arrangements = append(shape.Arrangements, armArrangement(bits, size))
+				}
+			}
+		}
+		form.Shapes = append(form.Shapes, shape)
+	}
+
+	return form, nil
+}
+
+// armSIMDForms returns the ASIMD forms of insts.
+func armSIMDForms(arm *Arm, insts []ArmInstruction) ([]*ArmSIMDForm, error) {
+	asimd := arm.InstructionsWithExtension(insts, "ASIMD")
+
+	// the narrowing mnemonic names the narrow destination elements if any of its forms has the 8-bit elements
+	narrowDst := make(map[string]bool)
+	for _, inst := range asimd {
+		if _, ok := arm.ParseMetadata(inst.Metadata).Attributes["VEC_NARROW"]; !ok {
+			continue
+		}
+		mnemonic, suffixes := splitArmName(inst.Name)
+		for _, suffix := range suffixes {
+			types, err := parseArmDataType(suffix)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", inst.Name, err)
+			}
+			if containsInt(armSIMDSizes(types), 8) {
+				narrowDst[mnemonic] = true
+			}
+		}
+	}
+
+	forms := make([]*ArmSIMDForm, 0, len(asimd))
+	for i := range asimd {
+		mnemonic, _ := splitArmName(asimd[i].Name)
+		form, err := newArmSIMDForm(arm, &asimd[i], narrowDst[mnemonic])
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", asimd[i].Name, asimd[i].Operands, err)
+		}
+		forms = append(forms, form)
+	}
+
+	return forms, nil
+}
+
+// writeArmSIMD writes the ASIMD forms of insts as JSON to w.
+func writeArmSIMD(w io.Writer, arm *Arm, insts []ArmInstruction) error {
+	forms, err := armSIMDForms(arm, insts)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, "asimd forms", forms)
+}
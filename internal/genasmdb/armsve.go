@@ -0,0 +1,116 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("sve", "sve.json", "write the SVE %s forms and their predication as JSON to `file`", []*ArmSVEForm{}, nil, armSVEForm)
+}
+
+// SVE governing predicate predications.
+//
+// armdata.js has no A64 instruction yet, so no SVE form either. The SVE operands are parsed
+// in the ARM reference manual syntax, like "Zdn.T, Pg/M, Zdn.T, Zm.T", once they are added to the data.
+const (
+	// ArmPredZeroing is the zeroing predication ("Pg/Z"), the inactive elements of the destination are set to zero.
+	ArmPredZeroing = "Z"
+	// ArmPredMerging is the merging predication ("Pg/M"), the inactive elements of the destination are unchanged.
+	ArmPredMerging = "M"
+)
+
+// ArmSVE represents the SVE semantics of the instruction form.
+type ArmSVE struct {
+	// Scalable reports whether the form operates on the scalable vector or predicate registers,
+	// the vector length is not known until the run time (vector-length agnostic).
+	Scalable bool `json:"scalable,omitzero"`
+
+	// Governing is the index of the governing predicate operand, or -1 if the form isn't predicated.
+	Governing int `json:"governing"`
+
+	// Predication is the predication of the governing predicate, ArmPredZeroing or ArmPredMerging,
+	// or empty if the predicate has no qualifier, like the predicate of the loads and stores.
+	Predication string `json:"predication,omitzero"`
+
+	// Destructive reports whether the destination register is also a source register, like "Zdn" of
+	// "add Zdn.T, Pg/M, Zdn.T, Zm.T". The constructive form has the separate destination register.
+	Destructive bool `json:"destructive,omitzero"`
+}
+
+// SVE returns the SVE semantics of the instruction, or nil if the form has no scalable vector or predicate register.
+func (a *Arm) SVE(inst *ArmInstruction) (*ArmSVE, error) {
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	if sve := newArmSVE(ops); sve.Scalable {
+		return sve, nil
+	}
+	return nil, nil
+}
+
+// newArmSVE returns the SVE semantics of the form of the operands.
+func newArmSVE(ops []*ArmOperand) *ArmSVE {
+	form := &ArmSVE{Governing: -1}
+	for _, op := range ops {
+		if op.Type != ArmOperandReg && op.Type != ArmOperandRegList {
+			continue
+		}
+		if op.Class == "z" || op.Class == "p" {
+			form.Scalable = true
+		}
+		if op.Class == "p" && op.Index > 0 && form.Governing < 0 && strings.HasPrefix(op.Field, "Pg") {
+			form.Governing = op.Index
+			form.Predication = op.Predication
+		}
+	}
+	form.Destructive = armIsDestructive(ops)
+
+	return form
+}
+
+// armIsDestructive reports whether the destination register operand is also a source register operand.
+//
+// The destination is destructive if its field is named as both the destination and the source, like "Zdn", "Zda" and "Rx",
+// or if it's repeated or referred by the "==" constraint of the other operand.
+func armIsDestructive(ops []*ArmOperand) bool {
+	if len(ops) == 0 || ops[0].Type != ArmOperandReg {
+		return false
+	}
+	dst := ops[0].Field
+	if strings.HasSuffix(dst, "dn") || strings.HasSuffix(dst, "da") {
+		return true
+	}
+	for _, op := range ops[1:] {
+		if op.Field == dst {
+			return true
+		}
+		for _, c := range op.Constraints {
+			if c.Op == "==" && c.Value == dst {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ArmSVEForm represents an SVE instruction form.
+type ArmSVEForm struct {
+	Name     string  `json:"name"`
+	Operands string  `json:"operands,omitzero"`
+	Encoding string  `json:"encoding"`
+	OpCode   string  `json:"opcode"`
+	SVE      *ArmSVE `json:"sve"`
+}
+
+// armSVEForm returns the form of inst if it's an SVE form, or nil.
+func armSVEForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	sve, err := a.SVE(inst)
+	if err != nil || sve == nil {
+		return nil, err
+	}
+	return &ArmSVEForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, SVE: sve}, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArmSVE(t *testing.T) {
+	_, arm := testModels(t)
+	// armdata.js has no A64 forms, so the SVE forms are written in its syntax
+	tests := []struct {
+		name     string
+		operands string
+		opcode   string
+		want     *ArmSVE
+	}{
+		{"add", "Zdn.T, Pg/M, Zdn.T, Zm.T", "00000100|Size:2|000|000|000|Pg:3|Zm:5|Zdn:5", &ArmSVE{Scalable: true, Governing: 1, Predication: ArmPredMerging, Destructive: true}},
+		{"movprfx", "Zd.T, Pg/Z, Zn.T", "00000100|Size:2|010|00|0|001|Pg:3|Zn:5|Zd:5", &ArmSVE{Scalable: true, Governing: 1, Predication: ArmPredZeroing}},
+		{"mla", "Zda.T, Pg/M, Zn.T, Zm.T", "00000100|Size:2|0|Zm:5|01|0|Pg:3|Zn:5|Zda:5", &ArmSVE{Scalable: true, Governing: 1, Predication: ArmPredMerging, Destructive: true}},
+		{"add", "Zd.T, Zn.T, Zm.T", "00000100|Size:2|1|Zm:5|000|000|Zn:5|Zd:5", &ArmSVE{Scalable: true, Governing: -1}},
+		{"and", "Pd.B, Pg/Z, Pn.B, Pm.B", "00100101|0|0|00|Pm:4|01|Pg:4|0|Pn:4|0|Pd:4", &ArmSVE{Scalable: true, Governing: 1, Predication: ArmPredZeroing}},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.SVE(&ArmInstruction{Name: tt.name, Operands: tt.operands, OpCode: tt.opcode, Arch: "A64"})
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SVE(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+
+	// the destructive forms without the scalable registers
+	for _, inst := range []*ArmInstruction{
+		testArmForm(t, "adc", "Rx!=HI, Rx!=HI, Rm!=HI", ArmT16),
+		testArmForm(t, "vadd.f32", "Sd, Sn, Sm", ArmA32),
+	} {
+		if got, err := arm.Arm.SVE(inst); err != nil || got != nil {
+			t.Errorf("SVE(%s %s) = %+v, %v, want nil", inst.Name, inst.Operands, got, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "json", "sysregs.json", "write the AArch64 system registers JSON to `file`", &emitterFunc{name: "arm-sysregs", fn: func(m *Model, w io.Writer) error {
+		return writeArmSysRegs(w)
+	}, out: ArmSystemRegisters{}})
+}
+
+// ArmSysRegAccess is the MRS/MSR access of the AArch64 system register.
+type ArmSysRegAccess string
+
+const (
+	// ArmSysRegRW is the read/write system register, accessed by both MRS and MSR.
+	ArmSysRegRW ArmSysRegAccess = "RW"
+	// ArmSysRegRO is the read-only system register, accessed only by MRS.
+	ArmSysRegRO ArmSysRegAccess = "RO"
+	// ArmSysRegWO is the write-only system register, accessed only by MSR.
+	ArmSysRegWO ArmSysRegAccess = "WO"
+)
+
+// ArmSysReg represents an AArch64 system register accessed by the MRS and MSR instructions.
+type ArmSysReg struct {
+	Name string `json:"name"`
+
+	// Op0, Op1, CRn, CRm and Op2 are the encoding fields, like "S3_3_C4_C2_0" of "NZCV".
+	Op0 uint8 `json:"op0"`
+	Op1 uint8 `json:"op1"`
+	CRn uint8 `json:"crn"`
+	CRm uint8 `json:"crm"`
+	Op2 uint8 `json:"op2"`
+
+	Access ArmSysRegAccess `json:"access"`
+
+	// EL is the lowest exception level the register can be accessed from, 0..3.
+	// The access from a lower exception level is trapped or undefined.
+	EL int `json:"el"`
+
+	// WriteEL is the lowest exception level the register can be written from if it's above EL,
+	// like 1 of "TPIDRRO_EL0", which is read-only at EL0, or 0 if it's EL.
+	WriteEL int `json:"writeEL,omitzero"`
+
+	// Feature is the FEAT_* feature required by the register, or empty if it's in the base architecture.
+	Feature string `json:"feature,omitzero"`
+}
+
+// Value returns the 16-bit "op0:op1:CRn:CRm:op2" value, the bits [20:5] of the MRS and MSR instruction.
+func (r *ArmSysReg) Value() uint16 {
+	return armSysRegValue(r.Op0, r.Op1, r.CRn, r.CRm, r.Op2)
+}
+
+// Generic returns the generic name of the register encoding, like "S3_3_C4_C2_0".
+func (r *ArmSysReg) Generic() string {
+	return fmt.Sprintf("S%d_%d_C%d_C%d_%d", r.Op0, r.Op1, r.CRn, r.CRm, r.Op2)
+}
+
+// CanRead reports whether the register can be read by MRS.
+func (r *ArmSysReg) CanRead() bool { return r.Access != ArmSysRegWO }
+
+// CanWrite reports whether the register can be written by MSR.
+func (r *ArmSysReg) CanWrite() bool { return r.Access != ArmSysRegRO }
+
+// AccessEL returns the lowest exception level the register can be read from by MRS, if read is true, or written by MSR.
+func (r *ArmSysReg) AccessEL(read bool) int {
+	if !read && r.WriteEL > r.EL {
+		return r.WriteEL
+	}
+	return r.EL
+}
+
+// armSysRegValue returns the 16-bit "op0:op1:CRn:CRm:op2" value of the encoding fields.
+func armSysRegValue(op0, op1, crn, crm, op2 uint8) uint16 {
+	return uint16(op0)<<14 | uint16(op1)<<11 | uint16(crn)<<7 | uint16(crm)<<3 | uint16(op2)
+}
+
+// ArmSysRegs is a list of the AArch64 system registers.
+//
+// The list is not exhaustive, it covers the registers commonly accessed by the user, the kernel and the hypervisor code.
+// The other registers can be accessed by the generic name, like "S3_0_C15_C2_0".
+var ArmSysRegs = []*ArmSysReg{
+	// special-purpose registers
+	{Name: "NZCV", Op0: 3, Op1: 3, CRn: 4, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 0},
+	{Name: "DAIF", Op0: 3, Op1: 3, CRn: 4, CRm: 2, Op2: 1, Access: ArmSysRegRW, EL: 0},
+	{Name: "DIT", Op0: 3, Op1: 3, CRn: 4, CRm: 2, Op2: 5, Access: ArmSysRegRW, EL: 0, Feature: "FEAT_DIT"},
+	{Name: "SSBS", Op0: 3, Op1: 3, CRn: 4, CRm: 2, Op2: 6, Access: ArmSysRegRW, EL: 0, Feature: "FEAT_SSBS"},
+	{Name: "TCO", Op0: 3, Op1: 3, CRn: 4, CRm: 2, Op2: 7, Access: ArmSysRegRW, EL: 0, Feature: "FEAT_MTE"},
+	{Name: "FPCR", Op0: 3, Op1: 3, CRn: 4, CRm: 4, Op2: 0, Access: ArmSysRegRW, EL: 0},
+	{Name: "FPSR", Op0: 3, Op1: 3, CRn: 4, CRm: 4, Op2: 1, Access: ArmSysRegRW, EL: 0},
+	{Name: "SPSel", Op0: 3, Op1: 0, CRn: 4, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "CurrentEL", Op0: 3, Op1: 0, CRn: 4, CRm: 2, Op2: 2, Access: ArmSysRegRO, EL: 1},
+	{Name: "PAN", Op0: 3, Op1: 0, CRn: 4, CRm: 2, Op2: 3, Access: ArmSysRegRW, EL: 1, Feature: "FEAT_PAN"},
+	{Name: "UAO", Op0: 3, Op1: 0, CRn: 4, CRm: 2, Op2: 4, Access: ArmSysRegRW, EL: 1, Feature: "FEAT_UAO"},
+	{Name: "SPSR_EL1", Op0: 3, Op1: 0, CRn: 4, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "ELR_EL1", Op0: 3, Op1: 0, CRn: 4, CRm: 0, Op2: 1, Access: ArmSysRegRW, EL: 1},
+	{Name: "SP_EL0", Op0: 3, Op1: 0, CRn: 4, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "SPSR_EL2", Op0: 3, Op1: 4, CRn: 4, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "ELR_EL2", Op0: 3, Op1: 4, CRn: 4, CRm: 0, Op2: 1, Access: ArmSysRegRW, EL: 2},
+	{Name: "SP_EL1", Op0: 3, Op1: 4, CRn: 4, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "SPSR_EL3", Op0: 3, Op1: 6, CRn: 4, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 3},
+	{Name: "ELR_EL3", Op0: 3, Op1: 6, CRn: 4, CRm: 0, Op2: 1, Access: ArmSysRegRW, EL: 3},
+	{Name: "SP_EL2", Op0: 3, Op1: 6, CRn: 4, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 3},
+
+	// identification registers
+	{Name: "MIDR_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 0, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "MPIDR_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 0, Op2: 5, Access: ArmSysRegRO, EL: 1},
+	{Name: "REVIDR_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 0, Op2: 6, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64PFR0_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 4, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64PFR1_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 4, Op2: 1, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64ZFR0_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 4, Op2: 4, Access: ArmSysRegRO, EL: 1, Feature: "FEAT_SVE"},
+	{Name: "ID_AA64DFR0_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 5, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64DFR1_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 5, Op2: 1, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64ISAR0_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 6, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64ISAR1_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 6, Op2: 1, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64MMFR0_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 7, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64MMFR1_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 7, Op2: 1, Access: ArmSysRegRO, EL: 1},
+	{Name: "ID_AA64MMFR2_EL1", Op0: 3, Op1: 0, CRn: 0, CRm: 7, Op2: 2, Access: ArmSysRegRO, EL: 1},
+	{Name: "CCSIDR_EL1", Op0: 3, Op1: 1, CRn: 0, CRm: 0, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "CLIDR_EL1", Op0: 3, Op1: 1, CRn: 0, CRm: 0, Op2: 1, Access: ArmSysRegRO, EL: 1},
+	{Name: "AIDR_EL1", Op0: 3, Op1: 1, CRn: 0, CRm: 0, Op2: 7, Access: ArmSysRegRO, EL: 1},
+	{Name: "CSSELR_EL1", Op0: 3, Op1: 2, CRn: 0, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "CTR_EL0", Op0: 3, Op1: 3, CRn: 0, CRm: 0, Op2: 1, Access: ArmSysRegRO, EL: 0},
+	{Name: "DCZID_EL0", Op0: 3, Op1: 3, CRn: 0, CRm: 0, Op2: 7, Access: ArmSysRegRO, EL: 0},
+	{Name: "RNDR", Op0: 3, Op1: 3, CRn: 2, CRm: 4, Op2: 0, Access: ArmSysRegRO, EL: 0, Feature: "FEAT_RNG"},
+	{Name: "RNDRRS", Op0: 3, Op1: 3, CRn: 2, CRm: 4, Op2: 1, Access: ArmSysRegRO, EL: 0, Feature: "FEAT_RNG"},
+
+	// system control and memory management registers
+	{Name: "SCTLR_EL1", Op0: 3, Op1: 0, CRn: 1, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "ACTLR_EL1", Op0: 3, Op1: 0, CRn: 1, CRm: 0, Op2: 1, Access: ArmSysRegRW, EL: 1},
+	{Name: "CPACR_EL1", Op0: 3, Op1: 0, CRn: 1, CRm: 0, Op2: 2, Access: ArmSysRegRW, EL: 1},
+	{Name: "ZCR_EL1", Op0: 3, Op1: 0, CRn: 1, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 1, Feature: "FEAT_SVE"},
+	{Name: "TTBR0_EL1", Op0: 3, Op1: 0, CRn: 2, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "TTBR1_EL1", Op0: 3, Op1: 0, CRn: 2, CRm: 0, Op2: 1, Access: ArmSysRegRW, EL: 1},
+	{Name: "TCR_EL1", Op0: 3, Op1: 0, CRn: 2, CRm: 0, Op2: 2, Access: ArmSysRegRW, EL: 1},
+	{Name: "AFSR0_EL1", Op0: 3, Op1: 0, CRn: 5, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "AFSR1_EL1", Op0: 3, Op1: 0, CRn: 5, CRm: 1, Op2: 1, Access: ArmSysRegRW, EL: 1},
+	{Name: "ESR_EL1", Op0: 3, Op1: 0, CRn: 5, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "FAR_EL1", Op0: 3, Op1: 0, CRn: 6, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "PAR_EL1", Op0: 3, Op1: 0, CRn: 7, CRm: 4, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "MAIR_EL1", Op0: 3, Op1: 0, CRn: 10, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "AMAIR_EL1", Op0: 3, Op1: 0, CRn: 10, CRm: 3, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "VBAR_EL1", Op0: 3, Op1: 0, CRn: 12, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "CONTEXTIDR_EL1", Op0: 3, Op1: 0, CRn: 13, CRm: 0, Op2: 1, Access: ArmSysRegRW, EL: 1},
+	{Name: "TPIDR_EL1", Op0: 3, Op1: 0, CRn: 13, CRm: 0, Op2: 4, Access: ArmSysRegRW, EL: 1},
+	{Name: "CNTKCTL_EL1", Op0: 3, Op1: 0, CRn: 14, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "SCTLR_EL2", Op0: 3, Op1: 4, CRn: 1, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "HCR_EL2", Op0: 3, Op1: 4, CRn: 1, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "CPTR_EL2", Op0: 3, Op1: 4, CRn: 1, CRm: 1, Op2: 2, Access: ArmSysRegRW, EL: 2},
+	{Name: "TTBR0_EL2", Op0: 3, Op1: 4, CRn: 2, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "TCR_EL2", Op0: 3, Op1: 4, CRn: 2, CRm: 0, Op2: 2, Access: ArmSysRegRW, EL: 2},
+	{Name: "VTTBR_EL2", Op0: 3, Op1: 4, CRn: 2, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "VTCR_EL2", Op0: 3, Op1: 4, CRn: 2, CRm: 1, Op2: 2, Access: ArmSysRegRW, EL: 2},
+	{Name: "ESR_EL2", Op0: 3, Op1: 4, CRn: 5, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "FAR_EL2", Op0: 3, Op1: 4, CRn: 6, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "MAIR_EL2", Op0: 3, Op1: 4, CRn: 10, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "VBAR_EL2", Op0: 3, Op1: 4, CRn: 12, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "TPIDR_EL2", Op0: 3, Op1: 4, CRn: 13, CRm: 0, Op2: 2, Access: ArmSysRegRW, EL: 2},
+	{Name: "CNTHCTL_EL2", Op0: 3, Op1: 4, CRn: 14, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 2},
+	{Name: "CNTVOFF_EL2", Op0: 3, Op1: 4, CRn: 14, CRm: 0, Op2: 3, Access: ArmSysRegRW, EL: 2},
+	{Name: "SCTLR_EL3", Op0: 3, Op1: 6, CRn: 1, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 3},
+	{Name: "SCR_EL3", Op0: 3, Op1: 6, CRn: 1, CRm: 1, Op2: 0, Access: ArmSysRegRW, EL: 3},
+	{Name: "CPTR_EL3", Op0: 3, Op1: 6, CRn: 1, CRm: 1, Op2: 2, Access: ArmSysRegRW, EL: 3},
+	{Name: "TTBR0_EL3", Op0: 3, Op1: 6, CRn: 2, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 3},
+	{Name: "TCR_EL3", Op0: 3, Op1: 6, CRn: 2, CRm: 0, Op2: 2, Access: ArmSysRegRW, EL: 3},
+	{Name: "ESR_EL3", Op0: 3, Op1: 6, CRn: 5, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 3},
+	{Name: "MAIR_EL3", Op0: 3, Op1: 6, CRn: 10, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 3},
+	{Name: "VBAR_EL3", Op0: 3, Op1: 6, CRn: 12, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 3},
+
+	// thread and timer registers, CNTFRQ_EL0 is written only at the highest implemented exception level
+	{Name: "TPIDR_EL0", Op0: 3, Op1: 3, CRn: 13, CRm: 0, Op2: 2, Access: ArmSysRegRW, EL: 0},
+	{Name: "TPIDRRO_EL0", Op0: 3, Op1: 3, CRn: 13, CRm: 0, Op2: 3, Access: ArmSysRegRW, EL: 0, WriteEL: 1},
+	{Name: "CNTFRQ_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 0, Op2: 0, Access: ArmSysRegRW, EL: 0, WriteEL: 3},
+	{Name: "CNTPCT_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 0, Op2: 1, Access: ArmSysRegRO, EL: 0},
+	{Name: "CNTVCT_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 0, Op2: 2, Access: ArmSysRegRO, EL: 0},
+	{Name: "CNTP_TVAL_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 2, Op2: 0, Access: ArmSysRegRW, EL: 0},
+	{Name: "CNTP_CTL_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 2, Op2: 1, Access: ArmSysRegRW, EL: 0},
+	{Name: "CNTP_CVAL_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 2, Op2: 2, Access: ArmSysRegRW, EL: 0},
+	{Name: "CNTV_TVAL_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 3, Op2: 0, Access: ArmSysRegRW, EL: 0},
+	{Name: "CNTV_CTL_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 3, Op2: 1, Access: ArmSysRegRW, EL: 0},
+	{Name: "CNTV_CVAL_EL0", Op0: 3, Op1: 3, CRn: 14, CRm: 3, Op2: 2, Access: ArmSysRegRW, EL: 0},
+
+	// performance monitors registers
+	{Name: "PMCR_EL0", Op0: 3, Op1: 3, CRn: 9, CRm: 12, Op2: 0, Access: ArmSysRegRW, EL: 0},
+	{Name: "PMCCNTR_EL0", Op0: 3, Op1: 3, CRn: 9, CRm: 13, Op2: 0, Access: ArmSysRegRW, EL: 0},
+	{Name: "PMUSERENR_EL0", Op0: 3, Op1: 3, CRn: 9, CRm: 14, Op2: 0, Access: ArmSysRegRW, EL: 0, WriteEL: 1},
+
+	// debug registers
+	{Name: "MDSCR_EL1", Op0: 2, Op1: 0, CRn: 0, CRm: 2, Op2: 2, Access: ArmSysRegRW, EL: 1},
+	{Name: "OSLAR_EL1", Op0: 2, Op1: 0, CRn: 1, CRm: 0, Op2: 4, Access: ArmSysRegWO, EL: 1},
+	{Name: "OSLSR_EL1", Op0: 2, Op1: 0, CRn: 1, CRm: 1, Op2: 4, Access: ArmSysRegRO, EL: 1},
+
+	// generic interrupt controller CPU interface registers
+	{Name: "ICC_PMR_EL1", Op0: 3, Op1: 0, CRn: 4, CRm: 6, Op2: 0, Access: ArmSysRegRW, EL: 1},
+	{Name: "ICC_IAR1_EL1", Op0: 3, Op1: 0, CRn: 12, CRm: 12, Op2: 0, Access: ArmSysRegRO, EL: 1},
+	{Name: "ICC_EOIR1_EL1", Op0: 3, Op1: 0, CRn: 12, CRm: 12, Op2: 1, Access: ArmSysRegWO, EL: 1},
+	{Name: "ICC_SRE_EL1", Op0: 3, Op1: 0, CRn: 12, CRm: 12, Op2: 5, Access: ArmSysRegRW, EL: 1},
+	{Name: "ICC_IGRPEN1_EL1", Op0: 3, Op1: 0, CRn: 12, CRm: 12, Op2: 7, Access: ArmSysRegRW, EL: 1},
+}
+
+// ArmPStateField represents a PSTATE field written by the "MSR <pstatefield>, #imm" instruction.
+type ArmPStateField struct {
+	Name string `json:"name"`
+
+	// Op1 and Op2 are the encoding fields of the field.
+	Op1 uint8 `json:"op1"`
+	Op2 uint8 `json:"op2"`
+
+	// Max is the largest immediate value.
+	Max int `json:"max"`
+
+	EL      int    `json:"el"`
+	Feature string `json:"feature,omitzero"`
+}
+
+// ArmPStateFields is a list of the PSTATE fields written by the "MSR <pstatefield>, #imm" instruction.
+var ArmPStateFields = []*ArmPStateField{
+	{Name: "SPSel", Op1: 0, Op2: 5, Max: 1, EL: 1},
+	{Name: "DAIFSet", Op1: 3, Op2: 6, Max: 15, EL: 0},
+	{Name: "DAIFClr", Op1: 3, Op2: 7, Max: 15, EL: 0},
+	{Name: "UAO", Op1: 0, Op2: 3, Max: 1, EL: 1, Feature: "FEAT_UAO"},
+	{Name: "PAN", Op1: 0, Op2: 4, Max: 1, EL: 1, Feature: "FEAT_PAN"},
+	{Name: "DIT", Op1: 3, Op2: 2, Max: 1, EL: 0, Feature: "FEAT_DIT"},
+	{Name: "SSBS", Op1: 3, Op2: 1, Max: 1, EL: 0, Feature: "FEAT_SSBS"},
+	{Name: "TCO", Op1: 3, Op2: 4, Max: 1, EL: 0, Feature: "FEAT_MTE"},
+}
+
+// armSysRegGenericRe matches the generic system register name, like "S3_3_C4_C2_0".
+var armSysRegGenericRe = regexp.MustCompile(`^[Ss]([23])_([0-7])_[Cc](\d+)_[Cc](\d+)_([0-7])$`)
+
+// LookupArmSysReg returns the system register of the name, case-insensitive, or the generic name, like "S3_3_C4_C2_0".
+//
+// The generic name of an unlisted encoding returns the register named by the generic name with the read/write access.
+func LookupArmSysReg(name string) (*ArmSysReg, error) {
+	for _, r := range ArmSysRegs {
+		if strings.EqualFold(r.Name, name) {
+			return r, nil
+		}
+	}
+
+	m := armSysRegGenericRe.FindStringSubmatch(name)
+	if m == nil {
+		return nil, fmt.Errorf("unknown system register %q", name)
+	}
+	var fields [5]uint8
+	for i := range fields {
+		n, _ := strconv.Atoi(m[i+1])
+		if n > 15 {
+			return nil, fmt.Errorf("invalid system register %q: C%d is out of range", name, n)
+		}
+		fields[i] = uint8(n)
+	}
+	if r := armSysRegByValue(armSysRegValue(fields[0], fields[1], fields[2], fields[3], fields[4])); r != nil {
+		return r, nil
+	}
+
+	return &ArmSysReg{
+		Name:   strings.ToUpper(name),
+		Op0:    fields[0],
+		Op1:    fields[1],
+		CRn:    fields[2],
+		CRm:    fields[3],
+		Op2:    fields[4],
+		Access: ArmSysRegRW,
+	}, nil
+}
+
+// armSysRegByValue returns the listed system register of the 16-bit "op0:op1:CRn:CRm:op2" value, or nil.
+func armSysRegByValue(v uint16) *ArmSysReg {
+	for _, r := range ArmSysRegs {
+		if r.Value() == v {
+			return r
+		}
+	}
+	return nil
+}
+
+// FormatArmSysReg returns the name of the system register of the 16-bit "op0:op1:CRn:CRm:op2" value,
+// or the generic name, like "S3_0_C15_C2_0", if the register isn't listed.
+func FormatArmSysReg(v uint16) string {
+	if r := armSysRegByValue(v); r != nil {
+		return r.Name
+	}
+	return fmt.Sprintf("S%d_%d_C%d_C%d_%d", v>>14&3, v>>11&7, v>>7&15, v>>3&15, v&7)
+}
+
+// CheckArmSysRegAccess checks the register name can be accessed by MRS, if read is true, or by MSR at the exception level el.
+// The writes below the WriteEL of the register, like "TPIDRRO_EL0" at EL0, are UNDEFINED.
+func CheckArmSysRegAccess(name string, read bool, el int) error {
+	r, err := LookupArmSysReg(name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case read && !r.CanRead():
+		return fmt.Errorf("system register %s is write-only", r.Name)
+	case !read && !r.CanWrite():
+		return fmt.Errorf("system register %s is read-only", r.Name)
+	case el < r.EL:
+		return fmt.Errorf("system register %s is not accessible from EL%d", r.Name, el)
+	case el < r.AccessEL(read):
+		return fmt.Errorf("system register %s is not writable from EL%d", r.Name, el)
+	}
+	return nil
+}
+
+// ArmSystemRegisters represents the AArch64 system registers and the PSTATE fields.
+type ArmSystemRegisters struct {
+	SysRegs      []*ArmSysReg      `json:"sysRegs"`
+	PStateFields []*ArmPStateField `json:"pstateFields"`
+}
+
+// writeArmSysRegs writes the AArch64 system registers and the PSTATE fields as JSON to w.
+func writeArmSysRegs(w io.Writer) error {
+	v := &ArmSystemRegisters{
+		SysRegs:      ArmSysRegs,
+		PStateFields: ArmPStateFields,
+	}
+
+	return writeJSON(w, "arm system registers", v)
+}
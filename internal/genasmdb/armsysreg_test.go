@@ -0,0 +1,66 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "testing"
+
+func TestLookupArmSysReg(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		value uint16
+	}{
+		{"NZCV", "NZCV", 0xda10},
+		{"tpidr_el0", "TPIDR_EL0", 0xde82},
+		// the generic names of the listed and the unlisted encodings
+		{"S3_3_C4_C2_0", "NZCV", 0xda10},
+		{"s3_0_c15_c2_0", "S3_0_C15_C2_0", 0xc790},
+	}
+	for _, tt := range tests {
+		r, err := LookupArmSysReg(tt.name)
+		if err != nil || r.Name != tt.want || r.Value() != tt.value {
+			t.Errorf("LookupArmSysReg(%q) = %+v, %v, want %s %#x", tt.name, r, err, tt.want, tt.value)
+			continue
+		}
+		if got := FormatArmSysReg(r.Value()); got != tt.want {
+			t.Errorf("FormatArmSysReg(%#x) = %q, want %q", r.Value(), got, tt.want)
+		}
+	}
+
+	for _, name := range []string{"XZR_EL9", "S1_0_C0_C0_0", "S3_0_C16_C0_0"} {
+		if r, err := LookupArmSysReg(name); err == nil {
+			t.Errorf("LookupArmSysReg(%q) = %+v, want error", name, r)
+		}
+	}
+}
+
+func TestCheckArmSysRegAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		read bool
+		el   int
+		ok   bool
+	}{
+		{"TPIDR_EL0", false, 0, true},
+		{"CTR_EL0", true, 0, true},
+		{"CTR_EL0", false, 1, false},
+		{"OSLAR_EL1", true, 1, false},
+		{"SCTLR_EL1", true, 0, false},
+		{"SCTLR_EL1", false, 1, true},
+		// the registers read-only at EL0
+		{"TPIDRRO_EL0", true, 0, true},
+		{"TPIDRRO_EL0", false, 0, false},
+		{"TPIDRRO_EL0", false, 1, true},
+		{"PMUSERENR_EL0", false, 0, false},
+		{"PMUSERENR_EL0", false, 1, true},
+		{"CNTFRQ_EL0", true, 0, true},
+		{"CNTFRQ_EL0", false, 1, false},
+		{"CNTFRQ_EL0", false, 3, true},
+	}
+	for _, tt := range tests {
+		if err := CheckArmSysRegAccess(tt.name, tt.read, tt.el); (err == nil) != tt.ok {
+			t.Errorf("CheckArmSysRegAccess(%q, %t, %d) = %v, want ok %t", tt.name, tt.read, tt.el, err, tt.ok)
+		}
+	}
+}
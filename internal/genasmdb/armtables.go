@@ -0,0 +1,621 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "go", "", "write the Go source of the ARM encoding tables to `file`", &emitterFunc{name: "arm-tables", fn: func(m *Model, w io.Writer) error {
+		return writeArmTables(w, m.Arm, m.ArmInstructions, newArmTablesOptions(m))
+	}})
+}
+
+// armTablesHeader is the header of the generated Go tables, the declarations of the table types.
+//
+// The strings of the tables are the references into the single string table, and the fields and the operands
+// of the encodings are the ranges of the field and the operand table of their extension, so the tables have
+// no string and slice headers to relocate and initialize.
+const armTablesHeader = `// Code generated by genasmdb%s. DO NOT EDIT.
+
+package %s
+
+import "math/bits"
+
+// Arch is the ARM instruction set.
+type Arch uint8
+
+const (
+	T16 Arch = iota + 1
+	T32
+	A32
+)
+
+// SourceVersion returns the asmdb data the tables are generated from, the data file with its SHA-256 checksum
+// followed by the upstream asmdb commit and its date if known, like
+// "asmdb/armdata.js (sha256:...) asmjit/asmdb 0123abcd... 2021-01-02T03:04:05Z".
+func SourceVersion() string {
+	return %q
+}
+
+// str is the reference to the string of strtab, the offset in the upper 24 bits and the length in the lower 8 bits.
+type str uint32
+
+// String returns the referenced string.
+func (s str) String() string {
+	off := s >> 8
+	return strtab[off : off+s&0xff]
+}
+
+// Field is a part of the opcode field placed in the instruction word.
+//
+// The bits Hi..Lo of the instruction word are the bits Shift..Shift+Hi-Lo of the field value.
+type Field struct {
+	name   str
+	Hi, Lo uint8
+	Shift  uint8
+}
+
+// Name returns the name of the opcode field.
+func (f *Field) Name() string {
+	return f.name.String()
+}
+
+// OperandType is the type of the operand.
+type OperandType uint8
+
+const (
+	Reg     OperandType = iota + 1 // register, like "Rd"
+	RegList                        // register list, like "RdList"
+	Mem                            // memory, like "[Rn, #+/-ImmZ]"
+	Imm                            // immediate, like "#ImmZ"
+	Rel                            // PC relative offset, like "#RelS*2"
+	Shift                          // shifted or extended register, like "LSL #Shift"
+	Cond                           // condition code, like "#FirstCond"
+)
+
+// OperandFlags is the set of the properties of the operand.
+type OperandFlags uint8
+
+const (
+	Optional     OperandFlags = 1 << iota // optional, "{op}"
+	Sign                                  // added or subtracted, "+/-"
+	Negative                              // subtracted, "-"
+	Extend                                // register extend, like "UXTW"
+	Writeback                             // memory operand written back, "!"
+	OptWriteback                          // memory operand optionally written back, "{!}"
+	HasRange                              // Min and Max are the range of the value
+)
+
+// Operand is the parsed instruction operand.
+//
+// The memory operand is followed by its elements in the operand table, the base register, the offset and the shift,
+// and the shift operand is followed by its amount. Parts is the number of the following operands which are the parts.
+type Operand struct {
+	field    str
+	Type     OperandType
+	Class    byte  // register class, like 'r' and 'd', or 0
+	Scale    uint8 // multiplier of the encoded immediate
+	Flags    OperandFlags
+	Parts    uint8
+	Min, Max int64 // range of the immediate value, including the scale, if Flags has HasRange
+}
+
+// Field returns the opcode field of the operand, like "Rd" and "ImmZ", the literal value, like "0",
+// or the shift operation, like "LSL".
+func (o *Operand) Field() string {
+	return o.field.String()
+}
+
+// Encoding is the instruction encoding, the instruction word w matches the encoding if w&Mask == Value.
+type Encoding struct {
+	name     str
+	operands str
+	Arch     Arch
+	Width    uint8
+	Mask     uint32
+	Value    uint32
+	tab      uint8  // index of the table of the extension in tables
+	fields   uint16 // index of the first field in the field table
+	nfields  uint8
+	args     uint16 // index of the first operand in the operand table
+	nargs    uint8
+}
+
+// Name returns the instruction name.
+func (e *Encoding) Name() string {
+	return e.name.String()
+}
+
+// Operands returns the instruction operands as written in the asmdb data.
+// It's empty if the tables were generated without the raw operands.
+func (e *Encoding) Operands() string {
+	return e.operands.String()
+}
+
+// Extension returns the CPU extension the instruction requires, like "ASIMD", or empty.
+func (e *Encoding) Extension() string {
+	return tables[e.tab].ext
+}
+
+// Fields returns the opcode fields placed in the instruction word.
+func (e *Encoding) Fields() []Field {
+	return tables[e.tab].fields[e.fields : int(e.fields)+int(e.nfields)]
+}
+
+// Args returns the parsed instruction operands, including the parts of the memory and the shift operands.
+func (e *Encoding) Args() []Operand {
+	return tables[e.tab].ops[e.args : int(e.args)+int(e.nargs)]
+}
+
+// Match reports whether the instruction word matches the encoding.
+func (e *Encoding) Match(w uint32) bool {
+	return w&e.Mask == e.Value
+}
+
+// Field extracts the value of the named field from the instruction word.
+func (e *Encoding) Field(w uint32, name string) uint32 {
+	var v uint32
+	for _, f := range e.Fields() {
+		if f.name.String() == name {
+			v |= (w >> f.Lo & (1<<(f.Hi-f.Lo+1) - 1)) << f.Shift
+		}
+	}
+	return v
+}
+
+// SetField places the value of the named field into the instruction word.
+func (e *Encoding) SetField(w uint32, name string, v uint32) uint32 {
+	for _, f := range e.Fields() {
+		if f.name.String() == name {
+			mask := uint32(1<<(f.Hi-f.Lo+1)-1) << f.Lo
+			w = w&^mask | (v>>f.Shift<<f.Lo)&mask
+		}
+	}
+	return w
+}
+
+// table is the encodings of an extension with their field and operand tables.
+//
+// The encodings of each instruction set are sorted by the number of the fixed bits in descending order,
+// so the first matching encoding of the table is its most specific one.
+type table struct {
+	ext    string
+	encs   []Encoding
+	fields []Field
+	ops    []Operand
+}
+
+// tables is the list of the tables of each extension, set by the init of the file of the extension.
+// The tables of the extensions excluded by the build tags are empty.
+var tables [%d]table
+
+// Range calls fn for each encoding of the extensions included by the build tags until fn returns false.
+func Range(fn func(e *Encoding) bool) {
+	for t := range tables {
+		encs := tables[t].encs
+		for i := range encs {
+			if !fn(&encs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Decode returns the most specific encoding of the instruction set arch matching the instruction word, or nil.
+func Decode(arch Arch, w uint32) *Encoding {
+	var match *Encoding
+	for t := range tables {
+		encs := tables[t].encs
+		for i := range encs {
+			if e := &encs[i]; e.Arch == arch && e.Match(w) {
+				if match == nil || bits.OnesCount32(e.Mask) > bits.OnesCount32(match.Mask) {
+					match = e
+				}
+				break
+			}
+		}
+	}
+	return match
+}
+`
+
+// armStrtab is the string table of the generated Go tables.
+type armStrtab struct {
+	buf  strings.Builder
+	refs map[string]uint32
+}
+
+// add adds s to the string table and returns its reference, the offset in the upper 24 bits and the length in the lower 8 bits.
+// The string already in the table, or a part of it, is shared.
+func (t *armStrtab) add(s string) (uint32, error) {
+	if len(s) > 0xff {
+		return 0, fmt.Errorf("string %q is too long for the string table", s)
+	}
+	if ref, ok := t.refs[s]; ok {
+		return ref, nil
+	}
+	off := strings.Index(t.buf.String(), s)
+	if off < 0 {
+		off = t.buf.Len()
+		t.buf.WriteString(s)
+	}
+	if off > 0xffffff {
+		return 0, fmt.Errorf("string table is too large")
+	}
+	ref := uint32(off)<<8 | uint32(len(s))
+	t.refs[s] = ref
+
+	return ref, nil
+}
+
+// armTableEntry is the encoding of the generated Go tables.
+type armTableEntry struct {
+	inst *ArmInstruction
+	enc  *ArmEncoding
+	ops  []*ArmOperand // flattened in the order of the operand table
+	ext  string        // required CPU extension, or empty
+}
+
+// armTableEntries returns the encodings of insts grouped by the extension in the order of exts,
+// the encodings requiring no extension first.
+//
+// The encodings of each instruction set are sorted by the number of the fixed bits in descending order,
+// so the first matching encoding is the most specific one.
+func armTableEntries(arm *Arm, insts []ArmInstruction) (exts []string, groups map[string][]armTableEntry, err error) {
+	exts = []string{""}
+	groups = map[string][]armTableEntry{"": nil}
+	for _, arch := range []string{ArmT16, ArmT32, ArmA32} {
+		var entries []armTableEntry
+		for _, inst := range armInstructionsOf(insts, arch) {
+			inst := inst
+			enc, err := inst.ParseEncoding()
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+			}
+			ops, err := inst.ParseOperands()
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+			}
+			meta := arm.ParseMetadata(inst.Metadata)
+			if len(meta.Extensions) > 1 {
+				return nil, nil, fmt.Errorf("%s %s: more than one extension %v", inst.Name, inst.Operands, meta.Extensions)
+			}
+			entry := armTableEntry{inst: &inst, enc: enc, ops: armTableOperands(ops)}
+			if len(meta.Extensions) == 1 {
+				entry.ext = meta.Extensions[0]
+			}
+			entries = append(entries, entry)
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return bits.OnesCount32(entries[i].enc.Mask) > bits.OnesCount32(entries[j].enc.Mask)
+		})
+		for _, e := range entries {
+			if _, ok := groups[e.ext]; !ok {
+				exts = append(exts, e.ext)
+			}
+			groups[e.ext] = append(groups[e.ext], e)
+		}
+	}
+	sort.Strings(exts) // the empty extension first
+
+	return exts, groups, nil
+}
+
+// armTableOperands returns the operands flattened in the order of the operand table, each operand followed by its parts.
+func armTableOperands(ops []*ArmOperand) []*ArmOperand {
+	var flat []*ArmOperand
+	for _, op := range ops {
+		flat = append(flat, op)
+		parts := op.Mem
+		if op.Amount != nil {
+			parts = []*ArmOperand{op.Amount}
+		}
+		flat = append(flat, armTableOperands(parts)...)
+	}
+	return flat
+}
+
+// armTableOperandParts returns the number of the parts of op in the operand table.
+func armTableOperandParts(op *ArmOperand) int {
+	parts := op.Mem
+	if op.Amount != nil {
+		parts = []*ArmOperand{op.Amount}
+	}
+	return len(armTableOperands(parts))
+}
+
+// armTableOperandFlags returns the flags of op as the Go expression of the OperandFlags, or empty if op has none.
+func armTableOperandFlags(op *ArmOperand) string {
+	return strings.Join(armTableOperandFlagNames(op), " | ")
+}
+
+// armTableOperandFlagNames returns the names of the OperandFlags constants of the flags of op in the order of their bits.
+func armTableOperandFlagNames(op *ArmOperand) []string {
+	var flags []string
+	for _, f := range []struct {
+		ok   bool
+		name string
+	}{
+		{op.Optional, "Optional"},
+		{op.Sign, "Sign"},
+		{op.Negative, "Negative"},
+		{op.Extend, "Extend"},
+		{op.Writeback == "!", "Writeback"},
+		{op.Writeback == "{!}", "OptWriteback"},
+		{op.Range != nil, "HasRange"},
+	} {
+		if f.ok {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
+// armTableOperandTypes maps the operand types to the OperandType constants of the generated tables.
+var armTableOperandTypes = map[ArmOperandType]string{
+	ArmOperandReg:     "Reg",
+	ArmOperandRegList: "RegList",
+	ArmOperandMem:     "Mem",
+	ArmOperandImm:     "Imm",
+	ArmOperandRel:     "Rel",
+	ArmOperandShift:   "Shift",
+	ArmOperandCond:    "Cond",
+}
+
+// newArmStrtab returns the string table of the strings of the groups of entries.
+// The raw operand strings are added if raw is true.
+func newArmStrtab(groups map[string][]armTableEntry, raw bool) (*armStrtab, error) {
+	var strs []string
+	for _, entries := range groups {
+		for _, e := range entries {
+			strs = append(strs, e.inst.Name)
+			if raw {
+				strs = append(strs, armCompactOperands(e.inst.Operands))
+			}
+			for _, f := range e.enc.Fields {
+				strs = append(strs, f.Name)
+			}
+			for _, op := range e.ops {
+				strs = append(strs, op.Field)
+			}
+		}
+	}
+
+	// the longest strings are added first, so the shorter ones are more likely shared as their parts
+	sort.Strings(strs)
+	sort.SliceStable(strs, func(i, j int) bool {
+		return len(strs[i]) > len(strs[j])
+	})
+	strtab := &armStrtab{refs: make(map[string]uint32)}
+	for _, s := range strs {
+		if _, err := strtab.add(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return strtab, nil
+}
+
+// writeArmStrtab writes the Go source of the string table to buf.
+func writeArmStrtab(buf *bytes.Buffer, strtab *armStrtab) {
+	buf.WriteString("\n// strtab is the string table of the encodings, the opcode fields and the operands.\nconst strtab = \"\"")
+	if strtab.buf.Len() > 0 {
+		// the empty string table of the reduced -arm-data has no continuation
+		buf.WriteString(" +")
+	}
+	buf.WriteString("\n")
+	for s := strtab.buf.String(); s != ""; {
+		n := 96
+		if n > len(s) {
+			n = len(s)
+		}
+		fmt.Fprintf(buf, "\t%q", s[:n])
+		if s = s[n:]; s != "" {
+			buf.WriteString(" +")
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// armTableName returns the Go identifier prefix of the table of the extension, like "asimd" and "base" for no extension.
+func armTableName(ext string) string {
+	if ext == "" {
+		return "base"
+	}
+	return strings.ToLower(ext)
+}
+
+// writeArmTable writes the Go source of the table of the extension ext, the tab-th table, to buf.
+func writeArmTable(buf *bytes.Buffer, tab int, ext string, entries []armTableEntry, strtab *armStrtab, raw bool) error {
+	var encs, fields, args bytes.Buffer
+	nfields, nargs := 0, 0
+	for _, e := range entries {
+		if nfields+len(e.enc.Fields) > 0xffff || len(e.enc.Fields) > 0xff {
+			return fmt.Errorf("%s %s: too many fields for the field table", e.inst.Name, e.inst.Operands)
+		}
+		if nargs+len(e.ops) > 0xffff || len(e.ops) > 0xff {
+			return fmt.Errorf("%s %s: too many operands for the operand table", e.inst.Name, e.inst.Operands)
+		}
+		fmt.Fprintf(&encs, "\t{name: %#x", strtab.refs[e.inst.Name])
+		if raw {
+			fmt.Fprintf(&encs, ", operands: %#x", strtab.refs[armCompactOperands(e.inst.Operands)])
+		}
+		fmt.Fprintf(&encs, ", Arch: %s, Width: %d, Mask: %#08x, Value: %#08x", e.inst.Arch, e.enc.Width, e.enc.Mask, e.enc.Value)
+		if tab > 0 {
+			fmt.Fprintf(&encs, ", tab: %d", tab)
+		}
+		if len(e.enc.Fields) > 0 {
+			fmt.Fprintf(&encs, ", fields: %d, nfields: %d", nfields, len(e.enc.Fields))
+		}
+		if len(e.ops) > 0 {
+			fmt.Fprintf(&encs, ", args: %d, nargs: %d", nargs, len(e.ops))
+		}
+		encs.WriteString("},\n")
+		for _, f := range e.enc.Fields {
+			fmt.Fprintf(&fields, "\t{%#x, %d, %d, %d}, // %s\n", strtab.refs[f.Name], f.Hi, f.Lo, f.Shift, f.Name)
+		}
+		for _, op := range e.ops {
+			fmt.Fprintf(&args, "\t{field: %#x, Type: %s, Scale: %d", strtab.refs[op.Field], armTableOperandTypes[op.Type], op.Scale)
+			if op.Class != "" {
+				fmt.Fprintf(&args, ", Class: '%s'", op.Class)
+			}
+			if flags := armTableOperandFlags(op); flags != "" {
+				fmt.Fprintf(&args, ", Flags: %s", flags)
+			}
+			if parts := armTableOperandParts(op); parts > 0 {
+				fmt.Fprintf(&args, ", Parts: %d", parts)
+			}
+			if op.Range != nil {
+				fmt.Fprintf(&args, ", Min: %d, Max: %d", op.Range.Min, op.Range.Max)
+			}
+			fmt.Fprintf(&args, "}, // %s\n", op.Data)
+		}
+		nfields += len(e.enc.Fields)
+		nargs += len(e.ops)
+	}
+
+	name := armTableName(ext)
+	desc := "requiring no extension"
+	if ext != "" {
+		desc = "requiring the " + ext + " extension"
+	}
+	fmt.Fprintf(buf, "\nfunc init() {\n\ttables[%d] = table{ext: %q, encs: %sEncodings[:], fields: %sFields[:], ops: %sOps[:]}\n}\n",
+		tab, ext, name, name, name)
+	fmt.Fprintf(buf, "\n// %sEncodings is the list of the instruction encodings %s.\nvar %sEncodings = [...]Encoding{\n", name, desc, name)
+	buf.Write(encs.Bytes())
+	fmt.Fprintf(buf, "}\n\n// %sFields is the list of the opcode fields of %sEncodings.\nvar %sFields = [...]Field{\n", name, name, name)
+	buf.Write(fields.Bytes())
+	fmt.Fprintf(buf, "}\n\n// %sOps is the list of the parsed operands of %sEncodings.\nvar %sOps = [...]Operand{\n", name, name, name)
+	buf.Write(args.Bytes())
+	buf.WriteString("}\n")
+
+	return nil
+}
+
+// armTablesOptions represents the options of the generated Go tables.
+type armTablesOptions struct {
+	pkg string // package name
+
+	// raw reports whether the raw operand strings are kept in the tables, the parsed operands always are.
+	raw bool
+
+	// source is the asmdb data the tables are generated from, like "asmdb/armdata.js (sha256:...)", or empty.
+	source string
+
+	// version is the upstream asmdb commit of the source data, like "asmjit/asmdb 0123abcd... 2021-01-02T03:04:05Z", or empty.
+	version string
+}
+
+// newArmTablesOptions returns the options of the generated Go tables of m set by the flags.
+func newArmTablesOptions(m *Model) *armTablesOptions {
+	return &armTablesOptions{pkg: *flagArmTablesPkg, raw: *flagArmTablesRaw, source: m.Source, version: m.Version}
+}
+
+// generatedBy returns the part of the generated code comment after "genasmdb", the source data if any.
+func (opts *armTablesOptions) generatedBy() string {
+	if opts.source == "" {
+		return ""
+	}
+	return " from " + opts.source
+}
+
+// sourceVersion returns the value of the generated SourceVersion, the source data followed by its upstream commit if any.
+func (opts *armTablesOptions) sourceVersion() string {
+	return strings.TrimSpace(opts.source + " " + opts.version)
+}
+
+// writeArmGoSource formats the Go source src and writes it to w.
+func writeArmGoSource(w io.Writer, src []byte) error {
+	src, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("format arm tables: %w", err)
+	}
+	_, err = w.Write(src)
+
+	return err
+}
+
+// writeArmTables writes the Go source of the encoding tables of insts to w.
+func writeArmTables(w io.Writer, arm *Arm, insts []ArmInstruction, opts *armTablesOptions) error {
+	exts, groups, err := armTableEntries(arm, insts)
+	if err != nil {
+		return err
+	}
+	strtab, err := newArmStrtab(groups, opts.raw)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, armTablesHeader, opts.generatedBy(), opts.pkg, opts.sourceVersion(), len(exts))
+	for i, ext := range exts {
+		if err := writeArmTable(&buf, i, ext, groups[ext], strtab, opts.raw); err != nil {
+			return err
+		}
+	}
+	writeArmStrtab(&buf, strtab)
+
+	return writeArmGoSource(w, buf.Bytes())
+}
+
+// writeArmTablesDir writes the Go source of the encoding tables of insts into the directory dir, split into the file
+// of each extension, like "asimd.go", and "tables.go" of the declarations and the encodings requiring no extension.
+//
+// The file of each extension is excluded by the build tag "arm_no_" followed by the lower case extension, like "arm_no_asimd",
+// so the tables of the unneeded extensions are neither compiled nor linked. The string table is shared by all extensions.
+func writeArmTablesDir(dir string, arm *Arm, insts []ArmInstruction, opts *armTablesOptions) error {
+	exts, groups, err := armTableEntries(arm, insts)
+	if err != nil {
+		return err
+	}
+	strtab, err := newArmStrtab(groups, opts.raw)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	for i, ext := range exts {
+		var buf bytes.Buffer
+		name := "tables.go"
+		if ext == "" {
+			fmt.Fprintf(&buf, armTablesHeader, opts.generatedBy(), opts.pkg, opts.sourceVersion(), len(exts))
+		} else {
+			name = armTableName(ext) + ".go"
+			tag := "arm_no_" + armTableName(ext)
+			fmt.Fprintf(&buf, "// Code generated by genasmdb%s. DO NOT EDIT.\n\n//go:build !%s\n// +build !%s\n\npackage %s\n",
+				opts.generatedBy(), tag, tag, opts.pkg)
+		}
+		if err := writeArmTable(&buf, i, ext, groups[ext], strtab, opts.raw); err != nil {
+			return err
+		}
+		if ext == "" {
+			writeArmStrtab(&buf, strtab)
+		}
+
+		if err := writeFile(filepath.Join(dir, name), func(w io.Writer) error {
+			return writeArmGoSource(w, buf.Bytes())
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// armCompactOperands returns the operands without the alignment spaces, like "Rd, Rn" for "Rd    , Rn".
+func armCompactOperands(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), " ,", ",")
+}
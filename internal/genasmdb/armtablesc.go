@@ -0,0 +1,183 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	registerEmitter("arm", "c", "arm_tables.h", "write the C header of the ARM encoding tables to `file`", &emitterFunc{name: "arm-tables-c", fn: func(m *Model, w io.Writer) error {
+		return writeArmTablesC(w, m.Arm, m.ArmInstructions, newArmTablesOptions(m))
+	}})
+}
+
+// armTablesCHeader is the header of the generated C tables, the declarations of the table types.
+//
+// The types mirror the ones of the generated Go tables. The fields and the operands of each encoding are
+// the ranges of arm_fields and arm_operands, and the encodings are in the order of the Go tables.
+const armTablesCHeader = `// Code generated by genasmdb%s. DO NOT EDIT.
+
+#ifndef ARM_TABLES_H
+#define ARM_TABLES_H
+
+#include <stdint.h>
+
+// ARM_SOURCE_VERSION is the asmdb data the tables are generated from, the data file with its SHA-256 checksum
+// followed by the upstream asmdb commit and its date if known.
+#define ARM_SOURCE_VERSION %s
+
+// arm_arch is the ARM instruction set.
+enum arm_arch {
+	ARM_T16 = 1,
+	ARM_T32,
+	ARM_A32,
+};
+
+// arm_operand_type is the type of the operand.
+enum arm_operand_type {
+	ARM_REG = 1,  // register, like "Rd"
+	ARM_REG_LIST, // register list, like "RdList"
+	ARM_MEM,      // memory, like "[Rn, #+/-ImmZ]"
+	ARM_IMM,      // immediate, like "#ImmZ"
+	ARM_REL,      // PC relative offset, like "#RelS*2"
+	ARM_SHIFT,    // shifted or extended register, like "LSL #Shift"
+	ARM_COND,     // condition code, like "#FirstCond"
+};
+
+// arm_operand_flags is the set of the properties of the operand.
+enum arm_operand_flags {
+	ARM_OPTIONAL = 1 << 0,      // optional, "{op}"
+	ARM_SIGN = 1 << 1,          // added or subtracted, "+/-"
+	ARM_NEGATIVE = 1 << 2,      // subtracted, "-"
+	ARM_EXTEND = 1 << 3,        // register extend, like "UXTW"
+	ARM_WRITEBACK = 1 << 4,     // memory operand written back, "!"
+	ARM_OPT_WRITEBACK = 1 << 5, // memory operand optionally written back, "{!}"
+	ARM_HAS_RANGE = 1 << 6,     // min and max are the range of the value
+};
+
+// arm_field is a part of the opcode field placed in the instruction word.
+//
+// The bits hi..lo of the instruction word are the bits shift..shift+hi-lo of the field value.
+struct arm_field {
+	const char *name;
+	uint8_t hi, lo;
+	uint8_t shift;
+};
+
+// arm_operand is the parsed instruction operand.
+//
+// The memory operand is followed by its elements, the base register, the offset and the shift,
+// and the shift operand is followed by its amount. parts is the number of the following operands which are the parts.
+struct arm_operand {
+	const char *field; // opcode field, like "Rd", the literal value, like "0", or the shift operation, like "LSL"
+	uint8_t type;      // arm_operand_type
+	char cls;          // register class, like 'r' and 'd', or 0
+	uint8_t scale;     // multiplier of the encoded immediate
+	uint8_t flags;     // arm_operand_flags
+	uint8_t parts;
+	int64_t min, max; // range of the immediate value, including the scale, if flags has ARM_HAS_RANGE
+};
+
+// arm_encoding is the instruction encoding, the instruction word w matches the encoding if (w & mask) == value.
+struct arm_encoding {
+	const char *name;
+	const char *operands; // operands as written in the asmdb data, or "" without the raw operands
+	const char *ext;      // CPU extension the instruction requires, like "ASIMD", or ""
+	uint8_t arch;         // arm_arch
+	uint8_t width;
+	uint32_t mask, value;
+	uint16_t fields; // index of the first field in arm_fields
+	uint8_t nfields;
+	uint16_t args; // index of the first operand in arm_operands
+	uint8_t nargs;
+};
+`
+
+// cIdent returns the upper snake case C identifier of the Go identifier with the prefix, like "ARM_OPT_WRITEBACK" for "OptWriteback".
+func cIdent(prefix, name string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// armTablesCFlags returns the flags of op as the C expression of arm_operand_flags, or 0 if op has none.
+func armTablesCFlags(op *ArmOperand) string {
+	names := armTableOperandFlagNames(op)
+	if len(names) == 0 {
+		return "0"
+	}
+	for i, name := range names {
+		names[i] = cIdent("ARM_", name)
+	}
+	return strings.Join(names, " | ")
+}
+
+// writeArmTablesC writes the C header of the encoding tables of insts to w.
+func writeArmTablesC(w io.Writer, arm *Arm, insts []ArmInstruction, opts *armTablesOptions) error {
+	exts, groups, err := armTableEntries(arm, insts)
+	if err != nil {
+		return err
+	}
+
+	var encs, fields, args bytes.Buffer
+	nfields, nargs := 0, 0
+	for _, ext := range exts {
+		for _, e := range groups[ext] {
+			if nfields+len(e.enc.Fields) > 0xffff || nargs+len(e.ops) > 0xffff {
+				return fmt.Errorf("%s %s: too many fields or operands for the C tables", e.inst.Name, e.inst.Operands)
+			}
+			operands := ""
+			if opts.raw {
+				operands = armCompactOperands(e.inst.Operands)
+			}
+			fmt.Fprintf(&encs, "\t{%s, %s, %s, %s, %d, %#08x, %#08x, %d, %d, %d, %d},\n",
+				strconv.Quote(e.inst.Name), strconv.Quote(operands), strconv.Quote(ext), cIdent("ARM_", e.inst.Arch),
+				e.enc.Width, e.enc.Mask, e.enc.Value, nfields, len(e.enc.Fields), nargs, len(e.ops))
+			for _, f := range e.enc.Fields {
+				fmt.Fprintf(&fields, "\t{%s, %d, %d, %d},\n", strconv.Quote(f.Name), f.Hi, f.Lo, f.Shift)
+			}
+			for _, op := range e.ops {
+				class := "0"
+				if op.Class != "" {
+					class = "'" + op.Class + "'"
+				}
+				var min, max int64
+				if op.Range != nil {
+					min, max = op.Range.Min, op.Range.Max
+				}
+				fmt.Fprintf(&args, "\t{%s, %s, %s, %d, %s, %d, %d, %d}, // %s\n", strconv.Quote(op.Field),
+					cIdent("ARM_", armTableOperandTypes[op.Type]), class, op.Scale, armTablesCFlags(op), armTableOperandParts(op), min, max, op.Data)
+			}
+			nfields += len(e.enc.Fields)
+			nargs += len(e.ops)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, armTablesCHeader, opts.generatedBy(), strconv.Quote(opts.sourceVersion()))
+	buf.WriteString("\n// arm_fields is the list of the opcode fields of arm_encodings.\nstatic const struct arm_field arm_fields[] = {\n")
+	buf.Write(fields.Bytes())
+	buf.WriteString("};\n\n// arm_operands is the list of the parsed operands of arm_encodings.\nstatic const struct arm_operand arm_operands[] = {\n")
+	buf.Write(args.Bytes())
+	buf.WriteString("};\n\n// arm_encodings is the list of the instruction encodings, the ones requiring no extension first.\n")
+	buf.WriteString("static const struct arm_encoding arm_encodings[] = {\n")
+	buf.Write(encs.Bytes())
+	buf.WriteString("};\n\n#define ARM_NUM_ENCODINGS (sizeof arm_encodings / sizeof arm_encodings[0])\n\n#endif // ARM_TABLES_H\n")
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
@@ -0,0 +1,185 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "rust", "arm_tables.rs", "write the Rust source of the ARM encoding tables to `file`", &emitterFunc{name: "arm-tables-rust", fn: func(m *Model, w io.Writer) error {
+		return writeArmTablesRust(w, m.Arm, m.ArmInstructions, newArmTablesOptions(m))
+	}})
+}
+
+// armTablesRustHeader is the header of the generated Rust tables, the declarations of the table types.
+//
+// The types mirror the ones of the generated Go tables, and the encodings are in the order of the Go tables.
+const armTablesRustHeader = `// Code generated by genasmdb%s. DO NOT EDIT.
+
+/// The asmdb data the tables are generated from, the data file with its SHA-256 checksum
+/// followed by the upstream asmdb commit and its date if known.
+pub const SOURCE_VERSION: &str = %s;
+
+/// The ARM instruction set.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum Arch {
+    T16 = 1,
+    T32,
+    A32,
+}
+
+/// The type of the operand.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum OperandType {
+    /// Register, like "Rd".
+    Reg = 1,
+    /// Register list, like "RdList".
+    RegList,
+    /// Memory, like "[Rn, #+/-ImmZ]".
+    Mem,
+    /// Immediate, like "#ImmZ".
+    Imm,
+    /// PC relative offset, like "#RelS*2".
+    Rel,
+    /// Shifted or extended register, like "LSL #Shift".
+    Shift,
+    /// Condition code, like "#FirstCond".
+    Cond,
+}
+
+/// The set of the properties of the operand.
+pub mod operand_flags {
+    /// Optional, "{op}".
+    pub const OPTIONAL: u8 = 1 << 0;
+    /// Added or subtracted, "+/-".
+    pub const SIGN: u8 = 1 << 1;
+    /// Subtracted, "-".
+    pub const NEGATIVE: u8 = 1 << 2;
+    /// Register extend, like "UXTW".
+    pub const EXTEND: u8 = 1 << 3;
+    /// Memory operand written back, "!".
+    pub const WRITEBACK: u8 = 1 << 4;
+    /// Memory operand optionally written back, "{!}".
+    pub const OPT_WRITEBACK: u8 = 1 << 5;
+    /// min and max are the range of the value.
+    pub const HAS_RANGE: u8 = 1 << 6;
+}
+
+/// A part of the opcode field placed in the instruction word.
+///
+/// The bits hi..lo of the instruction word are the bits shift..shift+hi-lo of the field value.
+#[derive(Clone, Copy, Debug)]
+pub struct Field {
+    pub name: &'static str,
+    pub hi: u8,
+    pub lo: u8,
+    pub shift: u8,
+}
+
+/// The parsed instruction operand.
+///
+/// The memory operand is followed by its elements, the base register, the offset and the shift,
+/// and the shift operand is followed by its amount. parts is the number of the following operands which are the parts.
+#[derive(Clone, Copy, Debug)]
+pub struct Operand {
+    /// The opcode field, like "Rd", the literal value, like "0", or the shift operation, like "LSL".
+    pub field: &'static str,
+    pub typ: OperandType,
+    /// The register class, like b'r' and b'd', or 0.
+    pub class: u8,
+    /// The multiplier of the encoded immediate.
+    pub scale: u8,
+    pub flags: u8,
+    pub parts: u8,
+    /// The range of the immediate value, including the scale, if flags has HAS_RANGE.
+    pub min: i64,
+    pub max: i64,
+}
+
+/// The instruction encoding, the instruction word w matches the encoding if w & mask == value.
+#[derive(Clone, Copy, Debug)]
+pub struct Encoding {
+    pub name: &'static str,
+    /// The operands as written in the asmdb data, or empty without the raw operands.
+    pub operands: &'static str,
+    /// The CPU extension the instruction requires, like "ASIMD", or empty.
+    pub ext: &'static str,
+    pub arch: Arch,
+    pub width: u8,
+    pub mask: u32,
+    pub value: u32,
+    pub fields: &'static [Field],
+    pub args: &'static [Operand],
+}
+
+impl Encoding {
+    /// Reports whether the instruction word matches the encoding.
+    pub fn matches(&self, w: u32) -> bool {
+        w & self.mask == self.value
+    }
+}
+`
+
+// armTablesRustFlags returns the flags of op as the Rust expression of the operand_flags, or 0 if op has none.
+func armTablesRustFlags(op *ArmOperand) string {
+	names := armTableOperandFlagNames(op)
+	if len(names) == 0 {
+		return "0"
+	}
+	for i, name := range names {
+		names[i] = "operand_flags::" + cIdent("", name)
+	}
+	return strings.Join(names, " | ")
+}
+
+// writeArmTablesRust writes the Rust source of the encoding tables of insts to w.
+func writeArmTablesRust(w io.Writer, arm *Arm, insts []ArmInstruction, opts *armTablesOptions) error {
+	exts, groups, err := armTableEntries(arm, insts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, armTablesRustHeader, opts.generatedBy(), strconv.Quote(opts.sourceVersion()))
+	buf.WriteString("\n/// The instruction encodings, the ones requiring no extension first.\npub static ENCODINGS: &[Encoding] = &[\n")
+	for _, ext := range exts {
+		for _, e := range groups[ext] {
+			operands := ""
+			if opts.raw {
+				operands = armCompactOperands(e.inst.Operands)
+			}
+			fmt.Fprintf(&buf, "    Encoding {\n        name: %s,\n        operands: %s,\n        ext: %s,\n        arch: Arch::%s,\n",
+				strconv.Quote(e.inst.Name), strconv.Quote(operands), strconv.Quote(ext), e.inst.Arch)
+			fmt.Fprintf(&buf, "        width: %d,\n        mask: %#08x,\n        value: %#08x,\n", e.enc.Width, e.enc.Mask, e.enc.Value)
+			buf.WriteString("        fields: &[\n")
+			for _, f := range e.enc.Fields {
+				fmt.Fprintf(&buf, "            Field { name: %s, hi: %d, lo: %d, shift: %d },\n", strconv.Quote(f.Name), f.Hi, f.Lo, f.Shift)
+			}
+			buf.WriteString("        ],\n        args: &[\n")
+			for _, op := range e.ops {
+				class := "0"
+				if op.Class != "" {
+					class = "b'" + op.Class + "'"
+				}
+				var min, max int64
+				if op.Range != nil {
+					min, max = op.Range.Min, op.Range.Max
+				}
+				fmt.Fprintf(&buf, "            // %s\n            Operand { field: %s, typ: OperandType::%s, class: %s, scale: %d, flags: %s, parts: %d, min: %d, max: %d },\n",
+					op.Data, strconv.Quote(op.Field), armTableOperandTypes[op.Type], class, op.Scale, armTablesRustFlags(op), armTableOperandParts(op), min, max)
+			}
+			buf.WriteString("        ],\n    },\n")
+		}
+	}
+	buf.WriteString("];\n")
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
@@ -0,0 +1,298 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	registerEmitter("arm", "json", "thumb.json", "write the Thumb T16/T32 forms with the IT block constraints JSON to `file`", &emitterFunc{name: "arm-thumb", fn: func(m *Model, w io.Writer) error {
+		return writeArmThumb(w, m.ArmInstructions)
+	}, out: ArmThumb{}})
+}
+
+// ArmITState is the position of the instruction relative to the IT block.
+type ArmITState int
+
+const (
+	// ArmITOutside is outside of any IT block.
+	ArmITOutside ArmITState = iota
+	// ArmITInside is inside of the IT block, but not the last instruction of it.
+	ArmITInside
+	// ArmITLast is the last instruction of the IT block.
+	ArmITLast
+)
+
+// String returns the name of the IT state.
+func (s ArmITState) String() string {
+	switch s {
+	case ArmITOutside:
+		return "outside"
+	case ArmITInside:
+		return "inside"
+	case ArmITLast:
+		return "last"
+	}
+	return fmt.Sprintf("ArmITState(%d)", int(s))
+}
+
+// ArmIT represents the IT block constraint of the Thumb instruction, the "IT=..." metadata.
+type ArmIT struct {
+	// In reports whether the instruction can be executed inside the IT block ("IN" or "ANY").
+	In bool `json:"in,omitzero"`
+
+	// Out reports whether the instruction can be executed outside the IT block ("OUT" or "ANY").
+	Out bool `json:"out,omitzero"`
+
+	// Last reports whether the instruction can be executed as the last instruction of the IT block ("LAST").
+	Last bool `json:"last,omitzero"`
+
+	// Def reports whether the instruction defines the IT block, the "it" instruction itself ("DEF").
+	Def bool `json:"def,omitzero"`
+
+	// Uncond reports whether the instruction is executed unconditionally even inside the IT block ("UNCOND").
+	Uncond bool `json:"uncond,omitzero"`
+}
+
+// parseArmIT parses the "IT=..." value of the instruction metadata, like "OUT|LAST".
+//
+// The instruction without the "IT=..." metadata can be executed anywhere.
+func parseArmIT(meta string) (ArmIT, error) {
+	var it ArmIT
+	for _, tok := range strings.Fields(meta) {
+		if !strings.HasPrefix(tok, "IT=") {
+			continue
+		}
+		for _, v := range strings.Split(strings.TrimPrefix(tok, "IT="), "|") {
+			switch v {
+			case "ANY":
+				it.In, it.Out = true, true
+			case "IN":
+				it.In = true
+			case "OUT":
+				it.Out = true
+			case "LAST":
+				it.Last = true
+			case "DEF":
+				it.Def = true
+			case "UNCOND":
+				it.In, it.Out, it.Uncond = true, true, true
+			default:
+				return it, fmt.Errorf("invalid IT constraint %q in %q", v, meta)
+			}
+		}
+		return it, nil
+	}
+
+	return ArmIT{In: true, Out: true}, nil
+}
+
+// Allows reports whether the instruction can be executed in the IT state.
+func (it ArmIT) Allows(state ArmITState) bool {
+	switch state {
+	case ArmITOutside:
+		return it.Out
+	case ArmITInside:
+		return it.In
+	case ArmITLast:
+		return it.In || it.Last
+	}
+	return false
+}
+
+// ArmThumbForm represents a Thumb instruction form, either the 16-bit T16 or the 32-bit T32 encoding.
+type ArmThumbForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+	Arch     string `json:"arch"`
+
+	// Width is the encoding width in bits, 16 for the T16 and 32 for the T32 forms.
+	Width int `json:"width"`
+
+	OpCode   string `json:"opcode"`
+	Metadata string `json:"metadata"`
+
+	IT ArmIT `json:"it"`
+
+	// Narrow is the list of the indexes of the T16 forms encoding the same operands as the T32 form.
+	Narrow []int `json:"narrow,omitzero"`
+
+	// Wide is the list of the indexes of the T32 forms encoding the same operands as the T16 form.
+	Wide []int `json:"wide,omitzero"`
+}
+
+// Qualifier returns the width qualifier forcing the form, ".n" for the T16 and ".w" for the T32 forms,
+// or empty if the form has no alternative encoding of the other width.
+func (f *ArmThumbForm) Qualifier() string {
+	switch {
+	case f.Width == 16 && len(f.Wide) > 0:
+		return ".n"
+	case f.Width == 32 && len(f.Narrow) > 0:
+		return ".w"
+	}
+	return ""
+}
+
+// ArmThumbGroup represents the Thumb forms of the same name accepting the same operand shape.
+type ArmThumbGroup struct {
+	Name string `json:"name"`
+
+	// Shape is the operands with the register constraints and the field names removed, like "R, R, #".
+	Shape string `json:"shape"`
+
+	// Forms is the list of the indexes of the forms, the T16 forms first.
+	Forms []int `json:"forms"`
+
+	// Outside, Inside and Last are the indexes of the form selected in each IT state, or -1 if no form is allowed.
+	Outside int `json:"outside"`
+	Inside  int `json:"inside"`
+	Last    int `json:"last"`
+}
+
+// ArmThumb represents the Thumb instruction forms with the narrow and wide encodings.
+type ArmThumb struct {
+	Forms  []*ArmThumbForm  `json:"forms"`
+	Groups []*ArmThumbGroup `json:"groups"`
+}
+
+// armThumbShapeReplacer is a list of the patterns and replacements normalizing the operands into the shape.
+var armThumbShapeReplacer = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(!=|==|<=)\w+`), ""},
+	{regexp.MustCompile(`\*\d+`), ""},
+	{regexp.MustCompile(`#\w+`), "#"},
+	{regexp.MustCompile(`\bR\w*List\b`), "RList"},
+	{regexp.MustCompile(`\bR[a-z]\w*`), "R"},
+	{regexp.MustCompile(`\b(LSL|LSR|ASR|ROR)\b`), "Sop"},
+	{regexp.MustCompile(`\s+`), " "},
+	{regexp.MustCompile(`\s*,\s*`), ", "},
+}
+
+// armThumbShapes returns the operand shapes accepted by the operands.
+//
+// The trailing optional operand, like "{Sop #Shift}" of the T32 forms, can be omitted,
+// so the shape without it is also returned.
+func armThumbShapes(operands string) []string {
+	shape := operands
+	for _, r := range armThumbShapeReplacer {
+		shape = r.re.ReplaceAllString(shape, r.repl)
+	}
+	shape = strings.TrimSpace(shape)
+
+	shapes := []string{shape}
+	if strings.HasSuffix(shape, "}") {
+		if i := strings.LastIndex(shape, ", {"); i >= 0 {
+			shapes = append(shapes, shape[:i])
+		}
+	}
+	return shapes
+}
+
+// armThumbForms returns the Thumb forms of insts and groups the narrow and wide forms of the same operand shape.
+func armThumbForms(insts []ArmInstruction) (*ArmThumb, error) {
+	thumb := new(ArmThumb)
+	groups := make(map[string]*ArmThumbGroup)
+	for _, arch := range []string{ArmT16, ArmT32} {
+		for _, inst := range armInstructionsOf(insts, arch) {
+			it, err := parseArmIT(inst.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+			}
+			width := 32
+			if arch == ArmT16 {
+				width = 16
+			}
+			idx := len(thumb.Forms)
+			thumb.Forms = append(thumb.Forms, &ArmThumbForm{
+				Name:     inst.Name,
+				Operands: inst.Operands,
+				Arch:     inst.Arch,
+				Width:    width,
+				OpCode:   inst.OpCode,
+				Metadata: inst.Metadata,
+				IT:       it,
+			})
+
+			for _, shape := range armThumbShapes(inst.Operands) {
+				key := inst.Name + "\t" + shape
+				g, ok := groups[key]
+				if !ok {
+					g = &ArmThumbGroup{Name: inst.Name, Shape: shape}
+					groups[key] = g
+					thumb.Groups = append(thumb.Groups, g)
+				}
+				g.Forms = append(g.Forms, idx)
+			}
+		}
+	}
+
+	for _, g := range thumb.Groups {
+		for _, i := range g.Forms {
+			for _, j := range g.Forms {
+				fi, fj := thumb.Forms[i], thumb.Forms[j]
+				if fi.Width == 32 && fj.Width == 16 && !containsInt(fi.Narrow, j) {
+					fi.Narrow = append(fi.Narrow, j)
+					fj.Wide = append(fj.Wide, i)
+				}
+			}
+		}
+		g.Outside = armThumbSelect(thumb.Forms, g.Forms, ArmITOutside)
+		g.Inside = armThumbSelect(thumb.Forms, g.Forms, ArmITInside)
+		g.Last = armThumbSelect(thumb.Forms, g.Forms, ArmITLast)
+	}
+
+	return thumb, nil
+}
+
+// armThumbSelect returns the index of the form selected from the candidates in the IT state, or -1 if no form is allowed.
+//
+// The first allowed narrow form is preferred, the wide form is selected only if no narrow form is allowed,
+// like "add r0, r1, r2" outside the IT block that must be encoded as T32 since the T16 form is "IT=IN".
+// The register constraints of the operands, like "Rd!=HI", are not checked.
+func armThumbSelect(forms []*ArmThumbForm, candidates []int, state ArmITState) int {
+	for _, width := range []int{16, 32} {
+		for _, i := range candidates {
+			if forms[i].Width == width && forms[i].IT.Allows(state) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// containsInt reports whether s contains v.
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether s contains v.
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// writeArmThumb writes the Thumb forms of insts as JSON to w.
+func writeArmThumb(w io.Writer, insts []ArmInstruction) error {
+	thumb, err := armThumbForms(insts)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, "thumb forms", thumb)
+}
@@ -0,0 +1,87 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+func init() {
+	registerEmitter("x86", "json", "att.json", "write the AT&T syntax mnemonics and samples JSON to `file`", &emitterFunc{name: "x86-att", fn: func(m *Model, w io.Writer) error {
+		return writeATT(w, m.X86, m.X86Instructions)
+	}, out: []*ATTForm(nil)})
+}
+
+// ATTForm represents the AT&T syntax of the asmdb x86 instruction form for the GNU toolchain.
+type ATTForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+	Encoding string `json:"encoding"`
+	OpCode   string `json:"opcode"`
+
+	// Mnemonics is the list of AT&T mnemonics of every name and every combination of the operand kinds,
+	// like "addb" and "add" for "add r8/m8, ib".
+	Mnemonics []string `json:"mnemonics"`
+
+	// Samples is the list of concrete instructions in the AT&T syntax.
+	Samples []*ATTSample `json:"samples"`
+}
+
+// ATTSample represents a concrete instruction in the AT&T syntax.
+type ATTSample struct {
+	// Mode is the processor mode bits, 32 or 64.
+	Mode int `json:"mode"`
+
+	// Asm is the assembly source in the AT&T syntax.
+	Asm string `json:"asm"`
+}
+
+// writeATT writes the AT&T syntax of insts to w as JSON.
+func writeATT(w io.Writer, x86 *X86, insts []X86Instruction) error {
+	f, err := NewX86Formatter(X86SyntaxATT, insts)
+	if err != nil {
+		return err
+	}
+
+	forms := make([]*ATTForm, len(insts))
+	for i := range insts {
+		inst := &insts[i]
+		ops, err := inst.ParseOperands()
+		if err != nil {
+			return fmt.Errorf("%s: %w", inst.Name, err)
+		}
+
+		form := &ATTForm{
+			Name:     inst.Name,
+			Operands: inst.Operands,
+			Encoding: inst.Encoding,
+			OpCode:   inst.OpCode,
+		}
+		seenMnemonic := make(map[string]bool)
+		seen := make(map[ATTSample]bool)
+		for _, mode := range x86FormModes(x86, inst) {
+			for _, name := range inst.Names() {
+				for _, sample := range x86Samples(name, ops, mode) {
+					if m := f.ATTMnemonic(sample.name, sample.kinds); !seenMnemonic[m] {
+						seenMnemonic[m] = true
+						form.Mnemonics = append(form.Mnemonics, m)
+					}
+					as := ATTSample{
+						Mode: mode,
+						Asm:  f.Format(sample),
+					}
+					if seen[as] {
+						continue
+					}
+					seen[as] = true
+					form.Samples = append(form.Samples, &as)
+				}
+			}
+		}
+		forms[i] = form
+	}
+
+	return writeJSON(w, "att forms", forms)
+}
@@ -0,0 +1,66 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// buildArmTables writes the Go source of the encoding tables of m split per extension into a temporary module,
+// and builds it with go found in PATH, once with all extensions and once with all extensions excluded by their
+// build tags, so the generated code which doesn't compile is caught before it reaches the users of the tables.
+func buildArmTables(ctx context.Context, m *Model) error {
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("build check: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "genasmdb-build")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	opts := newArmTablesOptions(m)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module genasmdb.check\n\ngo 1.16\n"), 0o644); err != nil {
+		return err
+	}
+	if err := writeArmTablesDir(filepath.Join(dir, opts.pkg), m.Arm, m.ArmInstructions, opts); err != nil {
+		return fmt.Errorf("build check: %w", err)
+	}
+
+	exts, _, err := armTableEntries(m.Arm, m.ArmInstructions)
+	if err != nil {
+		return err
+	}
+	var tags []string
+	for _, ext := range exts {
+		if ext != "" {
+			tags = append(tags, "arm_no_"+armTableName(ext))
+		}
+	}
+
+	for _, args := range [][]string{
+		{"build", "./..."},
+		{"build", "-tags", strings.Join(tags, ","), "./..."},
+	} {
+		var out bytes.Buffer
+		cmd := exec.CommandContext(ctx, gocmd, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		cmd.Stdout, cmd.Stderr = &out, &out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("build check: go %s: %w\n%s", strings.Join(args, " "), err, out.Bytes())
+		}
+		addProgress(&progress.Validated, 1)
+	}
+
+	return nil
+}
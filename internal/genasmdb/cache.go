@@ -0,0 +1,97 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// cacheKey returns the content hash of the generation, the hash of the generator executable, the asmdb data,
+// the flag values and the files read by the flags, the template, the config and the patches. The asmdb data read
+// from stdin can't be hashed before the generation.
+//
+// The generation with the same key writes the same outputs, so it's skipped if the key is recorded in the -cache file.
+func cacheKey() (string, error) {
+	h := sha256.New()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("cache key: %w", err)
+	}
+	for _, name := range append([]string{exe, *flagTmpl, *flagConfig}, splitList(*flagPatch)...) {
+		if name == "" {
+			continue
+		}
+		if err := hashFile(h, name); err != nil {
+			return "", fmt.Errorf("cache key: %w", err)
+		}
+	}
+	for _, name := range []string{*flagX86Data, *flagArmData} {
+		if name == "-" {
+			return "", fmt.Errorf("cache key: -cache can't hash the asmdb data read from stdin")
+		}
+	}
+	for _, data := range []struct {
+		path string
+		name string
+	}{
+		{*flagX86Data, asmdbX86DataJS},
+		{*flagArmData, asmdbArmDataJS},
+	} {
+		_, source, err := readData(data.path, data.name)
+		if err != nil {
+			return "", fmt.Errorf("cache key: %w", err)
+		}
+		io.WriteString(h, source)
+	}
+
+	// flag.VisitAll visits the flags in lexicographical order
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name == "cache" || f.Name == "dump" || f.Name == "diff" || f.Name == "v" {
+			return
+		}
+		fmt.Fprintf(h, "-%s=%q\n", f.Name, f.Value.String())
+	})
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// hashFile writes the name file to h.
+func hashFile(h io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// upToDate reports whether the key is recorded in the cache file name, the outputs of the previous generation are up-to-date.
+func upToDate(name, key string) (bool, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read cache: %w", err)
+	}
+	return string(bytes.TrimSpace(data)) == key, nil
+}
+
+// writeCache records the key in the cache file name.
+func writeCache(name, key string) error {
+	return writeFile(name, func(w io.Writer) error {
+		_, err := io.WriteString(w, strings.TrimSpace(key)+"\n")
+		return err
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("capstone", "capstone.json", "write the Capstone instruction mapping of the %s forms JSON to `file`", []*CapstoneForm(nil), x86CapstoneForm, nil)
+}
+
+// CapstoneForm represents a mapping between the asmdb x86 instruction form and Capstone's instruction model.
+//
+// Capstone only lists explicit operands in the cs_x86.operands, so implicit operands are mapped to
+// RegsRead and RegsWrite. Implicit registers which depend on the address size ("zax", "zsi", ...) are mapped
+// to the 64-bit registers as decoded by Capstone's CS_MODE_64.
+type CapstoneForm struct {
+	// ID is the Capstone instruction ID, like "X86_INS_ADC".
+	// Instructions with aliases are mapped to the ID of the first name.
+	ID string `json:"id"`
+
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+	Encoding string `json:"encoding"`
+	OpCode   string `json:"opcode"`
+
+	// OperandTypes is the list of Capstone x86_op_type of each explicit operand.
+	// Operands which accept multiple types are joined by '|', like "X86_OP_REG|X86_OP_MEM".
+	OperandTypes []string `json:"operandTypes,omitzero"`
+
+	// OperandAccess is the list of Capstone cs_ac_type of each explicit operand.
+	OperandAccess []string `json:"operandAccess,omitzero"`
+
+	// RegsRead and RegsWrite are the list of Capstone x86_reg implicitly read and written.
+	RegsRead  []string `json:"regsRead,omitzero"`
+	RegsWrite []string `json:"regsWrite,omitzero"`
+
+	// EFlags is the list of Capstone X86_EFLAGS_* bits.
+	EFlags []string `json:"eflags,omitzero"`
+
+	Architectures []string  `json:"architectures,omitzero"`
+	Extensions    []string  `json:"extensions,omitzero"`
+	Attributes    stringMap `json:"attributes,omitzero"`
+}
+
+// capstoneEFlags maps the asmdb flag access to the Capstone X86_EFLAGS_* prefixes.
+var capstoneEFlags = map[string][]string{
+	"R": {"TEST"},
+	"W": {"MODIFY"},
+	"X": {"TEST", "MODIFY"},
+	"U": {"UNDEFINED"},
+	"0": {"RESET"},
+	"1": {"SET"},
+}
+
+// x86CapstoneForm returns the CapstoneForm of inst.
+func x86CapstoneForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	return newCapstoneForm(x, inst)
+}
+
+// newCapstoneForm returns the CapstoneForm of inst.
+func newCapstoneForm(x86 *X86, inst *X86Instruction) (*CapstoneForm, error) {
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	meta := x86.ParseMetadata(inst.Metadata)
+
+	form := &CapstoneForm{
+		ID:            "X86_INS_" + strings.ToUpper(inst.Names()[0]),
+		Name:          inst.Name,
+		Operands:      inst.Operands,
+		Encoding:      inst.Encoding,
+		OpCode:        inst.OpCode,
+		Architectures: meta.Architectures,
+		Extensions:    meta.Extensions,
+		Attributes:    meta.Attributes,
+	}
+
+	for _, op := range ops {
+		if op.Implicit {
+			if !op.IsReg() {
+				continue
+			}
+			for _, kind := range op.Kinds {
+				reg := capstoneReg(kind)
+				if op.IsRead() {
+					form.RegsRead = append(form.RegsRead, reg)
+				}
+				if op.IsWrite() {
+					form.RegsWrite = append(form.RegsWrite, reg)
+				}
+			}
+			continue
+		}
+		form.OperandTypes = append(form.OperandTypes, capstoneOperandType(op))
+		form.OperandAccess = append(form.OperandAccess, capstoneAccess(op))
+	}
+
+	for reg, access := range meta.SpecialRegs {
+		if !strings.HasPrefix(reg, "FLAGS.") {
+			continue
+		}
+		for _, prefix := range capstoneEFlags[access] {
+			form.EFlags = append(form.EFlags, "X86_EFLAGS_"+prefix+"_"+strings.TrimPrefix(reg, "FLAGS."))
+		}
+	}
+	sort.Strings(form.EFlags)
+
+	return form, nil
+}
+
+// capstoneReg returns the Capstone x86_reg name of the asmdb register name.
+func capstoneReg(name string) string {
+	if len(name) == 3 && name[0] == 'z' {
+		name = "r" + name[1:]
+	}
+	name = strings.NewReplacer("(", "", ")", "").Replace(name)
+
+	return "X86_REG_" + strings.ToUpper(name)
+}
+
+// capstoneOperandType returns the Capstone x86_op_type of op.
+//
+// Capstone decodes relative displacements as immediate operands.
+func capstoneOperandType(op *X86Operand) string {
+	var types []string
+	if op.IsReg() {
+		types = append(types, "X86_OP_REG")
+	}
+	if op.IsMem() {
+		types = append(types, "X86_OP_MEM")
+	}
+	if op.IsImm() || op.IsRel() {
+		types = append(types, "X86_OP_IMM")
+	}
+
+	return strings.Join(types, "|")
+}
+
+// capstoneAccess returns the Capstone cs_ac_type of op.
+func capstoneAccess(op *X86Operand) string {
+	switch read, write := op.IsRead(), op.IsWrite(); {
+	case read && write:
+		return "CS_AC_READ|CS_AC_WRITE"
+	case write:
+		return "CS_AC_WRITE"
+	case read:
+		return "CS_AC_READ"
+	}
+	return "CS_AC_INVALID"
+}
@@ -0,0 +1,136 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// changelogSection is a section of the changelog, the title and its list items.
+type changelogSection struct {
+	title string
+	items []string
+}
+
+// modelExtensions returns the set of the names of the CPU extensions defined by m.
+func modelExtensions(m *Model) map[string]bool {
+	exts := make(map[string]bool)
+	if m.X86 != nil {
+		for _, ext := range m.X86.Extensions {
+			exts[ext.Name] = true
+		}
+	}
+	if m.Arm != nil {
+		for _, ext := range m.Arm.Extensions {
+			exts[ext.Name] = true
+		}
+	}
+	return exts
+}
+
+// modelNames returns the set of the instruction names of m.
+func modelNames(m *Model) map[string]bool {
+	names := make(map[string]bool)
+	for _, inst := range m.X86Instructions {
+		names[inst.Name] = true
+	}
+	for _, inst := range m.ArmInstructions {
+		names[inst.Name] = true
+	}
+	return names
+}
+
+// sortedNames returns the sorted names of the forms of diffs which are missing in the names set.
+func sortedNames(diffs []FormDiff, names map[string]bool) []string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, fd := range diffs {
+		if !names[fd.Key.Name] && !seen[fd.Key.Name] {
+			seen[fd.Key.Name] = true
+			list = append(list, "`"+fd.Key.Name+"`")
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// writeChangelog writes the Markdown changelog fragment of the changes of the instruction forms and the CPU extensions
+// from old to new of the same architecture to w, the new extensions and instructions, the removed instructions,
+// the added and the removed forms of the existing instructions, the encoding fixes and the other changes.
+//
+// Nothing is written if there is no change.
+func writeChangelog(w io.Writer, old, new *Model) error {
+	d := Diff(old, new)
+	oldNames, newNames := modelNames(old), modelNames(new)
+
+	var newExts []string
+	oldExts := modelExtensions(old)
+	for ext := range modelExtensions(new) {
+		if !oldExts[ext] {
+			newExts = append(newExts, "`"+ext+"`")
+		}
+	}
+	sort.Strings(newExts)
+
+	var addedForms, removedForms, fixes, others []string
+	for _, fd := range d.Added {
+		if oldNames[fd.Key.Name] {
+			addedForms = append(addedForms, "`"+fd.Key.String()+"`")
+		}
+	}
+	for _, fd := range d.Removed {
+		if newNames[fd.Key.Name] {
+			removedForms = append(removedForms, "`"+fd.Key.String()+"`")
+		}
+	}
+	for _, fd := range d.Changed {
+		changes := make([]string, len(fd.Changes))
+		encoding := false
+		for i, c := range fd.Changes {
+			changes[i] = c.String()
+			encoding = encoding || c.Field == "opcode" || c.Field == "encoding"
+		}
+		item := fmt.Sprintf("`%s`: %s", fd.Key, strings.Join(changes, ", "))
+		if encoding {
+			fixes = append(fixes, item)
+		} else {
+			others = append(others, item)
+		}
+	}
+
+	sections := []changelogSection{
+		{"New extensions", newExts},
+		{"New instructions", sortedNames(d.Added, oldNames)},
+		{"Removed instructions", sortedNames(d.Removed, newNames)},
+		{"New forms", addedForms},
+		{"Removed forms", removedForms},
+		{"Encoding fixes", fixes},
+		{"Other changes", others},
+	}
+	empty := true
+	for _, sec := range sections {
+		empty = empty && len(sec.items) == 0
+	}
+	if empty {
+		return nil
+	}
+
+	fmt.Fprintf(w, "\n### %s\n", new.Arch)
+	for _, sec := range sections {
+		if len(sec.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n#### %s\n\n", sec.title)
+		for _, item := range sec.items {
+			if _, err := fmt.Fprintf(w, "- %s\n", item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
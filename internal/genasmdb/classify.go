@@ -0,0 +1,329 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+func init() {
+	for _, arch := range []string{"x86", "arm"} {
+		registerEmitter(arch, "json", "categories.json", "write the "+arch+" instruction categories and their mnemonics as JSON to `file`", &emitterFunc{name: arch + "-categories", fn: func(m *Model, w io.Writer) error {
+			return writeCategories(w, m)
+		}, out: []*InstructionCategory{}})
+	}
+}
+
+// The instruction categories.
+const (
+	CategoryIntALU    = "int-alu"
+	CategoryShift     = "shift"
+	CategoryBranch    = "branch"
+	CategoryLoadStore = "load-store"
+	CategoryFP        = "fp"
+	CategorySIMDInt   = "simd-int"
+	CategorySIMDFP    = "simd-fp"
+	CategoryCrypto    = "crypto"
+	CategorySystem    = "system"
+)
+
+// categories is the list of the instruction categories with their descriptions, in the order of the output.
+var categories = []struct {
+	name, doc string
+}{
+	{CategoryIntALU, "integer arithmetic, logic, compare and bit manipulation"},
+	{CategoryShift, "shifts and rotates of the general purpose registers"},
+	{CategoryBranch, "branches, calls and returns, and the Thumb IT blocks"},
+	{CategoryLoadStore, "loads, stores, data moves, stack operations and prefetches"},
+	{CategoryFP, "scalar floating-point, the x87 FPU and the ARM VFP"},
+	{CategorySIMDInt, "SIMD integer, the vector and the mask registers"},
+	{CategorySIMDFP, "SIMD floating-point"},
+	{CategoryCrypto, "cryptography, AES, SHA, carry-less multiply and GF(2^8)"},
+	{CategorySystem, "system, privileged, barrier, hint and processor state"},
+}
+
+// x86ExtensionCategories is the curated category of the forms requiring the extension.
+//
+// The extensions not listed, like "AVX2" and "BMI", are classified by the operands and the names of the forms.
+var x86ExtensionCategories = map[string]string{
+	"AESNI": CategoryCrypto, "VAES": CategoryCrypto, "SHA": CategoryCrypto,
+	"PCLMULQDQ": CategoryCrypto, "VPCLMULQDQ": CategoryCrypto, "GFNI": CategoryCrypto,
+
+	"MOVBE": CategoryLoadStore, "MOVDIRI": CategoryLoadStore, "MOVDIR64B": CategoryLoadStore,
+	"PREFETCHW": CategoryLoadStore, "PREFETCHWT1": CategoryLoadStore,
+
+	"AMX_TILE": CategorySIMDInt, "AMX_INT8": CategorySIMDInt,
+	"AMX_BF16": CategorySIMDFP, "3DNOW": CategorySIMDFP,
+
+	"CET_IBT": CategorySystem, "CET_SS": CategorySystem, "CLDEMOTE": CategorySystem,
+	"CLFLUSH": CategorySystem, "CLFLUSHOPT": CategorySystem, "CLWB": CategorySystem,
+	"CLZERO": CategorySystem, "ENQCMD": CategorySystem, "FSGSBASE": CategorySystem,
+	"FXSR": CategorySystem, "HRESET": CategorySystem, "LWP": CategorySystem,
+	"MCOMMIT": CategorySystem, "MONITOR": CategorySystem, "MONITORX": CategorySystem,
+	"OSPKE": CategorySystem, "PCONFIG": CategorySystem, "PTWRITE": CategorySystem,
+	"RDPID": CategorySystem, "RDPRU": CategorySystem, "RDTSC": CategorySystem,
+	"RDTSCP": CategorySystem, "RTM": CategorySystem, "SEAM": CategorySystem,
+	"SERIALIZE": CategorySystem, "SKINIT": CategorySystem, "SMAP": CategorySystem,
+	"SMX": CategorySystem, "SNP": CategorySystem, "SVM": CategorySystem,
+	"TSX": CategorySystem, "TSXLDTRK": CategorySystem, "UINTR": CategorySystem,
+	"VMX": CategorySystem, "WAITPKG": CategorySystem, "WBNOINVD": CategorySystem,
+	"XSAVE": CategorySystem, "XSAVEC": CategorySystem, "XSAVEOPT": CategorySystem,
+	"XSAVES": CategorySystem,
+}
+
+// x86NameCategories is the curated category of the forms of the names, the forms of the other names
+// being integer ALU unless classified by the extensions, the control flow or the operands.
+var x86NameCategories = map[string]string{
+	"rcl": CategoryShift, "rcr": CategoryShift, "rol": CategoryShift, "ror": CategoryShift,
+	"sal": CategoryShift, "sar": CategoryShift, "shl": CategoryShift, "shr": CategoryShift,
+	"shld": CategoryShift, "shrd": CategoryShift, "rorx": CategoryShift,
+	"sarx": CategoryShift, "shlx": CategoryShift, "shrx": CategoryShift,
+
+	"mov": CategoryLoadStore, "movabs": CategoryLoadStore, "movsx": CategoryLoadStore,
+	"movsxd": CategoryLoadStore, "movzx": CategoryLoadStore, "movnti": CategoryLoadStore,
+	"movsb": CategoryLoadStore, "movsw": CategoryLoadStore, "movsd": CategoryLoadStore, "movsq": CategoryLoadStore,
+	"lodsb": CategoryLoadStore, "lodsw": CategoryLoadStore, "lodsd": CategoryLoadStore, "lodsq": CategoryLoadStore,
+	"stosb": CategoryLoadStore, "stosw": CategoryLoadStore, "stosd": CategoryLoadStore, "stosq": CategoryLoadStore,
+	"push": CategoryLoadStore, "pusha": CategoryLoadStore, "pushad": CategoryLoadStore,
+	"pushf": CategoryLoadStore, "pushfd": CategoryLoadStore, "pushfq": CategoryLoadStore,
+	"pop": CategoryLoadStore, "popa": CategoryLoadStore, "popad": CategoryLoadStore,
+	"popf": CategoryLoadStore, "popfd": CategoryLoadStore, "popfq": CategoryLoadStore,
+	"enter": CategoryLoadStore, "leave": CategoryLoadStore, "xchg": CategoryLoadStore, "xlatb": CategoryLoadStore,
+	"lds": CategoryLoadStore, "les": CategoryLoadStore, "lfs": CategoryLoadStore, "lgs": CategoryLoadStore, "lss": CategoryLoadStore,
+	"prefetch": CategoryLoadStore, "prefetchnta": CategoryLoadStore,
+	"prefetcht0": CategoryLoadStore, "prefetcht1": CategoryLoadStore, "prefetcht2": CategoryLoadStore,
+
+	// emms empties the x87 tag word shared with the MMX registers
+	"emms": CategoryFP,
+
+	"ldmxcsr": CategorySIMDFP, "stmxcsr": CategorySIMDFP, "vldmxcsr": CategorySIMDFP, "vstmxcsr": CategorySIMDFP,
+
+	"arpl": CategorySystem, "clts": CategorySystem, "cli": CategorySystem, "sti": CategorySystem,
+	"cpuid": CategorySystem, "hlt": CategorySystem, "in": CategorySystem, "out": CategorySystem,
+	"insb": CategorySystem, "insw": CategorySystem, "insd": CategorySystem,
+	"outsb": CategorySystem, "outsw": CategorySystem, "outsd": CategorySystem,
+	"int": CategorySystem, "int1": CategorySystem, "int3": CategorySystem, "into": CategorySystem,
+	"invd": CategorySystem, "invlpg": CategorySystem, "invpcid": CategorySystem, "wbinvd": CategorySystem,
+	"lar": CategorySystem, "lsl": CategorySystem, "verr": CategorySystem, "verw": CategorySystem,
+	"lgdt": CategorySystem, "lidt": CategorySystem, "lldt": CategorySystem, "ltr": CategorySystem, "lmsw": CategorySystem,
+	"sgdt": CategorySystem, "sidt": CategorySystem, "sldt": CategorySystem, "str": CategorySystem, "smsw": CategorySystem,
+	"lfence": CategorySystem, "mfence": CategorySystem, "sfence": CategorySystem,
+	"nop": CategorySystem, "pause": CategorySystem, "ud0": CategorySystem, "ud1": CategorySystem, "ud2": CategorySystem,
+	"rdmsr": CategorySystem, "wrmsr": CategorySystem, "rdpmc": CategorySystem, "rsm": CategorySystem, "swapgs": CategorySystem,
+	"syscall": CategorySystem, "sysenter": CategorySystem, "sysexit": CategorySystem, "sysexitq": CategorySystem,
+	"sysret": CategorySystem, "sysretq": CategorySystem,
+}
+
+// armNameCategories is the curated category of the forms of the lowercased ARM mnemonics.
+//
+// The loads and the stores are the mnemonics starting with "ld" and "st", and the IT blocks the ones starting with "it".
+var armNameCategories = map[string]string{
+	"b": CategoryBranch, "bl": CategoryBranch, "blx": CategoryBranch, "bx": CategoryBranch, "bxj": CategoryBranch,
+	"cbz": CategoryBranch, "cbnz": CategoryBranch, "tbb": CategoryBranch, "tbh": CategoryBranch,
+
+	"asr": CategoryShift, "asrs": CategoryShift, "lsl": CategoryShift, "lsls": CategoryShift,
+	"lsr": CategoryShift, "lsrs": CategoryShift, "ror": CategoryShift, "rors": CategoryShift,
+	"rrx": CategoryShift, "rrxs": CategoryShift,
+
+	"pld": CategoryLoadStore, "pldw": CategoryLoadStore, "pli": CategoryLoadStore,
+	"push": CategoryLoadStore, "pop": CategoryLoadStore, "swp": CategoryLoadStore, "swpb": CategoryLoadStore,
+	"fldmdbx": CategoryLoadStore, "fldmiax": CategoryLoadStore, "fstmdbx": CategoryLoadStore, "fstmiax": CategoryLoadStore,
+
+	"bkpt": CategorySystem, "clrex": CategorySystem, "cps": CategorySystem, "cpsid": CategorySystem, "cpsie": CategorySystem,
+	"dbg": CategorySystem, "dmb": CategorySystem, "dsb": CategorySystem, "isb": CategorySystem,
+	"eret": CategorySystem, "hlt": CategorySystem, "hvc": CategorySystem, "smc": CategorySystem, "svc": CategorySystem, "udf": CategorySystem,
+	"mcr": CategorySystem, "mcr2": CategorySystem, "mcrr": CategorySystem, "mcrr2": CategorySystem,
+	"mrc": CategorySystem, "mrc2": CategorySystem, "mrrc": CategorySystem, "mrrc2": CategorySystem,
+	"mrs": CategorySystem, "msr": CategorySystem, "nop": CategorySystem, "setend": CategorySystem,
+	"rfe": CategorySystem, "rfeda": CategorySystem, "rfedb": CategorySystem, "rfeib": CategorySystem,
+	"srs": CategorySystem, "srsda": CategorySystem, "srsdb": CategorySystem, "srsib": CategorySystem,
+	"sev": CategorySystem, "sevl": CategorySystem, "wfe": CategorySystem, "wfi": CategorySystem, "yield": CategorySystem,
+}
+
+// armExtensionCategories is the curated category of the ARM forms requiring the extension.
+var armExtensionCategories = map[string]string{
+	"AES":      CategoryCrypto,
+	"SHA1":     CategoryCrypto,
+	"SHA256":   CategoryCrypto,
+	"SECURITY": CategorySystem,
+}
+
+// Category returns the category of the instruction, like "simd-fp".
+//
+// The category is derived from the metadata, the control flow attribute and the curated extensions,
+// then from the opcode of the x87 forms, the vector operands and the curated names, and is "int-alu" otherwise.
+func (x *X86) Category(inst *X86Instruction) string {
+	meta := x.ParseMetadata(inst.Metadata)
+	if _, ok := meta.Attributes["Control"]; ok {
+		return CategoryBranch
+	}
+	for _, ext := range meta.Extensions {
+		if cat, ok := x86ExtensionCategories[ext]; ok {
+			return cat
+		}
+	}
+
+	if op, err := inst.ParseOpCode(); err == nil && len(op.Bytes) > 0 && op.Prefix == "" && op.Bytes[0] >= 0xD8 && op.Bytes[0] <= 0xDF {
+		return CategoryFP
+	}
+
+	name := inst.Names()[0]
+	if ops, err := inst.ParseOperands(); err == nil {
+		for _, op := range ops {
+			for _, kind := range op.Kinds {
+				switch x86RegClass(kind) {
+				case "mm", "xmm", "ymm", "zmm", "k", "tmm":
+					if x86IsFPName(name) {
+						return CategorySIMDFP
+					}
+					return CategorySIMDInt
+				}
+			}
+		}
+	}
+
+	if cat, ok := x86NameCategories[name]; ok {
+		return cat
+	}
+	if name == "fwait" || name == "wait" {
+		return CategoryFP
+	}
+	return CategoryIntALU
+}
+
+// x86IsFPName reports whether the name of the vector form is the one of a floating-point operation, like "vaddps",
+// "cvtsi2sd" and "vinsertf128". The integer forms, starting with "p" and "vp", are floating-point only for the permutes
+// of the packed floating-point elements, like "vpermilps", as the "d" of the doubleword forms, like "vpcmpd", follows "p" too.
+func x86IsFPName(name string) bool {
+	if strings.HasPrefix(name, "p") || strings.HasPrefix(name, "vp") {
+		return strings.HasPrefix(name, "vperm") && (strings.HasSuffix(name, "ps") || strings.HasSuffix(name, "pd"))
+	}
+	for _, suffix := range []string{"ps", "pd", "ss", "sd", "ph", "sh", "psx", "phx", "bf16"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	for _, s := range []string{"cvt", "f32x", "f64x", "f128", "mxcsr", "ddup", "hdup", "ldup"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Category returns the category of the instruction, like "simd-int".
+//
+// The category is derived from the curated mnemonics and extensions, the ASIMD forms are SIMD floating-point
+// for the "F" data types, like "vadd.f32", and the other "v" forms are VFP floating-point.
+func (a *Arm) Category(inst *ArmInstruction) string {
+	mnemonic, suffixes := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	if cat, ok := armNameCategories[mnemonic]; ok {
+		return cat
+	}
+	switch {
+	case strings.HasPrefix(mnemonic, "it"):
+		return CategoryBranch
+	case strings.HasPrefix(mnemonic, "ld"), strings.HasPrefix(mnemonic, "st"):
+		return CategoryLoadStore
+	}
+
+	meta := a.ParseMetadata(inst.Metadata)
+	for _, ext := range meta.Extensions {
+		if cat, ok := armExtensionCategories[ext]; ok {
+			return cat
+		}
+	}
+	if !strings.HasPrefix(mnemonic, "v") {
+		return CategoryIntALU
+	}
+	if !meta.HasExtension("ASIMD") {
+		return CategoryFP
+	}
+	for _, s := range suffixes {
+		if strings.HasPrefix(strings.ToLower(s), "f") {
+			return CategorySIMDFP
+		}
+	}
+	return CategorySIMDInt
+}
+
+// InstructionsInCategory returns the instructions of the category, like "crypto".
+func (x *X86) InstructionsInCategory(insts []X86Instruction, cat string) []X86Instruction {
+	var forms []X86Instruction
+	for i := range insts {
+		if x.Category(&insts[i]) == cat {
+			forms = append(forms, insts[i])
+		}
+	}
+	return forms
+}
+
+// InstructionsInCategory returns the instructions of the category, like "branch".
+func (a *Arm) InstructionsInCategory(insts []ArmInstruction, cat string) []ArmInstruction {
+	var forms []ArmInstruction
+	for i := range insts {
+		if a.Category(&insts[i]) == cat {
+			forms = append(forms, insts[i])
+		}
+	}
+	return forms
+}
+
+// InstructionCategory represents an instruction category and its mnemonics.
+type InstructionCategory struct {
+	Name string `json:"name"`
+	Doc  string `json:"doc"`
+
+	// Forms is the number of the forms of the category.
+	Forms int `json:"forms"`
+
+	// Mnemonics is the sorted list of the mnemonics of the forms, the ARM ones lowercased.
+	Mnemonics []string `json:"mnemonics"`
+}
+
+// instructionCategories returns the categories of the forms of m in the order of categories.
+// The categories without any form are omitted.
+func instructionCategories(m *Model) []*InstructionCategory {
+	byName := make(map[string]*InstructionCategory)
+	add := func(cat string, names ...string) {
+		c := byName[cat]
+		if c == nil {
+			c = &InstructionCategory{Name: cat}
+			byName[cat] = c
+		}
+		c.Forms++
+		for _, name := range names {
+			if !containsString(c.Mnemonics, name) {
+				c.Mnemonics = append(c.Mnemonics, name)
+			}
+		}
+	}
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		add(m.X86.Category(inst), inst.Names()...)
+	}
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		mnemonic, _ := splitArmName(inst.Name)
+		add(m.Arm.Category(inst), strings.ToLower(mnemonic))
+	}
+
+	var list []*InstructionCategory
+	for _, cat := range categories {
+		if c := byName[cat.name]; c != nil {
+			c.Doc = cat.doc
+			sort.Strings(c.Mnemonics)
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// writeCategories writes the instruction categories of the forms of m as JSON to w.
+func writeCategories(w io.Writer, m *Model) error {
+	return writeJSON(w, m.Arch+" categories", instructionCategories(m))
+}
@@ -0,0 +1,73 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "testing"
+
+func TestX86Category(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     string
+	}{
+		// the control flow attribute
+		{"jmp", "rel32", CategoryBranch},
+		// x86ExtensionCategories
+		{"aesenc", "X:xmm, xmm/m128", CategoryCrypto},
+		{"kmovw", "W:k[15:0], r32[15:0]", CategorySIMDInt},
+		// the x87 opcodes
+		{"fadd", "R:m32fp", CategoryFP},
+		// the vector operands
+		{"paddd", "X:~xmm, ~xmm/m128", CategorySIMDInt},
+		{"addps", "X:~xmm, ~xmm/m128", CategorySIMDFP},
+		{"vaddps", "W:ymm,~ymm,~ymm/m256", CategorySIMDFP},
+		{"vpcmpd", "W:k {k}, xmm, xmm/m128/b32, ib/ub", CategorySIMDInt},
+		{"vpcmpud", "W:k {k}, xmm, xmm/m128/b32, ib/ub", CategorySIMDInt},
+		{"vpermilps", "W:xmm, xmm, xmm/m128", CategorySIMDFP},
+		// x86NameCategories
+		{"shl/sal", "x:r8/m8, cl", CategoryShift},
+		{"mov", "W:r32, r32/m32", CategoryLoadStore},
+		{"cpuid", "X:<eax>, W:<ebx>, X:<ecx>, W:<edx>", CategorySystem},
+		{"fwait/wait", "", CategoryFP},
+		{"emms", "", CategoryFP},
+		{"add", "X:~r32,~r32/m32", CategoryIntALU},
+	}
+	for _, tt := range tests {
+		if got := x86.X86.Category(testX86Form(t, tt.name, tt.operands)); got != tt.want {
+			t.Errorf("Category(%s %s) = %q, want %q", tt.name, tt.operands, got, tt.want)
+		}
+	}
+}
+
+func TestArmCategory(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     string
+	}{
+		// armNameCategories
+		{"b", "#RelS*2", ArmT16, CategoryBranch},
+		{"lsl", "Rd!=XX, Rn!=XX, #Shift", ArmT32, CategoryShift},
+		{"push", "Rs!=SP", ArmA32, CategoryLoadStore},
+		{"dmb", "#ImmZ", ArmA32, CategorySystem},
+		// the "it" and the "ld" and "st" prefixes
+		{"it", "#FirstCond!=15", ArmT16, CategoryBranch},
+		{"ldr", "Rd!=HI, [Rn!=HI, #ImmZ*4]", ArmT16, CategoryLoadStore},
+		// armExtensionCategories
+		{"aese.<dt>", "Vx, Vm", ArmA32, CategoryCrypto},
+		// the VFP and the ASIMD data types
+		{"vadd.f32", "Sd, Sn, Sm", ArmA32, CategoryFP},
+		{"vadd.f32", "Vd, Vn, Vm", ArmA32, CategorySIMDFP},
+		{"vadd.x8-64", "Dd, Dn, Dm", ArmA32, CategorySIMDInt},
+		{"adc", "Rd    , Rn    , #ImmA", ArmA32, CategoryIntALU},
+	}
+	for _, tt := range tests {
+		if got := arm.Arm.Category(testArmForm(t, tt.name, tt.operands, tt.arch)); got != tt.want {
+			t.Errorf("Category(%s %s %s) = %q, want %q", tt.arch, tt.name, tt.operands, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+func init() {
+	for _, arch := range []string{"x86", "arm"} {
+		registerEmitter(arch, "json", "completion.json", "write the compact "+arch+" mnemonics and signatures JSON for the editor completion and hover to `file`", &emitterFunc{name: arch + "-completion", fn: func(m *Model, w io.Writer) error {
+			return writeCompletion(w, m)
+		}, out: []*CompletionEntry(nil)})
+	}
+}
+
+// CompletionEntry represents a mnemonic and the signatures of its forms for the editor completion.
+type CompletionEntry struct {
+	Mnemonic string `json:"mnemonic"`
+
+	// Signatures is the list of the forms of the mnemonic in the order of the asmdb data.
+	Signatures []*CompletionSignature `json:"signatures"`
+}
+
+// CompletionSignature represents the signature of an instruction form and its one-line doc for the hover.
+type CompletionSignature struct {
+	// Label is the name and the explicit operands of the form without the access and the constraints,
+	// like "vaddps zmm, zmm, zmm/m512/b32 {kz}" and "adcs Rd, Rn, #ImmA".
+	Label string `json:"label"`
+
+	// Doc is the one-line description of the form, the extensions and the opcode, like "AVX512_F: EVEX.512.0F.W0 58 /r",
+	// or the instruction set and the extensions for ARM, like "A32 ASIMD".
+	Doc string `json:"doc"`
+}
+
+// completionEntries returns the completion entries of the forms of m in the sorted order of the mnemonics.
+//
+// The ARM mnemonics are lowercased, so the flag setting "adcS" is completed as "adcs".
+func completionEntries(m *Model) ([]*CompletionEntry, error) {
+	entries := make(map[string]*CompletionEntry)
+	add := func(mnemonic, name string, operands []string, doc string) {
+		e := entries[mnemonic]
+		if e == nil {
+			e = &CompletionEntry{Mnemonic: mnemonic}
+			entries[mnemonic] = e
+		}
+		label := name
+		if len(operands) > 0 {
+			label += " " + strings.Join(operands, ", ")
+		}
+		e.Signatures = append(e.Signatures, &CompletionSignature{Label: label, Doc: doc})
+	}
+
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		ops, err := inst.ParseOperands()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inst.Name, err)
+		}
+		var operands []string
+		for _, op := range ops {
+			if op.Implicit {
+				continue
+			}
+			o := *op
+			o.Access, o.Commutative = "", false
+			operands = append(operands, o.String())
+		}
+		doc := inst.OpCode
+		if exts := m.X86.ParseMetadata(inst.Metadata).Extensions; len(exts) > 0 {
+			doc = strings.Join(exts, " ") + ": " + doc
+		}
+		for _, name := range inst.Names() {
+			add(name, name, operands, doc)
+		}
+	}
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		fields, err := splitArmOperands(inst.Operands)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+		}
+		var operands []string
+		for _, f := range fields {
+			operands = append(operands, armConstraintRe.ReplaceAllString(f, ""))
+		}
+		mnemonic, _ := splitArmName(inst.Name)
+		name := strings.ToLower(mnemonic) + inst.Name[len(mnemonic):]
+		doc := strings.Join(append([]string{inst.Arch}, m.Arm.ParseMetadata(inst.Metadata).Extensions...), " ")
+		add(strings.ToLower(mnemonic), name, operands, doc)
+	}
+
+	list := make([]*CompletionEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Mnemonic < list[j].Mnemonic })
+	return list, nil
+}
+
+// writeCompletion writes the completion entries of m to w as JSON without the indentation,
+// so the editor plugins can load it at once.
+func writeCompletion(w io.Writer, m *Model) error {
+	entries, err := completionEntries(m)
+	if err != nil {
+		return err
+	}
+	if err := (json.MarshalOptions{}).MarshalFull(json.EncodeOptions{}, w, entries); err != nil {
+		return fmt.Errorf("marshal %s completion: %w", m.Arch, err)
+	}
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
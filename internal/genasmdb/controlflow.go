@@ -0,0 +1,183 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("controlflow", "controlflow.json", "write the %s forms changing the control flow and their behavior as JSON to `file`", []*ControlFlowForm{}, x86ControlFlowForm, armControlFlowForm)
+}
+
+// The kinds of the control flow.
+const (
+	// ControlFlowJump is a branch to the target, like "jmp" and "jz".
+	ControlFlowJump = "jump"
+	// ControlFlowCall is a branch to the target saving the return address, like "call" and "bl".
+	ControlFlowCall = "call"
+	// ControlFlowReturn is a branch to the saved return address, like "ret" and "eret".
+	ControlFlowReturn = "return"
+	// ControlFlowInterrupt is a software interrupt, a system call or a trap, like "int" and "svc".
+	ControlFlowInterrupt = "interrupt"
+)
+
+// ControlFlow represents the control-flow behavior of an instruction form.
+type ControlFlow struct {
+	// Kind is the kind of the control flow, one of ControlFlowJump, ControlFlowCall, ControlFlowReturn and ControlFlowInterrupt.
+	Kind string `json:"kind"`
+
+	// Conditional reports whether the control flow depends on a condition, like the flags of "jz",
+	// the Cond field of the ARM forms and the compare of "cbz".
+	Conditional bool `json:"conditional,omitzero"`
+
+	// Indirect reports whether the target is read from a register or memory, like "jmp r64/m64" and "bx Rm".
+	Indirect bool `json:"indirect,omitzero"`
+
+	// Target is the index of the operand of the target, the relative offset, the register or the memory
+	// of the jumps and the calls, the selector of the x86 far direct forms and the vector of the interrupts.
+	// It's -1 if the target is implicit, like the return address.
+	Target int `json:"target"`
+}
+
+// x86ControlFlowKinds maps the value of the "Control" attribute to the kind of the control flow.
+// The "Branch" forms are the conditional jumps.
+var x86ControlFlowKinds = map[string]string{
+	"Branch": ControlFlowJump,
+	"Jump":   ControlFlowJump,
+	"Call":   ControlFlowCall,
+	"Return": ControlFlowReturn,
+}
+
+// x86ControlFlowNames is the curated control flow of the forms without the "Control" attribute, the interrupts,
+// the system calls and the returns from them.
+var x86ControlFlowNames = map[string]string{
+	"int": ControlFlowInterrupt, "int1": ControlFlowInterrupt, "int3": ControlFlowInterrupt, "into": ControlFlowInterrupt,
+	"syscall": ControlFlowInterrupt, "sysenter": ControlFlowInterrupt,
+	"ud0": ControlFlowInterrupt, "ud1": ControlFlowInterrupt, "ud2": ControlFlowInterrupt,
+	"sysret": ControlFlowReturn, "sysretq": ControlFlowReturn, "sysexit": ControlFlowReturn, "sysexitq": ControlFlowReturn,
+}
+
+// ControlFlow returns the control-flow behavior of the instruction, or nil if it continues with the next instruction.
+//
+// The behavior is derived from the "Control" attribute of the metadata, and from the curated names of the interrupts.
+func (x *X86) ControlFlow(inst *X86Instruction) (*ControlFlow, error) {
+	name := inst.Names()[0]
+	control := x.ParseMetadata(inst.Metadata).Attributes["Control"]
+	kind, ok := x86ControlFlowKinds[control]
+	if !ok {
+		kind, ok = x86ControlFlowNames[name]
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	cf := &ControlFlow{Kind: kind, Conditional: control == "Branch" || name == "into", Target: -1}
+	if kind == ControlFlowReturn {
+		// the immediate of "ret uw" is the size of the released stack
+		return cf, nil
+	}
+	for _, op := range ops {
+		if op.Implicit || kind == ControlFlowInterrupt && !op.IsImm() {
+			continue
+		}
+		cf.Target = op.Index
+		cf.Indirect = op.IsReg() || op.IsMem()
+		break
+	}
+	return cf, nil
+}
+
+// armControlFlowNames is the curated control flow of the lowercased ARM mnemonics.
+//
+// The forms branching by writing PC, like "pop {pc}", "ldr pc, [Rn]" and "mov pc, lr", aren't listed,
+// as their control flow depends on the register operands.
+var armControlFlowNames = map[string]string{
+	"b": ControlFlowJump, "bx": ControlFlowJump, "bxj": ControlFlowJump,
+	"cbz": ControlFlowJump, "cbnz": ControlFlowJump, "tbb": ControlFlowJump, "tbh": ControlFlowJump,
+	"bl": ControlFlowCall, "blx": ControlFlowCall,
+	"eret": ControlFlowReturn, "rfe": ControlFlowReturn, "rfeda": ControlFlowReturn, "rfedb": ControlFlowReturn, "rfeib": ControlFlowReturn,
+	"svc": ControlFlowInterrupt, "smc": ControlFlowInterrupt, "hvc": ControlFlowInterrupt,
+	"bkpt": ControlFlowInterrupt, "udf": ControlFlowInterrupt,
+}
+
+// ControlFlow returns the control-flow behavior of the instruction, or nil if it continues with the next instruction.
+//
+// The forms are conditional with the Cond field of the opcode, and "cbz" and "cbnz" with the compare.
+func (a *Arm) ControlFlow(inst *ArmInstruction) (*ControlFlow, error) {
+	mnemonic, _ := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	kind, ok := armControlFlowNames[mnemonic]
+	if !ok {
+		return nil, nil
+	}
+
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	cf := &ControlFlow{Kind: kind, Target: -1}
+	for _, field := range strings.Split(inst.OpCode, "|") {
+		if strings.TrimSpace(field) == "Cond" {
+			cf.Conditional = true
+		}
+	}
+	if mnemonic == "cbz" || mnemonic == "cbnz" {
+		cf.Conditional = true
+	}
+	if kind == ControlFlowReturn {
+		// the memory of "rfe" is the saved return address and status
+		return cf, nil
+	}
+	for _, op := range ops {
+		switch op.Type {
+		case ArmOperandRel, ArmOperandImm:
+			cf.Target = op.Index
+		case ArmOperandReg, ArmOperandMem:
+			if kind == ControlFlowInterrupt || mnemonic == "cbz" || mnemonic == "cbnz" {
+				continue
+			}
+			cf.Target = op.Index
+			cf.Indirect = true
+		default:
+			continue
+		}
+		break
+	}
+	return cf, nil
+}
+
+// ControlFlowForm represents an instruction form changing the control flow.
+type ControlFlowForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "D", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode string       `json:"opcode"`
+	Flow   *ControlFlow `json:"flow"`
+}
+
+// x86ControlFlowForm returns the form of inst if it changes the control flow, or nil.
+func x86ControlFlowForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	cf, err := x.ControlFlow(inst)
+	if err != nil || cf == nil {
+		return nil, err
+	}
+	return &ControlFlowForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Flow: cf}, nil
+}
+
+// armControlFlowForm returns the form of inst if it changes the control flow, or nil.
+func armControlFlowForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	cf, err := a.ControlFlow(inst)
+	if err != nil || cf == nil {
+		return nil, err
+	}
+	return &ControlFlowForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Flow: cf}, nil
+}
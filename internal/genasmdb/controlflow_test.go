@@ -0,0 +1,64 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86ControlFlow(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *ControlFlow
+	}{
+		// x86ControlFlowKinds
+		{"je/jz", "rel8", &ControlFlow{Kind: ControlFlowJump, Conditional: true, Target: 0}},
+		{"jmp", "rel32", &ControlFlow{Kind: ControlFlowJump, Target: 0}},
+		{"jmp", "R:r64/m64", &ControlFlow{Kind: ControlFlowJump, Indirect: true, Target: 0}},
+		{"call", "rel32", &ControlFlow{Kind: ControlFlowCall, Target: 0}},
+		{"ret", "uw", &ControlFlow{Kind: ControlFlowReturn, Target: -1}},
+		// x86ControlFlowNames
+		{"int", "ib/ub", &ControlFlow{Kind: ControlFlowInterrupt, Target: 0}},
+		{"into", "", &ControlFlow{Kind: ControlFlowInterrupt, Conditional: true, Target: -1}},
+		{"syscall", "", &ControlFlow{Kind: ControlFlowInterrupt, Target: -1}},
+		{"ud2", "", &ControlFlow{Kind: ControlFlowInterrupt, Target: -1}},
+		{"sysret", "", &ControlFlow{Kind: ControlFlowReturn, Target: -1}},
+		{"lea", "W:r32, mem", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.ControlFlow(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ControlFlow(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
+
+func TestArmControlFlow(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     *ControlFlow
+	}{
+		{"b", "#RelS*4", ArmA32, &ControlFlow{Kind: ControlFlowJump, Conditional: true, Target: 0}},
+		{"bx", "Rm", ArmT16, &ControlFlow{Kind: ControlFlowJump, Indirect: true, Target: 0}},
+		{"cbz", "Rn!=HI, #RelZ*2", ArmT16, &ControlFlow{Kind: ControlFlowJump, Conditional: true, Target: 1}},
+		{"bl", "#RelS*2", ArmT32, &ControlFlow{Kind: ControlFlowCall, Target: 0}},
+		{"eret", "", ArmA32, &ControlFlow{Kind: ControlFlowReturn, Conditional: true, Target: -1}},
+		{"rfe", "[Rn!=PC]{!}", ArmA32, &ControlFlow{Kind: ControlFlowReturn, Target: -1}},
+		{"svc", "#ImmZ", ArmT16, &ControlFlow{Kind: ControlFlowInterrupt, Target: 0}},
+		{"bkpt", "#ImmZ", ArmT16, &ControlFlow{Kind: ControlFlowInterrupt, Target: 0}},
+		{"add", "Rd!=HI, Rn!=HI, #ImmZ", ArmT16, nil},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.ControlFlow(testArmForm(t, tt.name, tt.operands, tt.arch))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ControlFlow(%s %s %s) = %+v, %v, want %+v", tt.arch, tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
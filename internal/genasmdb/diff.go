@@ -0,0 +1,179 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+// Delta represents the changes of the instruction forms between two versions of the asmdb data of an architecture.
+//
+// The added and the removed forms list all their fields as changed from or to empty.
+type Delta struct {
+	Arch    string     `json:"arch"`
+	Added   []FormDiff `json:"added,omitzero"`
+	Removed []FormDiff `json:"removed,omitzero"`
+	Changed []FormDiff `json:"changed,omitzero"`
+}
+
+// Empty reports whether there is no change.
+func (d *Delta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// FormKey identifies the instruction form, the ARM instruction set, the name and the operands of the form.
+type FormKey struct {
+	// Arch is the instruction set of the ARM form, like ArmA64, and empty for x86.
+	Arch string `json:"arch,omitzero"`
+
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Index is the index of the form among the forms of the same key, like the x86 forms of the legacy
+	// and the VEX encoding.
+	Index int `json:"index,omitzero"`
+}
+
+// String returns the key like "A64 add Xd, Xn, Xm" and "vaddps W:xmm,~xmm,~xmm/m128 #1".
+func (k FormKey) String() string {
+	s := k.Name
+	if k.Arch != "" {
+		s = k.Arch + " " + s
+	}
+	if k.Operands != "" {
+		s += " " + k.Operands
+	}
+	if k.Index != 0 {
+		s += " #" + strconv.Itoa(k.Index)
+	}
+	return s
+}
+
+// FormDiff represents the changed fields of the instruction form.
+type FormDiff struct {
+	Key     FormKey       `json:"key"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange represents the change of a field of the instruction form, like "opcode".
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// String returns the change like `opcode: "1111" -> "1110"`.
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Field, c.Old, c.New)
+}
+
+// diffForm is the instruction form with its fields in the order of the changes.
+type diffForm struct {
+	key    FormKey
+	fields [][2]string // name and value
+}
+
+// diffForms returns the instruction forms of m in the order of the asmdb data.
+func diffForms(m *Model) []diffForm {
+	var forms []diffForm
+	for _, inst := range m.X86Instructions {
+		forms = append(forms, diffForm{
+			key:    FormKey{Name: inst.Name, Operands: inst.Operands},
+			fields: [][2]string{{"encoding", inst.Encoding}, {"opcode", inst.OpCode}, {"metadata", inst.Metadata}},
+		})
+	}
+	for _, inst := range m.ArmInstructions {
+		forms = append(forms, diffForm{
+			key:    FormKey{Arch: inst.Arch, Name: inst.Name, Operands: inst.Operands},
+			fields: [][2]string{{"opcode", inst.OpCode}, {"metadata", inst.Metadata}},
+		})
+	}
+
+	seen := make(map[FormKey]int)
+	for i := range forms {
+		key := forms[i].key
+		forms[i].key.Index = seen[key]
+		seen[key]++
+	}
+	return forms
+}
+
+// Diff returns the changes of the instruction forms from old to new of the same architecture.
+//
+// The forms are matched by their key, the forms of the same name and operands by their order. The removed
+// and the changed forms are in the order of old, the added forms in the order of new.
+func Diff(old, new *Model) *Delta {
+	d := &Delta{Arch: new.Arch}
+
+	newForms := diffForms(new)
+	byKey := make(map[FormKey]diffForm, len(newForms))
+	for _, f := range newForms {
+		byKey[f.key] = f
+	}
+
+	oldKeys := make(map[FormKey]bool)
+	for _, of := range diffForms(old) {
+		oldKeys[of.key] = true
+		nf, ok := byKey[of.key]
+		if !ok {
+			fd := FormDiff{Key: of.key}
+			for _, field := range of.fields {
+				fd.Changes = append(fd.Changes, FieldChange{Field: field[0], Old: field[1]})
+			}
+			d.Removed = append(d.Removed, fd)
+			continue
+		}
+
+		fd := FormDiff{Key: of.key}
+		for i, field := range of.fields {
+			if v := nf.fields[i][1]; v != field[1] {
+				fd.Changes = append(fd.Changes, FieldChange{Field: field[0], Old: field[1], New: v})
+			}
+		}
+		if len(fd.Changes) != 0 {
+			d.Changed = append(d.Changed, fd)
+		}
+	}
+
+	for _, nf := range newForms {
+		if oldKeys[nf.key] {
+			continue
+		}
+		fd := FormDiff{Key: nf.key}
+		for _, field := range nf.fields {
+			fd.Changes = append(fd.Changes, FieldChange{Field: field[0], New: field[1]})
+		}
+		d.Added = append(d.Added, fd)
+	}
+
+	return d
+}
+
+// writeDiff writes the changes of the instruction forms of m from the asmdb data file of the same name as the
+// embedded name file in the -diff directory as JSON to w.
+func writeDiff(w io.Writer, m *Model, name string) error {
+	file := filepath.Join(*flagDiff, path.Base(name))
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read diff data: %w", err)
+	}
+
+	var old *Model
+	switch m.Arch {
+	case "x86":
+		old, err = decodeX86Model(data)
+	case "arm":
+		old, err = decodeArmModel(data)
+	}
+	if err != nil {
+		return fmt.Errorf("diff %s: %w", file, err)
+	}
+
+	return writeJSON(w, m.Arch+" diff", Diff(old, m))
+}
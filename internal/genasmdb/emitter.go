@@ -0,0 +1,228 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// Model represents the parsed asmdb data of an architecture passed to the emitters and the user templates.
+//
+// Only the fields of the architecture are set. The methods of the instructions are callable from the template,
+// like {{range .ArmInstructions}}{{.ParseEncoding.Mask}}{{end}}.
+type Model struct {
+	// Arch is the architecture, "x86" or "arm".
+	Arch string
+
+	// Source is the asmdb data file with its checksum, like "asmdb/armdata.js (sha256:...)".
+	Source string
+
+	// Version is the upstream asmdb commit of the embedded data recorded by -update, like
+	// "asmjit/asmdb 0123abcd... 2021-01-02T03:04:05Z", or empty if it's unknown or the data is read by -x86-data or -arm-data.
+	Version string
+
+	// Provenance is the list of the fields of the forms set by the -patch files in the order applied.
+	Provenance []FieldSource
+
+	X86             *X86
+	X86Instructions []X86Instruction
+
+	Arm             *Arm
+	ArmInstructions []ArmInstruction
+}
+
+// Emitter is the output backend writing the parsed asmdb data of an architecture.
+type Emitter interface {
+	// Name returns the name of the output, like "x86-capstone", which is also the name of its flag.
+	Name() string
+
+	// Emit writes the output of m to w.
+	Emit(m *Model, w io.Writer) error
+}
+
+// emitterFunc is the Emitter calling the function.
+type emitterFunc struct {
+	name string
+	fn   func(m *Model, w io.Writer) error
+
+	// out is a value of the Go type of the JSON output, like []*ATTForm(nil), whose JSON Schema describes
+	// and checks the output, the *JSONSchema of the output itself, or nil if the output isn't JSON.
+	out interface{}
+}
+
+// Name implements Emitter.
+func (e *emitterFunc) Name() string {
+	return e.name
+}
+
+// Emit implements Emitter.
+func (e *emitterFunc) Emit(m *Model, w io.Writer) error {
+	return e.fn(m, w)
+}
+
+// schema returns the JSON Schema of the JSON output of e, or nil if the output isn't JSON.
+func (e *emitterFunc) schema() *JSONSchema {
+	switch out := e.out.(type) {
+	case nil:
+		return nil
+	case *JSONSchema:
+		return out
+	}
+	return newJSONSchema(e.name, e.out)
+}
+
+// registeredEmitter represents the emitter of an architecture with the flag of its output file.
+type registeredEmitter struct {
+	emitter Emitter
+	arch    string
+
+	// format and file are the output format and the default name of the output file in the -o directory,
+	// the output is written there if the format is selected by -format. The file is empty if the output
+	// is written only to the file of the flag.
+	format string
+	file   string
+
+	flag *string
+}
+
+// emitters is a list of the registered emitters in the registration order.
+var emitters []*registeredEmitter
+
+// registerEmitter registers the emitter of arch and defines the flag of its output file named by the emitter
+// with the usage. The output is also written as file of format into the -o directory if file isn't empty.
+//
+// It's called by the init of the file of the emitter, so each output backend is added independently.
+func registerEmitter(arch, format, file, usage string, e Emitter) {
+	emitters = append(emitters, &registeredEmitter{
+		emitter: e,
+		arch:    arch,
+		format:  format,
+		file:    file,
+		flag:    flag.String(e.Name(), "", usage),
+	})
+}
+
+// emit writes the outputs of the emitters of the architecture of m enabled by the flags.
+func emit(ctx context.Context, m *Model) error {
+	for _, r := range emitters {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if r.arch != m.Arch {
+			continue
+		}
+		name := *r.flag
+		if name == "" && r.file != "" {
+			name = outputPath("", r.arch, r.format, r.file)
+		}
+		if name == "" {
+			continue
+		}
+		var schema *JSONSchema
+		if e, ok := r.emitter.(*emitterFunc); ok {
+			schema = e.schema()
+		}
+		if err := writeFile(name, func(w io.Writer) error {
+			if schema == nil {
+				return r.emitter.Emit(m, w)
+			}
+			// the JSON output is checked against its schema before it's written
+			var buf bytes.Buffer
+			if err := r.emitter.Emit(m, &buf); err != nil {
+				return err
+			}
+			if err := validateJSON(buf.Bytes(), schema); err != nil {
+				return fmt.Errorf("schema: %w", err)
+			}
+			_, err := buf.WriteTo(w)
+			return err
+		}); err != nil {
+			return fmt.Errorf("write %s: %w", r.emitter.Name(), err)
+		}
+
+		// the schema is published next to the output in the -o directory, like "att.schema.json" of "att.json"
+		if schema != nil && *r.flag == "" {
+			name = strings.TrimSuffix(name, ".json") + ".schema.json"
+			if err := writeFile(name, func(w io.Writer) error {
+				return writeJSONSchema(w, schema)
+			}); err != nil {
+				return fmt.Errorf("write %s schema: %w", r.emitter.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeJSON writes v as the indented JSON to w, what names v in the error, like "x86 faults".
+func writeJSON(w io.Writer, what string, v interface{}) error {
+	opts := json.EncodeOptions{Indent: "\t"}
+	if err := (json.MarshalOptions{}).MarshalFull(opts, w, v); err != nil {
+		return fmt.Errorf("marshal %s: %w", what, err)
+	}
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// x86FormFunc returns the form written for the x86 instruction by the forms emitter, like a *FaultForm,
+// or nil if the instruction isn't written.
+type x86FormFunc func(x *X86, inst *X86Instruction) (interface{}, error)
+
+// armFormFunc returns the form written for the ARM instruction by the forms emitter, like a *FaultForm,
+// or nil if the instruction isn't written.
+type armFormFunc func(a *Arm, inst *ArmInstruction) (interface{}, error)
+
+// registerFormsEmitter registers the JSON emitter of the forms returned by the function of each architecture
+// for its instructions in their order, x86 or arm is nil if the architecture has no such forms.
+//
+// The emitter of an architecture is named by the architecture and name, like "x86-faults", and its usage
+// is formatted with the architecture. The out is a value of the Go type of the output, like []*FaultForm(nil).
+func registerFormsEmitter(name, file, usage string, out interface{}, x86 x86FormFunc, arm armFormFunc) {
+	for _, arch := range []string{"x86", "arm"} {
+		if arch == "x86" && x86 == nil || arch == "arm" && arm == nil {
+			continue
+		}
+		name := arch + "-" + name
+		registerEmitter(arch, "json", file, fmt.Sprintf(usage, arch), &emitterFunc{name: name, fn: func(m *Model, w io.Writer) error {
+			forms, err := modelForms(m, x86, arm)
+			if err != nil {
+				return err
+			}
+			return writeJSON(w, name, forms)
+		}, out: out})
+	}
+}
+
+// modelForms returns the forms returned by x86 and arm for the instructions of m in their order.
+func modelForms(m *Model, x86 x86FormFunc, arm armFormFunc) ([]interface{}, error) {
+	var forms []interface{}
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		form, err := x86(m.X86, inst)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+		}
+		if form != nil {
+			forms = append(forms, form)
+		}
+	}
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		form, err := arm(m.Arm, inst)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+		}
+		if form != nil {
+			forms = append(forms, form)
+		}
+	}
+	return forms, nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("faults", "faults.json", "write the fault conditions of the %s forms as JSON to `file`", []*FaultForm{}, x86FaultForm, armFaultForm)
+}
+
+// The conditions of the faults.
+const (
+	// FaultLock is the LOCK prefix of the x86 form which doesn't accept it.
+	FaultLock = "lock"
+	// FaultMode64 is the execution of the x86 form invalid in the 64-bit mode.
+	FaultMode64 = "mode-64"
+	// FaultModeLegacy is the execution of the x64 only form outside the 64-bit mode.
+	FaultModeLegacy = "mode-legacy"
+	// FaultPrivilege is the execution of the privileged form, the x86 CPL above 0 or the ARM User mode.
+	FaultPrivilege = "privilege"
+	// FaultIOPL is the execution of the x86 I/O or interrupt flag form with the CPL above the IOPL.
+	FaultIOPL = "iopl"
+	// FaultAlignment is the memory operand which isn't aligned as the form requires.
+	FaultAlignment = "alignment"
+	// FaultAlignmentCheck is the unaligned memory operand of the x86 form with the alignment checking
+	// enabled by the AC flag and CR0.AM at the CPL 3.
+	FaultAlignmentCheck = "alignment-check"
+	// FaultCanonical is the non-canonical address of the memory operand of the x86 form in the 64-bit mode.
+	FaultCanonical = "canonical"
+	// FaultITBlock is the ARM form which must be outside of the IT block, or the last instruction of it, inside it.
+	FaultITBlock = "it-block"
+	// FaultConstraint is the ARM operand which breaks its constraint, like "Rd!=PC".
+	FaultConstraint = "constraint"
+)
+
+// Fault represents a condition faulting an instruction form at the decode or the execution.
+type Fault struct {
+	// Exception is the raised exception, like "#UD" and "#GP" of the x86 forms, or the behavior of the ARM form,
+	// "UNDEFINED", "UNPREDICTABLE" or "ALIGNMENT" for the alignment fault.
+	Exception string `json:"exception"`
+
+	// Condition is the condition of the fault, like FaultLock and FaultAlignment.
+	Condition string `json:"condition"`
+
+	// Operand is the index of the operand the condition applies to, or -1 for the form,
+	// like the stack of the x86 "push" for FaultCanonical.
+	Operand int `json:"operand"`
+}
+
+// x86PrivilegedNames is the curated list of the forms requiring the CPL 0.
+var x86PrivilegedNames = map[string]bool{
+	"clts": true, "hlt": true, "invd": true, "invlpg": true, "invpcid": true, "wbinvd": true, "wbnoinvd": true,
+	"lgdt": true, "lidt": true, "lldt": true, "ltr": true, "lmsw": true,
+	"rdmsr": true, "wrmsr": true, "swapgs": true, "sysexit": true, "sysexitq": true, "sysret": true, "sysretq": true,
+	"xsaves": true, "xsaves64": true, "xrstors": true, "xrstors64": true, "enqcmds": true, "rsm": true,
+}
+
+// x86PrivilegedExtensions is the list of the extensions whose forms require the CPL 0, like the VMX and SVM forms.
+var x86PrivilegedExtensions = map[string]bool{
+	"VMX": true, "SVM": true, "SKINIT": true, "SEAM": true, "SNP": true, "PCONFIG": true,
+}
+
+// x86IOPLNames is the list of the forms requiring the CPL at most the IOPL.
+var x86IOPLNames = map[string]bool{
+	"in": true, "out": true, "insb": true, "insw": true, "insd": true, "outsb": true, "outsw": true, "outsd": true,
+	"cli": true, "sti": true,
+}
+
+// Faults returns the conditions faulting the instruction, in the order of the conditions of the form and then of its operands.
+//
+// The LOCK prefix is derived from the eligibility of the form, the mode from the metadata, the privilege from the curated names and extensions,
+// like "rdmsr" and "VMX", and "mov" of the control and debug registers, and the memory operands from the memory
+// accesses and the alignment of the form.
+func (x *X86) Faults(inst *X86Instruction) ([]*Fault, error) {
+	name := inst.Names()[0]
+	meta := x.ParseMetadata(inst.Metadata)
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	var faults []*Fault
+	add := func(exception, cond string, operand int) {
+		faults = append(faults, &Fault{Exception: exception, Condition: cond, Operand: operand})
+	}
+
+	lock, err := x.LockPrefix(inst)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		add("#UD", FaultLock, -1)
+	}
+	switch {
+	case containsString(meta.Architectures, "X86") && !containsString(meta.Architectures, "X64"):
+		add("#UD", FaultMode64, -1)
+	case containsString(meta.Architectures, "X64") && !containsString(meta.Architectures, "X86"):
+		add("#UD", FaultModeLegacy, -1)
+	}
+
+	privileged := x86PrivilegedNames[name]
+	for _, ext := range meta.Extensions {
+		privileged = privileged || x86PrivilegedExtensions[ext]
+	}
+	for _, op := range ops {
+		for _, kind := range op.Kinds {
+			switch x86RegClass(kind) {
+			case "creg", "dreg":
+				privileged = true
+			}
+		}
+	}
+	if privileged {
+		add("#GP", FaultPrivilege, -1)
+	}
+	if x86IOPLNames[name] {
+		add("#GP", FaultIOPL, -1)
+	}
+
+	accesses, err := x.MemoryAccesses(inst)
+	if err != nil {
+		return nil, err
+	}
+	align, err := x.Alignment(inst)
+	if err != nil {
+		return nil, err
+	}
+	for _, access := range accesses {
+		if align != nil && align.Operand == access.Operand {
+			add("#GP", FaultAlignment, access.Operand)
+		}
+		if len(access.Widths) != 1 || access.Widths[0] != 8 {
+			// the bytes are always aligned
+			add("#AC", FaultAlignmentCheck, access.Operand)
+		}
+		if access.Operand < 0 {
+			// the stack accesses
+			add("#SS", FaultCanonical, access.Operand)
+		} else {
+			add("#GP", FaultCanonical, access.Operand)
+		}
+	}
+	return faults, nil
+}
+
+// armPrivilegedNames is the curated list of the forms which are UNDEFINED in the User mode.
+var armPrivilegedNames = map[string]bool{
+	"eret": true, "hvc": true, "smc": true,
+	"rfe": true, "rfeda": true, "rfedb": true, "rfeib": true,
+	"srs": true, "srsda": true, "srsdb": true, "srsib": true,
+}
+
+// Faults returns the conditions faulting the instruction, in the order of the conditions of the form and then of its operands.
+//
+// The IT block is derived from the "IT" attribute of the metadata, the privilege from the curated names, like "eret",
+// and the operands from their constraints and the alignment of the form.
+func (a *Arm) Faults(inst *ArmInstruction) ([]*Fault, error) {
+	mnemonic, _ := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	meta := a.ParseMetadata(inst.Metadata)
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	var faults []*Fault
+	add := func(exception, cond string, operand int) {
+		faults = append(faults, &Fault{Exception: exception, Condition: cond, Operand: operand})
+	}
+
+	if strings.Contains(meta.Attributes["IT"], "OUT") {
+		add("UNPREDICTABLE", FaultITBlock, -1)
+	}
+	if armPrivilegedNames[mnemonic] {
+		add("UNDEFINED", FaultPrivilege, -1)
+	}
+
+	align, err := a.Alignment(inst)
+	if err != nil {
+		return nil, err
+	}
+	if align != nil {
+		add("ALIGNMENT", FaultAlignment, align.Operand)
+	}
+	for _, op := range ops {
+		constrained := len(op.Constraints) > 0
+		for _, elem := range op.Mem {
+			constrained = constrained || len(elem.Constraints) > 0
+		}
+		if constrained {
+			add("UNPREDICTABLE", FaultConstraint, op.Index)
+		}
+	}
+	return faults, nil
+}
+
+// FaultForm represents an instruction form and its fault conditions.
+type FaultForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "RM", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode string   `json:"opcode"`
+	Faults []*Fault `json:"faults"`
+}
+
+// x86FaultForm returns the form of inst if it has fault conditions, or nil.
+func x86FaultForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	faults, err := x.Faults(inst)
+	if err != nil || len(faults) == 0 {
+		return nil, err
+	}
+	return &FaultForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Faults: faults}, nil
+}
+
+// armFaultForm returns the form of inst if it has fault conditions, or nil.
+func armFaultForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	faults, err := a.Faults(inst)
+	if err != nil || len(faults) == 0 {
+		return nil, err
+	}
+	return &FaultForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Faults: faults}, nil
+}
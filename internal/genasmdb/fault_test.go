@@ -0,0 +1,120 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86Faults(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     []*Fault
+	}{
+		// the LOCK prefix and the memory operands
+		{"add", "X:~r32/m32,~r32", []*Fault{
+			{Exception: "#AC", Condition: FaultAlignmentCheck, Operand: 0},
+			{Exception: "#GP", Condition: FaultCanonical, Operand: 0},
+		}},
+		{"add", "x:~r8/m8,~r8", []*Fault{{Exception: "#GP", Condition: FaultCanonical, Operand: 0}}},
+		{"movaps", "W:xmm, xmm/m128", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#GP", Condition: FaultAlignment, Operand: 1},
+			{Exception: "#AC", Condition: FaultAlignmentCheck, Operand: 1},
+			{Exception: "#GP", Condition: FaultCanonical, Operand: 1},
+		}},
+		// the mode and the stack
+		{"push", "R:r32/m32", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#UD", Condition: FaultMode64, Operand: -1},
+			{Exception: "#AC", Condition: FaultAlignmentCheck, Operand: 0},
+			{Exception: "#GP", Condition: FaultCanonical, Operand: 0},
+			{Exception: "#AC", Condition: FaultAlignmentCheck, Operand: -1},
+			{Exception: "#SS", Condition: FaultCanonical, Operand: -1},
+		}},
+		// x86PrivilegedNames
+		{"rdmsr", "W:<edx>, W:<eax>, R:<ecx>", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#GP", Condition: FaultPrivilege, Operand: -1},
+		}},
+		{"swapgs", "", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#UD", Condition: FaultModeLegacy, Operand: -1},
+			{Exception: "#GP", Condition: FaultPrivilege, Operand: -1},
+		}},
+		// x86PrivilegedExtensions
+		{"vmxon", "R:m64", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#GP", Condition: FaultPrivilege, Operand: -1},
+			{Exception: "#AC", Condition: FaultAlignmentCheck, Operand: 0},
+			{Exception: "#GP", Condition: FaultCanonical, Operand: 0},
+		}},
+		// the control and the debug registers
+		{"mov", "W:creg, r64", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#UD", Condition: FaultModeLegacy, Operand: -1},
+			{Exception: "#GP", Condition: FaultPrivilege, Operand: -1},
+		}},
+		{"mov", "W:r32, dreg", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#UD", Condition: FaultMode64, Operand: -1},
+			{Exception: "#GP", Condition: FaultPrivilege, Operand: -1},
+		}},
+		// x86IOPLNames
+		{"in", "w:al, ib/ub", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#GP", Condition: FaultIOPL, Operand: -1},
+		}},
+		{"cli", "", []*Fault{
+			{Exception: "#UD", Condition: FaultLock, Operand: -1},
+			{Exception: "#GP", Condition: FaultIOPL, Operand: -1},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.Faults(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Faults(%s %s) = %s, %v, want %s", tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
+
+func TestArmFaults(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     []*Fault
+	}{
+		// the IT block
+		{"b", "#RelS*2", ArmT16, []*Fault{{Exception: "UNPREDICTABLE", Condition: FaultITBlock, Operand: -1}}},
+		{"cbz", "Rn!=HI, #RelZ*2", ArmT16, []*Fault{
+			{Exception: "UNPREDICTABLE", Condition: FaultITBlock, Operand: -1},
+			{Exception: "UNPREDICTABLE", Condition: FaultConstraint, Operand: 0},
+		}},
+		// armPrivilegedNames
+		{"eret", "", ArmA32, []*Fault{{Exception: "UNDEFINED", Condition: FaultPrivilege, Operand: -1}}},
+		{"srsdb", "[Rn==SP]{!}, #Mode", ArmA32, []*Fault{
+			{Exception: "UNDEFINED", Condition: FaultPrivilege, Operand: -1},
+			{Exception: "ALIGNMENT", Condition: FaultAlignment, Operand: 0},
+			{Exception: "UNPREDICTABLE", Condition: FaultConstraint, Operand: 0},
+		}},
+		// the alignment and the constraints
+		{"ldrex", "Rd!=PC, [Rn!=PC]", ArmA32, []*Fault{
+			{Exception: "ALIGNMENT", Condition: FaultAlignment, Operand: 1},
+			{Exception: "UNPREDICTABLE", Condition: FaultConstraint, Operand: 0},
+			{Exception: "UNPREDICTABLE", Condition: FaultConstraint, Operand: 1},
+		}},
+		{"adc", "Rd    , Rn    , #ImmA", ArmA32, nil},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.Faults(testArmForm(t, tt.name, tt.operands, tt.arch))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Faults(%s %s %s) = %s, %v, want %s", tt.arch, tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("ordering", "ordering.json", "write the serializing and fencing %s forms and their ordering as JSON to `file`", []*OrderingForm{}, x86OrderingForm, armOrderingForm)
+}
+
+// The kinds of the memory fences.
+const (
+	// FenceLoad orders the prior loads before the later loads, like "lfence".
+	FenceLoad = "load"
+	// FenceStore orders the prior stores before the later stores, like "sfence".
+	FenceStore = "store"
+	// FenceFull orders all prior memory accesses before the later ones, like "mfence", the locked forms and "dmb".
+	FenceFull = "full"
+	// FenceAcquire orders the access of the form before the later memory accesses, like "lda".
+	FenceAcquire = "acquire"
+	// FenceRelease orders the prior memory accesses before the access of the form, like "stl".
+	FenceRelease = "release"
+)
+
+// Ordering represents the serializing and the memory ordering behavior of an instruction form.
+type Ordering struct {
+	// Serializing reports whether the form is architecturally serializing, completing all prior instructions
+	// and draining the stores before the next instruction is fetched, like "cpuid" and "isb".
+	Serializing bool `json:"serializing,omitzero"`
+
+	// DispatchSerializing reports whether the later instructions don't execute before the prior instructions
+	// complete locally, like "lfence", "rdtscp" and "dsb".
+	DispatchSerializing bool `json:"dispatchSerializing,omitzero"`
+
+	// Fence is the kind of the memory fence of the form, one of FenceLoad, FenceStore, FenceFull, FenceAcquire
+	// and FenceRelease, or empty if the form doesn't order memory.
+	Fence string `json:"fence,omitzero"`
+}
+
+// x86SerializingNames is the curated list of the architecturally serializing forms.
+// The moves to the control and debug registers are serializing too.
+var x86SerializingNames = map[string]bool{
+	"cpuid": true, "serialize": true, "iret": true, "iretd": true, "iretq": true, "rsm": true,
+	"invd": true, "wbinvd": true, "wbnoinvd": true, "invlpg": true, "invpcid": true, "invept": true, "invvpid": true,
+	"lgdt": true, "lidt": true, "lldt": true, "ltr": true, "wrmsr": true, "xsetbv": true,
+}
+
+// x86FenceNames is the curated fence of the fence forms.
+var x86FenceNames = map[string]string{
+	"lfence": FenceLoad,
+	"sfence": FenceStore,
+	"mfence": FenceFull,
+}
+
+// x86DispatchSerializingNames is the list of the dispatch serializing forms which aren't serializing.
+var x86DispatchSerializingNames = map[string]bool{
+	"lfence": true, "rdtscp": true,
+}
+
+// Ordering returns the serializing and the memory ordering behavior of the instruction, or nil if it has none.
+//
+// The serializing forms are full fences, and so are the implicitly locked forms, like "xchg" of the memory.
+// The forms accepting the LOCK prefix only fence with the prefix, so they aren't listed.
+func (x *X86) Ordering(inst *X86Instruction) (*Ordering, error) {
+	name := inst.Names()[0]
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Ordering{Serializing: x86SerializingNames[name], DispatchSerializing: x86DispatchSerializingNames[name], Fence: x86FenceNames[name]}
+	for _, op := range ops {
+		for _, kind := range op.Kinds {
+			switch x86RegClass(kind) {
+			case "creg", "dreg":
+				o.Serializing = o.Serializing || op.IsWrite()
+			}
+		}
+	}
+	if _, ok := x.ParseMetadata(inst.Metadata).Attributes["ImplicitLock"]; ok {
+		for _, op := range ops {
+			if op.IsMem() {
+				o.Fence = FenceFull
+			}
+		}
+	}
+	if o.Serializing {
+		o.DispatchSerializing, o.Fence = true, FenceFull
+	}
+	if *o == (Ordering{}) {
+		return nil, nil
+	}
+	return o, nil
+}
+
+// Ordering returns the serializing and the memory ordering behavior of the instruction, or nil if it has none.
+//
+// The barriers "dmb" and "dsb" are full fences of the default option, "isb" and "eret" are context synchronizing,
+// and the acquire and release forms, like "ldaex" and "stl", are one-way fences.
+func (a *Arm) Ordering(inst *ArmInstruction) (*Ordering, error) {
+	mnemonic, _ := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	switch {
+	case mnemonic == "dmb":
+		return &Ordering{Fence: FenceFull}, nil
+	case mnemonic == "dsb":
+		return &Ordering{DispatchSerializing: true, Fence: FenceFull}, nil
+	case mnemonic == "isb", mnemonic == "eret":
+		return &Ordering{Serializing: true, DispatchSerializing: true}, nil
+	case strings.HasPrefix(mnemonic, "lda"):
+		return &Ordering{Fence: FenceAcquire}, nil
+	case strings.HasPrefix(mnemonic, "stl"):
+		return &Ordering{Fence: FenceRelease}, nil
+	}
+	return nil, nil
+}
+
+// OrderingForm represents a serializing or fencing instruction form.
+type OrderingForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "MR", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode   string    `json:"opcode"`
+	Ordering *Ordering `json:"ordering"`
+}
+
+// x86OrderingForm returns the form of inst if it serializes or fences, or nil.
+func x86OrderingForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	o, err := x.Ordering(inst)
+	if err != nil || o == nil {
+		return nil, err
+	}
+	return &OrderingForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Ordering: o}, nil
+}
+
+// armOrderingForm returns the form of inst if it serializes or fences, or nil.
+func armOrderingForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	o, err := a.Ordering(inst)
+	if err != nil || o == nil {
+		return nil, err
+	}
+	return &OrderingForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Ordering: o}, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86Ordering(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *Ordering
+	}{
+		// x86SerializingNames
+		{"cpuid", "X:<eax>, W:<ebx>, X:<ecx>, W:<edx>", &Ordering{Serializing: true, DispatchSerializing: true, Fence: FenceFull}},
+		{"wrmsr", "R:<edx>, R:<eax>, R:<ecx>", &Ordering{Serializing: true, DispatchSerializing: true, Fence: FenceFull}},
+		// the moves to the control and the debug registers
+		{"mov", "W:creg, r64", &Ordering{Serializing: true, DispatchSerializing: true, Fence: FenceFull}},
+		{"mov", "W:r64, creg", nil},
+		// x86FenceNames and x86DispatchSerializingNames
+		{"lfence", "", &Ordering{DispatchSerializing: true, Fence: FenceLoad}},
+		{"sfence", "", &Ordering{Fence: FenceStore}},
+		{"mfence", "", &Ordering{Fence: FenceFull}},
+		{"rdtscp", "W:<edx>, W:<eax>, W:<ecx>", &Ordering{DispatchSerializing: true}},
+		// the implicitly locked forms
+		{"xchg", "X:~r32/m32, X:~r32", &Ordering{Fence: FenceFull}},
+		{"xchg", "X:~eax, X:~r32", nil},
+		{"add", "X:~r32/m32,~r32", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.Ordering(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ordering(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
+
+func TestArmOrdering(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     *Ordering
+	}{
+		{"dmb", "#ImmZ", ArmT32, &Ordering{Fence: FenceFull}},
+		{"dsb", "#ImmZ", ArmA32, &Ordering{DispatchSerializing: true, Fence: FenceFull}},
+		{"isb", "#ImmZ", ArmA32, &Ordering{Serializing: true, DispatchSerializing: true}},
+		{"eret", "", ArmA32, &Ordering{Serializing: true, DispatchSerializing: true}},
+		// the acquire and the release forms
+		{"lda", "Rd!=PC, [Rn!=PC]", ArmA32, &Ordering{Fence: FenceAcquire}},
+		{"ldaex", "Rd!=PC, [Rn!=PC]", ArmT32, &Ordering{Fence: FenceAcquire}},
+		{"stl", "Rs!=PC, [Rn!=PC]", ArmA32, &Ordering{Fence: FenceRelease}},
+		{"ldr", "Rd!=HI, [Rn!=HI, #ImmZ*4]", ArmT16, nil},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.Ordering(testArmForm(t, tt.name, tt.operands, tt.arch))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ordering(%s %s %s) = %+v, %v, want %+v", tt.arch, tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("fpexceptions", "fpexceptions.json", "write the floating-point exceptions of the %s forms as JSON to `file`", []*FPExceptionForm{}, x86FPExceptionForm, armFPExceptionForm)
+}
+
+// fpExceptionSet is the set of the floating-point exceptions, the bits of fpExceptionNames.
+type fpExceptionSet uint8
+
+const (
+	fpInvalid fpExceptionSet = 1 << iota
+	fpDenormal
+	fpZeroDivide
+	fpOverflow
+	fpUnderflow
+	fpPrecision
+
+	// fpArith is the set of the rounded arithmetic, like "addps" and "fmul".
+	fpArith = fpInvalid | fpDenormal | fpOverflow | fpUnderflow | fpPrecision
+)
+
+// fpExceptionNames is the names of the exceptions of fpExceptionSet, the names of the exception flags
+// of the x87 status word in x86data.js, like "X87SW.INVALID_OP".
+var fpExceptionNames = []string{"INVALID_OP", "DENORMAL", "ZERO_DIVIDE", "OVERFLOW", "UNDERFLOW", "PRECISION"}
+
+// names returns the names of the exceptions of s in the order of fpExceptionNames.
+func (s fpExceptionSet) names() []string {
+	names := []string{}
+	for i, name := range fpExceptionNames {
+		if s&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fpExceptionRule is the exceptions of the forms whose name matches re.
+type fpExceptionRule struct {
+	re  *regexp.Regexp
+	set fpExceptionSet
+}
+
+// x86SIMDFPExceptions is the curated exceptions of the SSE and AVX floating-point forms, the first matching rule applies.
+// The forms matching no rule are rounded arithmetic. The doubleword conversions to double precision are exact.
+var x86SIMDFPExceptions = []fpExceptionRule{
+	{regexp.MustCompile(`^v(rcp28|rsqrt28)`), fpInvalid | fpZeroDivide},
+	{regexp.MustCompile(`^vexp2`), fpInvalid | fpOverflow},
+	{regexp.MustCompile(`^v?(rcp|rsqrt)|bf16`), 0},
+	{regexp.MustCompile(`^v?(and|or|xor|mov|blend|shuf|unpck|perm|extract|insert|broadcast|compress|expand|gather|scatter|maskmov|fpclass|test|ldmxcsr|stmxcsr)`), 0},
+	{regexp.MustCompile(`div`), fpArith | fpZeroDivide},
+	{regexp.MustCompile(`sqrt`), fpInvalid | fpDenormal | fpPrecision},
+	{regexp.MustCompile(`^v?(min|max|cmp|comi|ucomi|range|getexp|getmant)`), fpInvalid | fpDenormal},
+	{regexp.MustCompile(`^v?cvtt?(ss|sd|ps|pd|sh|ph)2(u?si|u?dq|u?qq|pi|u?w)`), fpInvalid | fpPrecision},
+	{regexp.MustCompile(`^v?cvt(u?dq|pi)2pd$`), 0},
+	{regexp.MustCompile(`^v?cvtt?(u?si|u?dq|u?qq|pi|u?w)2`), fpPrecision},
+	{regexp.MustCompile(`^v?cvt(ss2sd|ps2pd|ph2psx?|sh2ss|sh2sd|ph2pd)`), fpInvalid | fpDenormal},
+	{regexp.MustCompile(`^v?(round|rndscale)`), fpInvalid | fpPrecision},
+	{regexp.MustCompile(`^vreduce`), fpInvalid | fpDenormal | fpPrecision},
+	{regexp.MustCompile(`^vfixupimm`), fpInvalid | fpZeroDivide},
+}
+
+// x87FPExceptions is the curated exceptions of the x87 forms, the first matching rule applies.
+// The forms matching no rule are rounded arithmetic. The stack faults are invalid operations.
+var x87FPExceptions = []fpExceptionRule{
+	{regexp.MustCompile(`^(f(n?clex|n?init|ldcw|n?stcw|n?stsw|n?save|rstor|n?stenv|ldenv|free|incstp|decstp|nop|wait|xam)|wait|emms)$`), 0},
+	{regexp.MustCompile(`^f(xch|chs|abs|cmov|ild|bld|ld[1z]|ldpi|ldl2[et]|ldlg2|ldln2)`), fpInvalid},
+	{regexp.MustCompile(`^fld$`), fpInvalid | fpDenormal},
+	{regexp.MustCompile(`^fstp?$`), fpInvalid | fpOverflow | fpUnderflow | fpPrecision},
+	{regexp.MustCompile(`^f(istt?p?|bstp)$`), fpInvalid | fpPrecision},
+	{regexp.MustCompile(`^f(u?comi?p*|icomp?|tst)$`), fpInvalid | fpDenormal},
+	{regexp.MustCompile(`^fi?div`), fpArith | fpZeroDivide},
+	{regexp.MustCompile(`^f(sqrt|rndint)$`), fpInvalid | fpDenormal | fpPrecision},
+	{regexp.MustCompile(`^fprem1?$`), fpInvalid | fpDenormal | fpUnderflow},
+	{regexp.MustCompile(`^fxtract$`), fpInvalid | fpDenormal | fpZeroDivide},
+	{regexp.MustCompile(`^fyl2x$`), fpArith | fpZeroDivide},
+	{regexp.MustCompile(`^f(sin|cos|sincos|ptan|patan|2xm1|yl2xp1)$`), fpInvalid | fpDenormal | fpUnderflow | fpPrecision},
+}
+
+// armFPExceptions is the curated exceptions of the VFP and ASIMD floating-point forms of the lowercased mnemonics,
+// the first matching rule applies. The forms matching no rule are rounded arithmetic.
+var armFPExceptions = []fpExceptionRule{
+	{regexp.MustCompile(`^v(mov|abs|neg|sel|dup|ext|swp|tbl|tbx|trn|uzp|zip|rev)`), 0},
+	{regexp.MustCompile(`^vdiv$`), fpArith | fpZeroDivide},
+	{regexp.MustCompile(`^vsqrt$`), fpInvalid | fpDenormal | fpPrecision},
+	{regexp.MustCompile(`^v(cmpe?|ceq|cge|cgt|cle|clt|acge|acgt|acle|aclt|max|min|pmax|pmin|maxnm|minnm)$`), fpInvalid | fpDenormal},
+	{regexp.MustCompile(`^v(recpe|rsqrte)$`), fpInvalid | fpZeroDivide},
+	{regexp.MustCompile(`^vrintx$`), fpInvalid | fpPrecision},
+	{regexp.MustCompile(`^vrint`), fpInvalid},
+}
+
+// FPExceptions represents the floating-point exceptions an instruction form may raise.
+type FPExceptions struct {
+	// Exceptions is the list of the exceptions, like "INVALID_OP" and "PRECISION", in the order of the flags.
+	// It's empty if the form raises none, like the moves and the logical operations.
+	Exceptions []string `json:"exceptions"`
+
+	// Status and Control are the registers of the exception flags and of the masks and the rounding mode,
+	// "X87SW" and "X87CW" of the x87 forms, "MXCSR" of the SSE and AVX forms and "FPSCR" of the ARM forms.
+	Status  string `json:"status"`
+	Control string `json:"control"`
+
+	// Suppressed reports whether the EVEX form can suppress all exceptions, with the "{sae}" or "{er}" decorator.
+	Suppressed bool `json:"suppressed,omitzero"`
+}
+
+// matchFPExceptions returns the exceptions of the first rule of rules matching the name, or fpArith.
+func matchFPExceptions(rules []fpExceptionRule, name string) fpExceptionSet {
+	for _, rule := range rules {
+		if rule.re.MatchString(name) {
+			return rule.set
+		}
+	}
+	return fpArith
+}
+
+// FPExceptions returns the floating-point exceptions the instruction may raise, or nil if it isn't a x87, SSE
+// or AVX floating-point form. The 3DNow! forms don't report exceptions.
+func (x *X86) FPExceptions(inst *X86Instruction) (*FPExceptions, error) {
+	meta := x.ParseMetadata(inst.Metadata)
+	if meta.HasExtension("3DNOW") || meta.HasExtension("3DNOW2") || meta.HasExtension("AMX_BF16") {
+		return nil, nil
+	}
+	name := inst.Names()[0]
+	switch x.Category(inst) {
+	case CategoryFP:
+		return &FPExceptions{Exceptions: matchFPExceptions(x87FPExceptions, name).names(), Status: "X87SW", Control: "X87CW"}, nil
+	case CategorySIMDFP:
+		ops, err := inst.ParseOperands()
+		if err != nil {
+			return nil, err
+		}
+		fe := &FPExceptions{Exceptions: matchFPExceptions(x86SIMDFPExceptions, name).names(), Status: "MXCSR", Control: "MXCSR"}
+		for _, op := range ops {
+			for _, deco := range op.Decorators {
+				fe.Suppressed = fe.Suppressed || deco == "{sae}" || deco == "{er}"
+			}
+		}
+		return fe, nil
+	}
+	return nil, nil
+}
+
+// FPExceptions returns the floating-point exceptions the instruction may raise, or nil if it isn't a VFP
+// or ASIMD floating-point form.
+//
+// The exceptions of "vcvt" depend on the data types, like "vcvt.s32.f32" converting to the integer.
+func (a *Arm) FPExceptions(inst *ArmInstruction) (*FPExceptions, error) {
+	switch a.Category(inst) {
+	case CategoryFP, CategorySIMDFP:
+	default:
+		return nil, nil
+	}
+
+	mnemonic, suffixes := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	set := matchFPExceptions(armFPExceptions, mnemonic)
+	if strings.HasPrefix(mnemonic, "vcvt") && len(suffixes) >= 2 {
+		dst, src := strings.ToLower(suffixes[0]), strings.ToLower(suffixes[1])
+		switch {
+		case !strings.HasPrefix(dst, "f"):
+			set = fpInvalid | fpPrecision
+		case !strings.HasPrefix(src, "f"):
+			set = fpPrecision
+		}
+	}
+	return &FPExceptions{Exceptions: set.names(), Status: "FPSCR", Control: "FPSCR"}, nil
+}
+
+// FPExceptionForm represents a floating-point instruction form and its exceptions.
+type FPExceptionForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "RVM", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode     string        `json:"opcode"`
+	Exceptions *FPExceptions `json:"fp"`
+}
+
+// x86FPExceptionForm returns the form of inst if it raises floating-point exceptions, or nil.
+func x86FPExceptionForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	fe, err := x.FPExceptions(inst)
+	if err != nil || fe == nil {
+		return nil, err
+	}
+	return &FPExceptionForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Exceptions: fe}, nil
+}
+
+// armFPExceptionForm returns the form of inst if it raises floating-point exceptions, or nil.
+func armFPExceptionForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	fe, err := a.FPExceptions(inst)
+	if err != nil || fe == nil {
+		return nil, err
+	}
+	return &FPExceptionForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Exceptions: fe}, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchFPExceptions(t *testing.T) {
+	tests := []struct {
+		rules string
+		name  string
+		want  fpExceptionSet
+	}{
+		// x86SIMDFPExceptions
+		{"x86", "vrcp28ps", fpInvalid | fpZeroDivide},
+		{"x86", "vexp2pd", fpInvalid | fpOverflow},
+		{"x86", "rsqrtps", 0},
+		{"x86", "vmovaps", 0},
+		{"x86", "divsd", fpArith | fpZeroDivide},
+		{"x86", "vsqrtpd", fpInvalid | fpDenormal | fpPrecision},
+		{"x86", "comiss", fpInvalid | fpDenormal},
+		{"x86", "cvttsd2si", fpInvalid | fpPrecision},
+		{"x86", "vcvtudq2pd", 0},
+		{"x86", "cvtsi2ss", fpPrecision},
+		{"x86", "cvtss2sd", fpInvalid | fpDenormal},
+		{"x86", "roundps", fpInvalid | fpPrecision},
+		{"x86", "vreducesd", fpInvalid | fpDenormal | fpPrecision},
+		{"x86", "vfixupimmps", fpInvalid | fpZeroDivide},
+		{"x86", "addps", fpArith},
+		// x87FPExceptions
+		{"x87", "fnstsw", 0},
+		{"x87", "emms", 0},
+		{"x87", "fchs", fpInvalid},
+		{"x87", "fld", fpInvalid | fpDenormal},
+		{"x87", "fstp", fpInvalid | fpOverflow | fpUnderflow | fpPrecision},
+		{"x87", "fistp", fpInvalid | fpPrecision},
+		{"x87", "fucomip", fpInvalid | fpDenormal},
+		{"x87", "fidivr", fpArith | fpZeroDivide},
+		{"x87", "fsqrt", fpInvalid | fpDenormal | fpPrecision},
+		{"x87", "fprem1", fpInvalid | fpDenormal | fpUnderflow},
+		{"x87", "fxtract", fpInvalid | fpDenormal | fpZeroDivide},
+		{"x87", "fyl2x", fpArith | fpZeroDivide},
+		{"x87", "fyl2xp1", fpInvalid | fpDenormal | fpUnderflow | fpPrecision},
+		{"x87", "fmul", fpArith},
+		// armFPExceptions
+		{"arm", "vneg", 0},
+		{"arm", "vdiv", fpArith | fpZeroDivide},
+		{"arm", "vsqrt", fpInvalid | fpDenormal | fpPrecision},
+		{"arm", "vcmpe", fpInvalid | fpDenormal},
+		{"arm", "vrecpe", fpInvalid | fpZeroDivide},
+		{"arm", "vrintx", fpInvalid | fpPrecision},
+		{"arm", "vrintz", fpInvalid},
+		{"arm", "vfma", fpArith},
+	}
+	rules := map[string][]fpExceptionRule{"x86": x86SIMDFPExceptions, "x87": x87FPExceptions, "arm": armFPExceptions}
+	for _, tt := range tests {
+		if got := matchFPExceptions(rules[tt.rules], tt.name); got != tt.want {
+			t.Errorf("%s exceptions of %s = %q, want %q", tt.rules, tt.name, got.names(), tt.want.names())
+		}
+	}
+}
+
+func TestX86FPExceptions(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *FPExceptions
+	}{
+		{"fadd", "R:m32fp", &FPExceptions{Exceptions: fpArith.names(), Status: "X87SW", Control: "X87CW"}},
+		{"addps", "X:~xmm, ~xmm/m128", &FPExceptions{Exceptions: fpArith.names(), Status: "MXCSR", Control: "MXCSR"}},
+		{"vaddps", "W:zmm {kz},~zmm,~zmm/m512/b32 {er}", &FPExceptions{Exceptions: fpArith.names(), Status: "MXCSR", Control: "MXCSR", Suppressed: true}},
+		{"paddd", "X:~xmm, ~xmm/m128", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.FPExceptions(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FPExceptions(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
+
+func TestArmFPExceptions(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     fpExceptionSet
+	}{
+		{"vadd.f32", "Sd, Sn, Sm", ArmA32, fpArith},
+		{"vadd.f32", "Vd, Vn, Vm", ArmA32, fpArith},
+		// the conversions to and from the integers
+		{"vcvta.s32.f32", "Sd, Sn", ArmA32, fpInvalid | fpPrecision},
+		{"vcvt.f32.s32", "Sd, Sn", ArmA32, fpPrecision},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.FPExceptions(testArmForm(t, tt.name, tt.operands, tt.arch))
+		want := &FPExceptions{Exceptions: tt.want.names(), Status: "FPSCR", Control: "FPSCR"}
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("FPExceptions(%s %s %s) = %+v, %v, want %+v", tt.arch, tt.name, tt.operands, got, err, want)
+		}
+	}
+
+	got, err := arm.Arm.FPExceptions(testArmForm(t, "vadd.x8-64", "Dd, Dn, Dm", ArmA32))
+	if err != nil || got != nil {
+		t.Errorf("FPExceptions(A32 vadd.x8-64 Dd, Dn, Dm) = %+v, %v, want nil", got, err)
+	}
+}
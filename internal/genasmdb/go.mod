@@ -5,4 +5,5 @@ go 1.16
 require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/go-json-experiment/json v0.0.0-20210812092850-7635db4ea421
+	golang.org/x/sync v0.0.0-20220907140024-f12130a52804
 )
@@ -0,0 +1,134 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var flagGolden = flag.Bool("golden", false, "rewrite the golden outputs in testdata/golden")
+
+// goldenDir is the directory of the outputs generated from the test data.
+const goldenDir = "testdata/golden"
+
+// setFlags sets the flags of the generator to the values and restores them at the end of the test.
+func setFlags(t testing.TB, values map[string]string) {
+	for name, value := range values {
+		f := flag.Lookup(name)
+		if f == nil {
+			t.Fatalf("flag -%s isn't defined", name)
+		}
+		old := f.Value.String()
+		if err := flag.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			flag.Set(name, old)
+		})
+	}
+}
+
+// genTestData generates the outputs of the formats of the test data into dir.
+func genTestData(t testing.TB, dir, formats string) {
+	setFlags(t, map[string]string{
+		"x86-data": "testdata/x86data.js",
+		"arm-data": "testdata/armdata.js",
+		"o":        dir,
+		"format":   formats,
+	})
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(w)
+
+	if err := gen(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTree returns the contents of the files in dir by their slash separated path relative to dir.
+func readTree(t testing.TB, dir string) map[string][]byte {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// compareTrees reports the files missing from, added to or different in got from want.
+func compareTrees(t *testing.T, got, want map[string][]byte) {
+	for name, data := range want {
+		g, ok := got[name]
+		switch {
+		case !ok:
+			t.Errorf("%s is missing", name)
+		case !bytes.Equal(g, data):
+			t.Errorf("%s differs from the expected output", name)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s isn't in the expected output", name)
+		}
+	}
+}
+
+func TestGolden(t *testing.T) {
+	dir := t.TempDir()
+	genTestData(t, dir, "go,json")
+
+	if *flagGolden {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range readTree(t, dir) {
+			path := filepath.Join(goldenDir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	compareTrees(t, readTree(t, dir), readTree(t, goldenDir))
+	if t.Failed() {
+		t.Log("run go test -run TestGolden -golden to rewrite the golden outputs")
+	}
+}
+
+func TestGenDeterministic(t *testing.T) {
+	// the maps are iterated in the random order, so a few runs are compared to the first
+	want := t.TempDir()
+	genTestData(t, want, "go,json,c,rust")
+	for i := 0; i < 3; i++ {
+		dir := t.TempDir()
+		genTestData(t, dir, "go,json,c,rust")
+		compareTrees(t, readTree(t, dir), readTree(t, want))
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerFormsEmitter("ioports", "ioports.json", "write the %s forms accessing the I/O ports as JSON to `file`", []*IOPortForm{}, x86IOPortForm, nil)
+}
+
+// x86IOPortNames maps the forms accessing the I/O ports to their direction, "in" reading and "out" writing the port.
+var x86IOPortNames = map[string]string{
+	"in": "in", "insb": "in", "insw": "in", "insd": "in",
+	"out": "out", "outsb": "out", "outsw": "out", "outsd": "out",
+}
+
+// x86AccumulatorWidths maps the accumulator transferred by "in" and "out" to its width in bits.
+var x86AccumulatorWidths = map[string]int{"al": 8, "ax": 16, "eax": 32}
+
+// IOPort represents the I/O port access of an x86 instruction form.
+type IOPort struct {
+	// Direction is "in" reading the port and "out" writing it.
+	Direction string `json:"direction"`
+
+	// Port is the index of the port operand, the 8-bit immediate or "dx".
+	Port int `json:"port"`
+
+	// Immediate reports whether the port is the 8-bit immediate, limiting it to 0..255.
+	Immediate bool `json:"immediate,omitzero"`
+
+	// Data is the index of the transferred operand, the accumulator or the memory of the string operations.
+	Data int `json:"data"`
+
+	// Width is the width in bits of the transferred data.
+	Width int `json:"width"`
+
+	// IOPL reports whether the form requires the CPL at most the IOPL, or the access allowed by the I/O permission
+	// bitmap of the TSS, in the protected mode.
+	IOPL bool `json:"iopl,omitzero"`
+}
+
+// IOPort returns the I/O port access of the instruction, or nil if the form doesn't access the I/O ports.
+func (x *X86) IOPort(inst *X86Instruction) (*IOPort, error) {
+	name := inst.Names()[0]
+	dir, ok := x86IOPortNames[name]
+	if !ok {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	s, err := x.StringOperation(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &IOPort{Direction: dir, Port: -1, Data: -1, IOPL: x86IOPLNames[name]}
+	for _, op := range ops {
+		kind := op.Kinds[0]
+		switch {
+		case p.Port < 0 && (op.IsImm() || kind == "dx"):
+			p.Port, p.Immediate = op.Index, op.IsImm()
+		default:
+			p.Data, p.Width = op.Index, x86AccumulatorWidths[kind]
+			if s != nil {
+				p.Width = s.Width
+			}
+		}
+	}
+	if p.Port < 0 || p.Data < 0 {
+		return nil, fmt.Errorf("I/O port form without port or data operand")
+	}
+	return p, nil
+}
+
+// IOPortForm represents an x86 instruction form accessing the I/O ports.
+type IOPortForm struct {
+	Name     string  `json:"name"`
+	Operands string  `json:"operands,omitzero"`
+	Encoding string  `json:"encoding"`
+	OpCode   string  `json:"opcode"`
+	Port     *IOPort `json:"port"`
+}
+
+// x86IOPortForm returns the form of inst if it accesses the I/O ports, or nil.
+func x86IOPortForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	p, err := x.IOPort(inst)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &IOPortForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Port: p}, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86IOPort(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *IOPort
+	}{
+		// x86IOPortNames and x86AccumulatorWidths
+		{"in", "w:al, ib/ub", &IOPort{Direction: "in", Port: 1, Immediate: true, Data: 0, Width: 8, IOPL: true}},
+		{"in", "W:eax, dx", &IOPort{Direction: "in", Port: 1, Data: 0, Width: 32, IOPL: true}},
+		{"out", "ub, ax", &IOPort{Direction: "out", Port: 0, Immediate: true, Data: 1, Width: 16, IOPL: true}},
+		{"out", "R:dx, R:al", &IOPort{Direction: "out", Port: 0, Data: 1, Width: 8, IOPL: true}},
+		// the string operations
+		{"insd", "W:es:zdi, dx", &IOPort{Direction: "in", Port: 1, Data: 0, Width: 32, IOPL: true}},
+		{"outsb", "R:dx, R:ds:zsi", &IOPort{Direction: "out", Port: 0, Data: 1, Width: 8, IOPL: true}},
+		{"mov", "W:r32, r32/m32", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.IOPort(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("IOPort(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/go-json-experiment/json"
+)
+
+// stringMap is the map of the strings marshaled with the sorted keys, so the generated JSON is deterministic.
+type stringMap map[string]string
+
+// metadataFields returns the sorted metadata fields of m, "name" for the empty value and "name=value" for the others.
+func (m stringMap) metadataFields() []string {
+	fields := make([]string, 0, len(m))
+	for name, value := range m {
+		if value != "" {
+			name += "=" + value
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// MarshalNextJSON implements json.MarshalerV2.
+func (m stringMap) MarshalNextJSON(enc *json.Encoder, opts json.MarshalOptions) error {
+	return marshalSortedMap(enc, opts, reflect.ValueOf(m))
+}
+
+// armRegClassMap is the map of the register classes of each instruction set marshaled with the sorted keys.
+type armRegClassMap map[string][]*ArmRegClass
+
+// MarshalNextJSON implements json.MarshalerV2.
+func (m armRegClassMap) MarshalNextJSON(enc *json.Encoder, opts json.MarshalOptions) error {
+	return marshalSortedMap(enc, opts, reflect.ValueOf(m))
+}
+
+// marshalSortedMap marshals the map m of the string keys as the JSON object of the members sorted by the name.
+//
+// The Go maps are iterated in random order, and the JSON encoder writes the members in the iteration order.
+func marshalSortedMap(enc *json.Encoder, opts json.MarshalOptions, m reflect.Value) error {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	if err := enc.WriteToken(json.ObjectStart); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := enc.WriteToken(json.String(k.String())); err != nil {
+			return err
+		}
+		if err := opts.MarshalNext(enc, m.MapIndex(k).Interface()); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(json.ObjectEnd)
+}
@@ -0,0 +1,104 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerFormsEmitter("keystone", "keystone.json", "write the Keystone instruction syntax mapping of the %s forms JSON to `file`", []*KeystoneForm(nil), x86KeystoneForm, nil)
+}
+
+// KeystoneForm represents a mnemonic and operand-syntax mapping of the asmdb x86 instruction form
+// in the Intel syntax accepted by Keystone.
+type KeystoneForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+	Encoding string `json:"encoding"`
+	OpCode   string `json:"opcode"`
+
+	// Samples is the list of concrete instructions of every name and every combination of the operand kinds.
+	Samples []*KeystoneSample `json:"samples"`
+}
+
+// KeystoneSample represents a concrete instruction that can be passed to Keystone's ks_asm.
+type KeystoneSample struct {
+	// Mode is the Keystone ks_mode, "KS_MODE_32" or "KS_MODE_64".
+	Mode string `json:"mode"`
+
+	// Asm is the assembly source in the Intel syntax.
+	Asm string `json:"asm"`
+}
+
+// x86Modes returns the processor modes of the architectures listed in the metadata.
+func x86Modes(meta *X86Metadata) []int {
+	var modes []int
+	for _, arch := range meta.Architectures {
+		switch arch {
+		case "ANY":
+			return []int{32, 64}
+		case "X86":
+			modes = append(modes, 32)
+		case "X64":
+			modes = append(modes, 64)
+		}
+	}
+	if len(modes) == 0 {
+		// instructions without the architecture are available in any mode
+		return []int{32, 64}
+	}
+
+	return modes
+}
+
+// x86FormModes returns the processor modes of the instruction form, the modes of its metadata
+// without the 32-bit mode if the opcode has REX.W, like "nop r64/m64" listed for any mode.
+func x86FormModes(x86 *X86, inst *X86Instruction) []int {
+	modes := x86Modes(x86.ParseMetadata(inst.Metadata))
+	if op, err := inst.ParseOpCode(); err != nil || !op.REXW {
+		return modes
+	}
+
+	var long []int
+	for _, mode := range modes {
+		if mode == 64 {
+			long = append(long, mode)
+		}
+	}
+	return long
+}
+
+// x86KeystoneForm returns the KeystoneForm of inst.
+func x86KeystoneForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	form := &KeystoneForm{
+		Name:     inst.Name,
+		Operands: inst.Operands,
+		Encoding: inst.Encoding,
+		OpCode:   inst.OpCode,
+	}
+	seen := make(map[KeystoneSample]bool)
+	for _, mode := range x86FormModes(x, inst) {
+		for _, name := range inst.Names() {
+			for _, sample := range x86Samples(name, ops, mode) {
+				ks := KeystoneSample{
+					Mode: fmt.Sprintf("KS_MODE_%d", mode),
+					Asm:  sample.Intel(),
+				}
+				// kinds like "ib/ub" result in the same sample
+				if seen[ks] {
+					continue
+				}
+				seen[ks] = true
+				form.Samples = append(form.Samples, &ks)
+			}
+		}
+	}
+	return form, nil
+}
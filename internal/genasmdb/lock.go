@@ -0,0 +1,122 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerFormsEmitter("lock", "lock.json", "write the %s forms accepting the LOCK prefix as JSON to `file`", []*LockForm{}, x86LockForm, nil)
+}
+
+// LockPrefix represents the LOCK prefix eligibility of an x86 instruction form.
+//
+// The LOCK prefix is legal only with the memory destination, the form raises #UD if the destination
+// is encoded as a register or if the form doesn't accept the prefix.
+type LockPrefix struct {
+	// Operand is the index of the memory destination of the locked form.
+	Operand int `json:"operand"`
+
+	// Register reports whether the destination accepts a register too, like "r32/m32" of "add",
+	// whose register encoding faults with the LOCK prefix.
+	Register bool `json:"register,omitzero"`
+
+	// Implicit reports whether the form is locked without the prefix, like "xchg" of the memory.
+	Implicit bool `json:"implicit,omitzero"`
+
+	// XAcquire and XRelease report whether the locked form accepts the XACQUIRE and XRELEASE hints of the lock elision.
+	XAcquire bool `json:"xacquire,omitzero"`
+	XRelease bool `json:"xrelease,omitzero"`
+}
+
+// LockPrefix returns the LOCK prefix eligibility of the instruction, or nil if the form doesn't accept the prefix.
+//
+// The eligibility is derived from the "Lock", "ImplicitLock", "XAcquire" and "XRelease" attributes of the metadata.
+// The forms of the "Lock" attribute without the memory destination, like "and r64, ud", don't accept the prefix.
+func (x *X86) LockPrefix(inst *X86Instruction) (*LockPrefix, error) {
+	meta := x.ParseMetadata(inst.Metadata)
+	if _, ok := meta.Attributes["Lock"]; !ok {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		if !op.IsMem() || !op.IsWrite() || op.Implicit {
+			continue
+		}
+		_, implicit := meta.Attributes["ImplicitLock"]
+		_, acquire := meta.Attributes["XAcquire"]
+		_, release := meta.Attributes["XRelease"]
+		return &LockPrefix{Operand: op.Index, Register: op.IsReg(), Implicit: implicit, XAcquire: acquire, XRelease: release}, nil
+	}
+	return nil, nil
+}
+
+// CheckLock returns the error of the LOCK prefix of the instruction with the destination encoded as memory or as a register,
+// or nil if the prefix is legal.
+func (x *X86) CheckLock(inst *X86Instruction, memory bool) error {
+	lock, err := x.LockPrefix(inst)
+	if err != nil {
+		return err
+	}
+	switch {
+	case lock == nil:
+		return fmt.Errorf("%s %s: LOCK prefix not accepted", inst.Name, inst.Operands)
+	case !memory:
+		return fmt.Errorf("%s %s: LOCK prefix requires memory operand %d", inst.Name, inst.Operands, lock.Operand)
+	}
+	return nil
+}
+
+// x86LockByte is the LOCK prefix byte.
+const x86LockByte = 0xF0
+
+// x86PrefixBytes is the set of the legacy prefix bytes, which may precede the opcode of the encoding in any order.
+var x86PrefixBytes = map[byte]bool{
+	0xF0: true, 0xF2: true, 0xF3: true,
+	0x2E: true, 0x36: true, 0x3E: true, 0x26: true, 0x64: true, 0x65: true,
+	0x66: true, 0x67: true,
+}
+
+// WithLock returns the encoding enc of the instruction with the destination encoded as memory or as a register
+// with the LOCK prefix applied, or the error of CheckLock if the prefix isn't legal.
+//
+// The prefix is prepended to the legacy prefixes, so it precedes the REX prefix and the opcode,
+// and enc is returned as is if its legacy prefixes already have it. enc isn't modified.
+func (x *X86) WithLock(inst *X86Instruction, enc []byte, memory bool) ([]byte, error) {
+	if err := x.CheckLock(inst, memory); err != nil {
+		return nil, err
+	}
+	for _, b := range enc {
+		if !x86PrefixBytes[b] {
+			break
+		}
+		if b == x86LockByte {
+			return enc, nil
+		}
+	}
+	return append([]byte{x86LockByte}, enc...), nil
+}
+
+// LockForm represents an x86 instruction form accepting the LOCK prefix.
+type LockForm struct {
+	Name     string      `json:"name"`
+	Operands string      `json:"operands,omitzero"`
+	Encoding string      `json:"encoding"`
+	OpCode   string      `json:"opcode"`
+	Lock     *LockPrefix `json:"lock"`
+}
+
+// x86LockForm returns the form of inst if it accepts the LOCK prefix, or nil.
+func x86LockForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	lock, err := x.LockPrefix(inst)
+	if err != nil || lock == nil {
+		return nil, err
+	}
+	return &LockForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Lock: lock}, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestX86LockPrefix(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *LockPrefix
+	}{
+		{"add", "X:~r32/m32,~r32", &LockPrefix{Operand: 0, Register: true, XAcquire: true, XRelease: true}},
+		{"cmpxchg", "X:r64/m64, r64, <rax>", &LockPrefix{Operand: 0, Register: true, XAcquire: true, XRelease: true}},
+		{"xchg", "X:~r32/m32, X:~r32", &LockPrefix{Operand: 0, Register: true, Implicit: true, XAcquire: true}},
+		// the forms without the memory destination
+		{"and", "X:r64, ud", nil},
+		{"add", "X:~r32,~r32/m32", nil},
+		{"mov", "W:r32/m32, r32", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.LockPrefix(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LockPrefix(%s %s) = %+v, %v, want %+v", tt.name, tt.operands, got, err, tt.want)
+		}
+	}
+}
+
+func TestX86CheckLock(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		memory   bool
+		want     string
+	}{
+		{"add", "X:~r32/m32,~r32", true, ""},
+		{"add", "X:~r32/m32,~r32", false, "add X:~r32/m32,~r32: LOCK prefix requires memory operand 0"},
+		{"mov", "W:r32/m32, r32", true, "mov W:r32/m32, r32: LOCK prefix not accepted"},
+	}
+	for _, tt := range tests {
+		err := x86.X86.CheckLock(testX86Form(t, tt.name, tt.operands), tt.memory)
+		if (err == nil) != (tt.want == "") || err != nil && err.Error() != tt.want {
+			t.Errorf("CheckLock(%s %s, %v) = %v, want %q", tt.name, tt.operands, tt.memory, err, tt.want)
+		}
+	}
+}
+
+func TestX86WithLock(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		enc      []byte
+		memory   bool
+		want     []byte // nil if the prefix isn't legal
+	}{
+		// add [rax], ecx
+		{"add", "X:~r32/m32,~r32", []byte{0x01, 0x08}, true, []byte{0xF0, 0x01, 0x08}},
+		// cmpxchg [rax], rcx of the REX prefix
+		{"cmpxchg", "X:r64/m64, r64, <rax>", []byte{0x48, 0x0F, 0xB1, 0x08}, true, []byte{0xF0, 0x48, 0x0F, 0xB1, 0x08}},
+		// add word [rax], cx of the operand-size prefix already locked
+		{"add", "x:~r16/m16,~r16", []byte{0x66, 0xF0, 0x01, 0x08}, true, []byte{0x66, 0xF0, 0x01, 0x08}},
+		// add eax, ecx
+		{"add", "X:~r32/m32,~r32", []byte{0x01, 0xC8}, false, nil},
+		// mov [rax], ecx
+		{"mov", "W:r32/m32, r32", []byte{0x89, 0x08}, true, nil},
+	}
+	for _, tt := range tests {
+		enc := append([]byte(nil), tt.enc...)
+		got, err := x86.X86.WithLock(testX86Form(t, tt.name, tt.operands), enc, tt.memory)
+		if (err == nil) != (tt.want != nil) || !bytes.Equal(got, tt.want) {
+			t.Errorf("WithLock(%s %s, % X, %v) = % X, %v, want % X", tt.name, tt.operands, tt.enc, tt.memory, got, err, tt.want)
+		}
+		if !bytes.Equal(enc, tt.enc) {
+			t.Errorf("WithLock(%s %s, % X, %v) modified the encoding to % X", tt.name, tt.operands, tt.enc, tt.memory, enc)
+		}
+	}
+}
@@ -5,14 +5,28 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"embed"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-json-experiment/json"
+	"golang.org/x/sync/errgroup"
 )
 
 func init() {
@@ -35,55 +49,537 @@ const (
 	asmdbArmDataJS = "asmdb/armdata.js"
 )
 
+// asmdbFS is the embedded asmdb data files with the optional VERSION file.
+//
+//go:embed asmdb
+var asmdbFS embed.FS
+
 var (
-	//go:embed asmdb/x86data.js
-	asmdbX86 embed.FS
+	flagNASM  = flag.Bool("nasm-validate", false, "validate the NASM syntax samples with nasm found in PATH")
+	flagBuild = flag.Bool("build-check", false, "build the generated Go source of the ARM encoding tables in a temporary module with go found in PATH")
+
+	flagArmTablesDir = flag.String("arm-tables-dir", "", "write the Go source of the ARM encoding tables split per extension into `dir`")
+	flagArmTablesPkg = flag.String("arm-tables-pkg", "arm", "package `name` of the ARM encoding tables")
+	flagArmTablesRaw = flag.Bool("arm-tables-raw", true, "keep the raw operand strings in the ARM encoding tables")
 
-	//go:embed asmdb/armdata.js
-	asmdbArm embed.FS
+	flagOut      = flag.String("o", "", "write the outputs of the -format formats into the subdirectory of `dir` of each architecture, like dir/arm")
+	flagArch     = flag.String("arch", "x86,arm", "comma separated `list` of the architectures to generate, x86 and arm")
+	flagFormat   = flag.String("format", "go", "comma separated `list` of the output formats written into the -o directory, go, json, c and rust")
+	flagDump     = flag.Bool("dump", false, "print the dump of the parsed asmdb data")
+	flagTmpl     = flag.String("template", "", "execute the text/template `file` with the parsed data of each architecture into the -o directory")
+	flagX86Data  = flag.String("x86-data", "", "read the x86 asmdb data from `file` instead of the embedded x86data.js, - for stdin")
+	flagArmData  = flag.String("arm-data", "", "read the ARM asmdb data from `file` instead of the embedded armdata.js, - for stdin")
+	flagUpdate   = flag.String("update", "", "download the asmdb data of the upstream `ref`, like master, into the asmdb directory, print the Markdown changelog and exit")
+	flagCache    = flag.String("cache", "", "skip the generation if the content hash of the data and the flags recorded in the cache `file` is unchanged")
+	flagConfig   = flag.String("config", "", "read the flags from the JSON config `file`, the flags on the command line take precedence")
+	flagPatch    = flag.String("patch", "", "comma separated `list` of the JSON patch files applied in order to the asmdb data")
+	flagDiff     = flag.String("diff", "", "print the changes of the instruction forms from the asmdb data files in `dir` as JSON")
+	flagVerbose  = flag.Bool("v", false, "log the progress and the summary of the forms of each architecture and extension as key=value records")
+	flagSite     = flag.String("site", "", "write the static HTML site of the forms by extension and mnemonic with the search index into `dir`")
+	flagProgress = flag.Bool("progress", false, "print the number of the entries parsed, files written and validations run on a status line to stderr")
+	flagTimeout  = flag.Duration("timeout", 0, "cancel the generation or the update after `duration`, like 5m, or never if 0")
+	flagStrict   = flag.Bool("strict", false, "fail the generation on the x86 forms whose operand encoding, like \"RMI\", doesn't agree with the operands, and on the ARM aliases whose forms are missing")
+	flagValidate = flag.Bool("validate", false, "print the findings of the validation of the asmdb data instead of the generation and exit with status 1 if there is any")
 )
 
 func main() {
-	if err := gen(); err != nil {
+	flag.Parse()
+
+	// the config sets the flags first, like "timeout" and "update"
+	if *flagConfig != "" {
+		if err := loadConfig(*flagConfig); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// the interrupt and the -timeout cancel the pending downloads, external validations and generators
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *flagTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *flagTimeout)
+		defer cancel()
+	}
+
+	if *flagUpdate != "" {
+		if err := updateData(ctx, os.Stdout, filepath.Dir(asmdbX86DataJS), *flagUpdate); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var line *progressLine
+	if *flagProgress {
+		line = newProgressLine(os.Stderr)
+		progressHook = line.update
+	}
+
+	if *flagValidate {
+		err := gen(ctx)
+		line.done()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if atomic.LoadInt32(&numFindings) != 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	var key string
+	if *flagCache != "" {
+		var err error
+		if key, err = cacheKey(); err != nil {
+			log.Fatal(err)
+		}
+		ok, err := upToDate(*flagCache, key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if ok && !*flagDump && *flagDiff == "" {
+			return
+		}
+	}
+
+	err := gen(ctx)
+	line.done()
+	if err != nil {
 		log.Fatal(err)
 	}
+
+	if *flagCache != "" {
+		if err := writeCache(*flagCache, key); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// loadConfig sets the flags not set on the command line from the JSON config file name.
+//
+// The config is the object of the flag names and their values, the string, the boolean or the list of strings
+// for the comma separated list flags, like:
+//
+//	{"arch": ["arm"], "format": ["go", "json"], "o": "gen", "arm-tables-pkg": "armasm", "arm-tables-raw": false}
+func loadConfig(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("unmarshal config %s: %w", name, err)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	names := make([]string, 0, len(config))
+	for n := range config {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		if n == "config" {
+			return fmt.Errorf("config %s: nested config", name)
+		}
+		if set[n] {
+			continue
+		}
+		var value string
+		switch v := config[n].(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case []interface{}:
+			list := make([]string, len(v))
+			for i, e := range v {
+				s, ok := e.(string)
+				if !ok {
+					return fmt.Errorf("config %s: %s: element %d is not a string", name, n, i)
+				}
+				list[i] = s
+			}
+			value = strings.Join(list, ",")
+		default:
+			return fmt.Errorf("config %s: %s: invalid value %v", name, n, v)
+		}
+		if err := flag.Set(n, value); err != nil {
+			return fmt.Errorf("config %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// archGens maps the architecture names of the -arch flag to their generators.
+var archGens = map[string]func(ctx context.Context, w io.Writer) error{
+	"x86": genX86,
+	"arm": genArm,
+}
+
+// outputFormats is a list of the output formats of the -format flag.
+var outputFormats = []string{"go", "json", "c", "rust"}
+
+// splitList splits the comma separated list flag value.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// hasFormat reports whether the -o directory is set and the format is selected by the -format flag.
+func hasFormat(format string) bool {
+	return *flagOut != "" && containsString(splitList(*flagFormat), format)
+}
+
+// outputPath returns the path of the output file, the flag value of the file if it's set,
+// or the default name in the architecture subdirectory of the -o directory if the format is selected.
+// It returns empty if the output isn't enabled.
+func outputPath(file, arch, format, name string) string {
+	if file != "" {
+		return file
+	}
+	if hasFormat(format) {
+		return filepath.Join(*flagOut, arch, name)
+	}
+	return ""
+}
+
+// gen parses and generates each architecture selected by -arch concurrently,
+// then prints the dump of each architecture in order if -dump is set.
+func gen(ctx context.Context) error {
+	if *flagTmpl != "" && *flagOut == "" {
+		return fmt.Errorf("-template requires the -o directory")
+	}
+	for _, format := range splitList(*flagFormat) {
+		if !containsString(outputFormats, format) {
+			return fmt.Errorf("unknown output format %q", format)
+		}
+	}
+	if *flagX86Data == "-" && *flagArmData == "-" {
+		return fmt.Errorf("only one of -x86-data and -arm-data can read stdin")
+	}
+	var gens []func(ctx context.Context, w io.Writer) error
+	for _, arch := range splitList(*flagArch) {
+		fn, ok := archGens[arch]
+		if !ok {
+			return fmt.Errorf("unknown architecture %q", arch)
+		}
+		gens = append(gens, fn)
+	}
+	dumps := make([]bytes.Buffer, len(gens))
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i, fn := range gens {
+		i, fn := i, fn
+		g.Go(func() error {
+			return fn(ctx, &dumps[i])
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if *flagSite != "" {
+		if err := writeSiteRoot(*flagSite, splitList(*flagArch)); err != nil {
+			return fmt.Errorf("write site: %w", err)
+		}
+	}
+
+	for i := range dumps {
+		if _, err := dumps[i].WriteTo(os.Stdout); err != nil {
+			return fmt.Errorf("print dump: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// genX86 parses the x86 asmdb data, writes the x86 outputs enabled by the flags and the dump of the data to w.
+func genX86(ctx context.Context, w io.Writer) error {
+	data, source, err := readData(*flagX86Data, asmdbX86DataJS)
+	if err != nil {
+		return err
+	}
+	version, err := readVersion(*flagX86Data)
+	if err != nil {
+		return err
+	}
+
+	m, err := decodeX86Model(data)
+	if err != nil {
+		return err
+	}
+	m.Source, m.Version = source, version
+	if err := applyPatches(m, splitList(*flagPatch)); err != nil {
+		return err
+	}
+	logSummary(m)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if *flagStrict {
+		if err := checkEncodings(m); err != nil {
+			return err
+		}
+	}
+
+	if *flagDump {
+		fmt.Fprintf(w, "x86asm: %s\n", spew.Sdump(*m.X86))
+		fmt.Fprintf(w, "Instructions: %s\n", spew.Sdump(m.X86Instructions))
+	}
+	if *flagDiff != "" {
+		if err := writeDiff(w, m, asmdbX86DataJS); err != nil {
+			return err
+		}
+	}
+	if *flagValidate {
+		return writeFindingsText(w, m)
+	}
+
+	if err := emit(ctx, m); err != nil {
+		return err
+	}
+
+	if *flagTmpl != "" {
+		if err := writeFile(templateOutput(*flagTmpl, m.Arch), func(w io.Writer) error {
+			return writeTemplate(w, *flagTmpl, m)
+		}); err != nil {
+			return fmt.Errorf("write x86 template: %w", err)
+		}
+	}
+
+	if *flagSite != "" {
+		if err := writeSite(filepath.Join(*flagSite, m.Arch), m); err != nil {
+			return fmt.Errorf("write x86 site: %w", err)
+		}
+	}
+
+	if *flagNASM {
+		if err := validateNASM(ctx, m.X86, m.X86Instructions); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func gen() error {
-	fsX86, err := asmdbX86.Open(asmdbX86DataJS)
+// genArm parses the ARM asmdb data, writes the ARM outputs enabled by the flags and the dump of the data to w.
+func genArm(ctx context.Context, w io.Writer) error {
+	data, source, err := readData(*flagArmData, asmdbArmDataJS)
 	if err != nil {
-		return fmt.Errorf("read %s embeded file: %w", asmdbX86DataJS, err)
+		return err
+	}
+	version, err := readVersion(*flagArmData)
+	if err != nil {
+		return err
 	}
-	defer fsX86.Close()
 
-	x86AsmData, err := parse(fsX86)
+	m, err := decodeArmModel(data)
 	if err != nil {
-		return fmt.Errorf("parse asmdb data: %w", err)
+		return err
+	}
+	m.Source, m.Version = source, version
+	if err := applyPatches(m, splitList(*flagPatch)); err != nil {
+		return err
+	}
+	logSummary(m)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if *flagDump {
+		fmt.Fprintf(w, "armasm: %s\n", spew.Sdump(*m.Arm))
+		for _, arch := range m.Arm.Architectures {
+			fmt.Fprintf(w, "Arm %s Instructions: %s\n", arch, spew.Sdump(armInstructionsOf(m.ArmInstructions, arch)))
+		}
+	}
+	if *flagDiff != "" {
+		if err := writeDiff(w, m, asmdbArmDataJS); err != nil {
+			return err
+		}
+	}
+	if *flagValidate {
+		return writeFindingsText(w, m)
+	}
+
+	if err := emit(ctx, m); err != nil {
+		return err
+	}
+
+	tablesOpts := newArmTablesOptions(m)
+
+	if *flagArmTablesDir != "" {
+		if err := writeArmTablesDir(*flagArmTablesDir, m.Arm, m.ArmInstructions, tablesOpts); err != nil {
+			return fmt.Errorf("write arm tables: %w", err)
+		}
+	}
+
+	if hasFormat("go") {
+		if err := writeArmTablesDir(filepath.Join(*flagOut, tablesOpts.pkg), m.Arm, m.ArmInstructions, tablesOpts); err != nil {
+			return fmt.Errorf("write arm tables: %w", err)
+		}
+	}
+
+	if *flagBuild {
+		if err := buildArmTables(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	if *flagTmpl != "" {
+		if err := writeFile(templateOutput(*flagTmpl, m.Arch), func(w io.Writer) error {
+			return writeTemplate(w, *flagTmpl, m)
+		}); err != nil {
+			return fmt.Errorf("write arm template: %w", err)
+		}
+	}
+
+	if *flagSite != "" {
+		if err := writeSite(filepath.Join(*flagSite, m.Arch), m); err != nil {
+			return fmt.Errorf("write arm site: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// decodeX86Model decodes the x86 asmdb data.
+func decodeX86Model(data []byte) (*Model, error) {
+	m := &Model{Arch: "x86", X86: &X86{}}
+	if err := decodeData(bytes.NewReader(data), m.X86, func(inst [5]string) error {
+		m.X86Instructions = append(m.X86Instructions, X86Instruction{
+			Name:     inst[0],
+			Operands: inst[1],
+			Encoding: inst[2],
+			OpCode:   inst[3],
+			Metadata: inst[4],
+		})
+		addProgress(&progress.Parsed, 1)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("decode X86: %w", err)
+	}
+
+	return m, nil
+}
+
+// decodeArmModel decodes the ARM asmdb data.
+func decodeArmModel(data []byte) (*Model, error) {
+	m := &Model{Arch: "arm", Arm: &Arm{}}
+	if err := decodeData(bytes.NewReader(data), m.Arm, func(inst [5]string) error {
+		m.ArmInstructions = append(m.ArmInstructions, newArmInstruction(inst))
+		addProgress(&progress.Parsed, 1)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("decode Arm: %w", err)
 	}
 
-	var x86Asm X86
-	if err := json.Unmarshal(x86AsmData, &x86Asm); err != nil {
-		return fmt.Errorf("unmarshal X86: %w", err)
+	return m, nil
+}
+
+// readData reads the asmdb data from the file path, the standard input if path is "-",
+// or the embedded name file if path is empty.
+//
+// The source is the data file with its SHA-256 checksum, like "asmdb/armdata.js (sha256:...)",
+// to record the source data in the header of the generated files.
+func readData(path, name string) (data []byte, source string, err error) {
+	switch path {
+	case "":
+		if data, err = asmdbFS.ReadFile(name); err != nil {
+			return nil, "", fmt.Errorf("read %s embeded file: %w", name, err)
+		}
+	case "-":
+		name = "stdin"
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, "", fmt.Errorf("read asmdb data from stdin: %w", err)
+		}
+	default:
+		name = filepath.ToSlash(path)
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, "", fmt.Errorf("read asmdb data: %w", err)
+		}
 	}
-	instructions := x86Asm.Instructions // copy
-	x86Asm.Instructions = nil
 
-	fmt.Printf("x86asm: %s\n", spew.Sdump(x86Asm))
+	return data, fmt.Sprintf("%s (sha256:%x)", name, sha256.Sum256(data)), nil
+}
 
-	insts := make([]X86Instruction, len(instructions))
-	for i, inst := range instructions {
-		// _ = inst[4] // BCE hint // TODO(zchee): still needs?
-		insts[i].Name = inst[0]
-		insts[i].Operands = inst[1]
-		insts[i].Encoding = inst[2]
-		insts[i].OpCode = inst[3]
-		insts[i].Metadata = inst[4]
+// readVersion returns the upstream asmdb commit of the embedded data recorded in the VERSION file by -update,
+// or empty if the data is read from path or there is no VERSION file.
+func readVersion(path string) (string, error) {
+	if path != "" {
+		return "", nil
+	}
+	data, err := asmdbFS.ReadFile("asmdb/" + asmdbVersionFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
 	}
-	fmt.Printf("Instructions: %s\n", spew.Sdump(insts))
+	if err != nil {
+		return "", fmt.Errorf("read %s embeded file: %w", asmdbVersionFile, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeFile creates the name file with its parent directories and writes the output of fn to it.
+func writeFile(name string, fn func(w io.Writer) error) (err error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(name), err)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("close %s: %w", name, cerr)
+		}
+	}()
+
+	bw := bufio.NewWriter(f)
+	if err := fn(bw); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	verbosef("msg=wrote file=%s", name)
+	addProgress(&progress.Written, 1)
 
 	return nil
 }
 
+// verbosef logs the key=value record of the format with -v.
+func verbosef(format string, args ...interface{}) {
+	if *flagVerbose {
+		log.Printf(format, args...)
+	}
+}
+
+// logSummary logs the summary records of m with -v, the number of the forms of the architecture
+// and of each extension in the sorted order, the forms requiring no extension as "none".
+func logSummary(m *Model) {
+	if !*flagVerbose {
+		return
+	}
+	exts := countExtensions(m)
+	verbosef("msg=parsed arch=%s forms=%d extensions=%d source=%q", m.Arch, len(m.X86Instructions)+len(m.ArmInstructions), len(exts), m.Source)
+	names := make([]string, 0, len(exts))
+	for ext := range exts {
+		names = append(names, ext)
+	}
+	sort.Strings(names)
+	for _, ext := range names {
+		verbosef("msg=extension arch=%s extension=%s forms=%d", m.Arch, ext, exts[ext])
+	}
+}
+
 const (
 	// markJSONBegin is a magic comment that marks the beginning of the JSON data in the asmjit/asmdb JavaScript file.
 	markJSONBegin = "// ${JSON:BEGIN}"
@@ -92,30 +588,450 @@ const (
 	markJSONEnd = "// ${JSON:END}"
 )
 
-func parse(r io.Reader) (data []byte, err error) {
-	buf, err := io.ReadAll(r)
-	if err != nil {
-		return nil, fmt.Errorf("read r reader: %w", err)
+// utf8BOM is the UTF-8 byte order mark skipped at the beginning of the asmjit/asmdb JavaScript file.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// DataError represents the error of the asmdb data at its position in the asmjit/asmdb JavaScript file.
+type DataError struct {
+	// Offset and Line are the byte offset and the 1-based line number of the error in the file.
+	Offset int64
+	Line   int
+
+	// Marker is the magic comment of the error, markJSONBegin or markJSONEnd, or empty.
+	Marker string
+
+	// Context is the excerpt of the line around the error, or empty if the line is unknown.
+	Context string
+
+	Err error
+}
+
+// Error implements error.
+func (e *DataError) Error() string {
+	s := fmt.Sprintf("asmdb data line %d (offset %d)", e.Line, e.Offset)
+	if e.Marker != "" {
+		s += fmt.Sprintf(" %q", e.Marker)
+	}
+	s += ": " + e.Err.Error()
+	if e.Context != "" {
+		s += fmt.Sprintf(", near %q", e.Context)
+	}
+	return s
+}
+
+// Unwrap returns the underlying error.
+func (e *DataError) Unwrap() error {
+	return e.Err
+}
+
+const (
+	// dataContextLines is the number of the last read lines kept for the context of DataError.
+	dataContextLines = 64
+
+	// dataContextBytes is the maximum length of the context of DataError on each side of the error.
+	dataContextBytes = 40
+)
+
+// dataLine is the position of a line of the JSON data in the asmjit/asmdb JavaScript file.
+type dataLine struct {
+	jsonOff int64 // offset of the line in the JSON data
+	srcOff  int64 // offset of the line in the file
+	no      int   // 1-based line number
+}
+
+// dataReader reads the JSON data between the markJSONBegin and markJSONEnd magic comments
+// of the asmjit/asmdb JavaScript file line by line.
+//
+// The magic comments may be indented, the lines may end with CRLF and the file may begin with the UTF-8 BOM.
+// The JSON data without the markJSONEnd magic comment ends at the end of the file with a warning.
+// The comments and the trailing commas of the JSON data, which JavaScript allows, are sanitized.
+//
+// It records the position of each line of the JSON data, so the errors of the JSON decoder
+// are reported at their line of the file.
+type dataReader struct {
+	r     *bufio.Reader
+	begin bool   // the markJSONBegin magic comment was read
+	end   bool   // the markJSONEnd magic comment was read
+	line  []byte // unread part of the current line
+
+	lineNo  int            // number of the lines read
+	srcOff  int64          // offset of the next line in the file
+	jsonOff int64          // offset of the next line in the JSON data
+	lines   []dataLine     // the lines of the JSON data in order
+	recent  map[int][]byte // the last dataContextLines lines by number
+
+	pending []byte // sanitized data held until the comma is resolved
+	comma   int    // index in pending of the comma which may be trailing, or -1
+	str     bool   // the sanitizer is in a JSON string
+	esc     bool   // the sanitizer is after the backslash in a JSON string
+}
+
+// newDataReader returns the reader of the JSON data of the asmjit/asmdb JavaScript file r.
+func newDataReader(r io.Reader) *dataReader {
+	return &dataReader{r: bufio.NewReader(r), recent: make(map[int][]byte), comma: -1}
+}
+
+// sanitize appends the JSON line to the pending lines with the upstream quirks blanked out by the spaces,
+// the comments from "//" to the end of the line and the trailing commas before ']' and '}', so the offsets
+// of the JSON data are kept. The pending data from the comma which may be trailing is held until it's resolved.
+func (d *dataReader) sanitize(line []byte) {
+	start := len(d.pending)
+	d.pending = append(d.pending, line...)
+	for i := start; i < len(d.pending); i++ {
+		c := d.pending[i]
+		switch {
+		case d.str:
+			switch {
+			case d.esc:
+				d.esc = false
+			case c == '\\':
+				d.esc = true
+			case c == '"':
+				d.str = false
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '/' && i+1 < len(d.pending) && d.pending[i+1] == '/':
+			for ; i < len(d.pending) && d.pending[i] != '\r' && d.pending[i] != '\n'; i++ {
+				d.pending[i] = ' '
+			}
+			continue
+		}
+
+		if d.comma >= 0 && (c == ']' || c == '}') {
+			d.pending[d.comma] = ' '
+		}
+		d.comma = -1
+		switch c {
+		case '"':
+			d.str = true
+		case ',':
+			d.comma = i
+		}
+	}
+}
+
+// flush makes the pending data readable, the unresolved comma is kept.
+func (d *dataReader) flush() {
+	d.line, d.pending, d.comma = d.pending, nil, -1
+}
+
+// Read implements io.Reader.
+func (d *dataReader) Read(p []byte) (int, error) {
+	for len(d.line) == 0 {
+		if d.end {
+			return 0, io.EOF
+		}
+
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("read asmdb data: %w", err)
+		}
+		d.lineNo++
+		n := len(line) // the length in the file with the BOM
+		if d.lineNo == 1 {
+			line = bytes.TrimPrefix(line, utf8BOM)
+		}
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case !d.begin:
+			d.begin = bytes.HasPrefix(trimmed, []byte(markJSONBegin))
+		case bytes.HasPrefix(trimmed, []byte(markJSONEnd)):
+			d.end = true
+			d.flush()
+		default:
+			d.sanitize(line)
+			if d.comma < 0 {
+				d.flush()
+			} else {
+				// the lines up to the unresolved comma are readable
+				d.line = d.pending[:d.comma]
+				d.pending = append([]byte(nil), d.pending[d.comma:]...)
+				d.comma = 0
+			}
+			d.lines = append(d.lines, dataLine{jsonOff: d.jsonOff, srcOff: d.srcOff + int64(n-len(line)), no: d.lineNo})
+			d.jsonOff += int64(len(line))
+			d.recent[d.lineNo] = line
+			delete(d.recent, d.lineNo-dataContextLines)
+		}
+		d.srcOff += int64(n)
+
+		if err == io.EOF && !d.end {
+			if !d.begin {
+				return 0, &DataError{Offset: d.srcOff, Line: d.lineNo, Marker: markJSONBegin, Err: errors.New("could not find the magic comment")}
+			}
+			d.warnMissingEnd()
+			d.end = true
+			d.flush()
+		}
+	}
+
+	n := copy(p, d.line)
+	d.line = d.line[n:]
+
+	return n, nil
+}
+
+// warnMissingEnd logs the warning of the missing markJSONEnd magic comment, the JSON data ends at the end of the file.
+func (d *dataReader) warnMissingEnd() {
+	log.Printf("asmdb data line %d: could not find %q magic comment, the JSON data ends at the end of the file", d.lineNo, markJSONEnd)
+}
+
+// finish reads the rest of the file after the decoded JSON data, warning about the missing markJSONEnd
+// magic comment and the ignored JSON data of the next markJSONBegin magic comment, only the first pair
+// of the magic comments is read.
+func (d *dataReader) finish() error {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("read asmdb data: %w", err)
+		}
+		d.lineNo++
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case !d.end && bytes.HasPrefix(trimmed, []byte(markJSONEnd)):
+			d.end = true
+		case d.end && bytes.HasPrefix(trimmed, []byte(markJSONBegin)):
+			log.Printf("asmdb data line %d: ignoring the JSON data of the next %q magic comment", d.lineNo, markJSONBegin)
+			return nil
+		}
+
+		if err == io.EOF {
+			if !d.end {
+				d.warnMissingEnd()
+			}
+			return nil
+		}
+	}
+}
+
+// errorAt returns err as the DataError at the offset off of the JSON data.
+func (d *dataReader) errorAt(off int64, err error) *DataError {
+	i := sort.Search(len(d.lines), func(i int) bool {
+		return d.lines[i].jsonOff > off
+	}) - 1
+	if i < 0 {
+		return &DataError{Offset: d.srcOff, Line: d.lineNo, Err: err}
+	}
+
+	l := d.lines[i]
+	e := &DataError{Offset: l.srcOff + off - l.jsonOff, Line: l.no, Err: err}
+	if line, ok := d.recent[l.no]; ok {
+		col := int(off - l.jsonOff)
+		lo, hi := col-dataContextBytes, col+dataContextBytes
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(line) {
+			hi = len(line)
+		}
+		if lo < hi {
+			e.Context = string(bytes.TrimSpace(line[lo:hi]))
+		}
+	}
+	return e
+}
+
+// decodeError returns err of dec as the DataError at its position, the offset of the JSON syntax error
+// or the current offset of dec. The DataError of the reader is returned as is.
+func (d *dataReader) decodeError(dec *json.Decoder, err error) error {
+	var de *DataError
+	if errors.As(err, &de) {
+		return de
+	}
+	off := dec.InputOffset()
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		off = serr.Offset
+	}
+	return d.errorAt(off, err)
+}
+
+// dataMember is the position of a member of the data other than the instructions in the object decoded at once.
+type dataMember struct {
+	restOff int   // offset of the member value in the decoded object
+	jsonOff int64 // offset of the member value in the JSON data
+}
+
+// decodeData decodes the JSON data of the asmjit/asmdb JavaScript file r into v, except the "instructions" array,
+// whose instruction tuples are decoded one at a time and passed to fn in order, so the whole array is never held in memory.
+//
+// The errors of the data are the *DataError of their position in the file. The instruction tuples of an unexpected shape
+// and the errors returned by fn don't stop decoding, they are collected and returned as EntryErrors after the whole
+// data is decoded, so all problems of the instructions are reported at once.
+func decodeData(r io.Reader, v interface{}, fn func(inst [5]string) error) error {
+	dr := newDataReader(r)
+	dec := json.NewDecoder(dr)
+	if tok, err := dec.ReadToken(); err != nil {
+		return dr.decodeError(dec, fmt.Errorf("decode asmdb data: %w", err))
+	} else if tok.Kind() != '{' {
+		return dr.decodeError(dec, fmt.Errorf("decode asmdb data: got %v, want object", tok.Kind()))
 	}
 
-	// split buf by markJSONBegin magic comment
-	splitted := bytes.SplitN(buf, []byte(markJSONBegin), 2)
-	if len(splitted) <= 1 {
-		return nil, fmt.Errorf("could not split asmdb data by %q magic comment: splitted length: %d", markJSONBegin, len(splitted))
+	// the members other than the instructions are decoded into v at once
+	rest := bytes.NewBufferString("{")
+	var errs EntryErrors
+	var members []dataMember
+	for dec.PeekKind() != '}' {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return dr.decodeError(dec, fmt.Errorf("decode asmdb data: %w", err))
+		}
+		name := tok.String()
+
+		if name != "instructions" {
+			val, err := dec.ReadValue()
+			if err != nil {
+				return dr.decodeError(dec, fmt.Errorf("decode asmdb data %q: %w", name, err))
+			}
+			if rest.Len() > 1 {
+				rest.WriteByte(',')
+			}
+			quoted, err := json.Marshal(name)
+			if err != nil {
+				return fmt.Errorf("encode asmdb data name %q: %w", name, err)
+			}
+			rest.Write(quoted)
+			rest.WriteByte(':')
+			members = append(members, dataMember{restOff: rest.Len(), jsonOff: dec.InputOffset() - int64(len(val))})
+			rest.Write(val)
+			continue
+		}
+
+		if tok, err := dec.ReadToken(); err != nil {
+			return dr.decodeError(dec, fmt.Errorf("decode asmdb instructions: %w", err))
+		} else if tok.Kind() != '[' {
+			return dr.decodeError(dec, fmt.Errorf("decode asmdb instructions: got %v, want array", tok.Kind()))
+		}
+		for i := 0; dec.PeekKind() != ']'; i++ {
+			depth := dec.StackDepth()
+			inst, err := decodeTuple(dec)
+			if err != nil && !errors.Is(err, errTupleShape) {
+				return dr.decodeError(dec, fmt.Errorf("decode asmdb instruction %d: %w", i, err))
+			}
+			if err != nil {
+				errs = append(errs, &EntryError{Index: i, Name: inst[0], Err: dr.decodeError(dec, err)})
+
+				// skip the rest of the tuple of the unexpected shape
+				for dec.StackDepth() > depth {
+					if _, err := dec.ReadToken(); err != nil {
+						return dr.decodeError(dec, fmt.Errorf("decode asmdb instruction %d: %w", i, err))
+					}
+				}
+				continue
+			}
+			if err := validateTuple(inst); err != nil {
+				errs = append(errs, &EntryError{Index: i, Name: inst[0], Err: dr.errorAt(dec.InputOffset()-1, err)})
+				continue
+			}
+			if err := fn(inst); err != nil {
+				errs = append(errs, &EntryError{Index: i, Name: inst[0], Err: err})
+			}
+		}
+		if _, err := dec.ReadToken(); err != nil {
+			return dr.decodeError(dec, fmt.Errorf("decode asmdb instructions: %w", err))
+		}
+	}
+	if _, err := dec.ReadToken(); err != nil {
+		return dr.decodeError(dec, fmt.Errorf("decode asmdb data: %w", err))
 	}
+	rest.WriteByte('}')
 
-	data = splitted[1]
-	if len(data) == 0 {
-		return nil, fmt.Errorf("incorrect splitted asmdb data: data length: %d", len(data))
+	if err := json.Unmarshal(rest.Bytes(), v); err != nil {
+		var serr *json.SemanticError
+		if !errors.As(err, &serr) || len(members) == 0 {
+			return fmt.Errorf("unmarshal asmdb data: %w", err)
+		}
+		i := sort.Search(len(members), func(i int) bool {
+			return int64(members[i].restOff) > serr.Offset
+		}) - 1
+		if i < 0 {
+			i = 0
+		}
+		return dr.errorAt(members[i].jsonOff+serr.Offset-int64(members[i].restOff), fmt.Errorf("unmarshal asmdb data: %w", err))
 	}
-	data = data[1:] // 1 means trim first newline
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return dr.finish()
+}
+
+// errTupleShape is the error of the instruction tuple of an unexpected shape, the rest of the data can be still decoded.
+var errTupleShape = errors.New("invalid instruction tuple")
+
+// EntryError represents the error of an instruction entry of the asmdb data.
+type EntryError struct {
+	Index int    // index of the entry in the instructions
+	Name  string // mnemonic of the entry, or empty if it's unknown
+	Err   error
+}
+
+// Error implements error.
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("instruction %d %q: %v", e.Index, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
 
-	// trim after the markJSONEnd magic comment
-	idx := bytes.Index(data, []byte(markJSONEnd))
-	if idx <= 0 {
-		return nil, fmt.Errorf("could not find %q magic comment from asmdb data: %s", markJSONEnd, string(data))
+// EntryErrors is the list of the errors of the instruction entries collected in one pass over the asmdb data.
+type EntryErrors []*EntryError
+
+// Error implements error, the error of each entry on its own line.
+func (errs EntryErrors) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d invalid asmdb instructions:", len(errs))
+	for _, err := range errs {
+		sb.WriteString("\n\t")
+		sb.WriteString(err.Error())
 	}
-	data = data[:idx-1] // -1 means also trim end of newline
+	return sb.String()
+}
 
-	return
+// decodeTuple decodes the next instruction tuple from dec, the array of five strings, by reading the tokens directly
+// instead of unmarshaling with reflection, as the asmdb data has thousands of them.
+func decodeTuple(dec *json.Decoder) (inst [5]string, err error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return inst, err
+	}
+	if tok.Kind() != '[' {
+		return inst, fmt.Errorf("%w: got %v, want array", errTupleShape, tok.Kind())
+	}
+	for i := range inst {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return inst, err
+		}
+		if tok.Kind() == ']' {
+			return inst, fmt.Errorf("%w: got %d elements, want %d", errTupleShape, i, len(inst))
+		}
+		if tok.Kind() != '"' {
+			return inst, fmt.Errorf("%w: element %d: got %v, want string", errTupleShape, i, tok.Kind())
+		}
+		inst[i] = tok.String()
+	}
+	if tok, err = dec.ReadToken(); err != nil {
+		return inst, err
+	}
+	if tok.Kind() != ']' {
+		return inst, fmt.Errorf("%w: got more than %d elements", errTupleShape, len(inst))
+	}
+
+	return inst, nil
+}
+
+// validateTuple reports the error of the instruction tuple of the empty name or opcode with the tuple content.
+func validateTuple(inst [5]string) error {
+	switch {
+	case strings.TrimSpace(inst[0]) == "":
+		return fmt.Errorf("%w: empty name: %q", errTupleShape, inst)
+	case strings.TrimSpace(inst[3]) == "":
+		return fmt.Errorf("%w: empty opcode: %q", errTupleShape, inst)
+	}
+	return nil
 }
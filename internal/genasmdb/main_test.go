@@ -0,0 +1,483 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+)
+
+// testDataObject is the data other than the instructions of the test data.
+type testDataObject struct {
+	Name string `json:"name"`
+}
+
+// testData returns the asmjit/asmdb JavaScript file of the JSON lines between the magic comments.
+func testData(lines ...string) string {
+	return "// test data\n" + markJSONBegin + "\n" + strings.Join(lines, "\n") + "\n" + markJSONEnd + "\n"
+}
+
+// jsonString returns v as JSON for the test errors.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// testDataLines is the JSON data of two instructions.
+var testDataLines = []string{
+	`{`,
+	`  "name": "test",`,
+	`  "instructions": [`,
+	`    ["add", "r32, r32", "MR", "01 /r", "ANY"],`,
+	`    ["sub", "r32, r32", "MR", "29 /r", "ANY"]`,
+	`  ]`,
+	`}`,
+}
+
+// decodeTestData decodes src and returns the decoded object, the names of the instructions and the log output.
+func decodeTestData(src string) (testDataObject, []string, string, error) {
+	var logs bytes.Buffer
+	w := log.Writer()
+	log.SetOutput(&logs)
+	defer log.SetOutput(w)
+
+	var v testDataObject
+	var names []string
+	err := decodeData(strings.NewReader(src), &v, func(inst [5]string) error {
+		names = append(names, inst[0])
+		return nil
+	})
+	return v, names, logs.String(), err
+}
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		log  string // substring of the logged warning, or empty
+	}{
+		{
+			name: "plain",
+			src:  testData(testDataLines...),
+		},
+		{
+			name: "BOM",
+			src:  "\xef\xbb\xbf" + testData(testDataLines...),
+		},
+		{
+			name: "CRLF",
+			src:  strings.ReplaceAll(testData(testDataLines...), "\n", "\r\n"),
+		},
+		{
+			name: "indented markers",
+			src:  "  \t" + markJSONBegin + "\n" + strings.Join(testDataLines, "\n") + "\n    " + markJSONEnd + "\n",
+		},
+		{
+			name: "missing END marker",
+			src:  markJSONBegin + "\n" + strings.Join(testDataLines, "\n") + "\n",
+			log:  "could not find",
+		},
+		{
+			name: "next marker pair",
+			src:  testData(testDataLines...) + testData(`{"name": "next", "instructions": []}`),
+			log:  "ignoring the JSON data",
+		},
+		{
+			name: "comments",
+			src: testData(
+				`{`,
+				`  // the name`,
+				`  "name": "test", // trailing comment`,
+				`  "instructions": [`,
+				`    // ["mul", "r32", "M", "F7 /4", "ANY"],`,
+				`    ["add", "r32, r32", "MR", "01 /r", "ANY"],`,
+				`    ["sub", "r32, r32", "MR", "29 /r", "ANY"]`,
+				`  ]`,
+				`}`,
+			),
+		},
+		{
+			name: "trailing commas",
+			src: testData(
+				`{`,
+				`  "name": "test",`,
+				`  "instructions": [`,
+				`    ["add", "r32, r32", "MR", "01 /r", "ANY",],`,
+				`    ["sub", "r32, r32", "MR", "29 /r", "ANY"],`,
+				`  ],`,
+				`}`,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, names, logs, err := decodeTestData(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.Name != "test" {
+				t.Errorf("name = %q, want %q", v.Name, "test")
+			}
+			if got := strings.Join(names, " "); got != "add sub" {
+				t.Errorf("instructions = %q, want %q", got, "add sub")
+			}
+			if tt.log == "" && logs != "" || !strings.Contains(logs, tt.log) {
+				t.Errorf("log = %q, want %q", logs, tt.log)
+			}
+		})
+	}
+}
+
+func TestDecodeDataError(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		at   string // the text at the offset of the error in src, or empty if it isn't checked
+		err  string // substring of the error
+	}{
+		{
+			name: "missing BEGIN marker",
+			src:  strings.Join(testDataLines, "\n") + "\n",
+			line: 8,
+			err:  "could not find the magic comment",
+		},
+		{
+			name: "missing comma",
+			src:  testData(`{`, `  "name": "test"`, `  "instructions": []`, `}`),
+			line: 5,
+			at:   `"instructions"`,
+			err:  "missing character ','",
+		},
+		{
+			name: "BOM missing comma",
+			src:  "\xef\xbb\xbf" + testData(`{`, `  "name": "test"`, `  "instructions": []`, `}`),
+			line: 5,
+			at:   `"instructions"`,
+			err:  "missing character ','",
+		},
+		{
+			name: "CRLF missing comma",
+			src:  strings.ReplaceAll(testData(`{`, `  "name": "test"`, `  "instructions": []`, `}`), "\n", "\r\n"),
+			line: 5,
+			at:   `"instructions"`,
+			err:  "missing character ','",
+		},
+		{
+			name: "unterminated string",
+			src:  testData(`{`, `  "name": "test,`, `  "instructions": []`, `}`),
+			line: 4,
+			err:  "within string",
+		},
+		{
+			name: "name of the wrong type",
+			src:  testData(`{`, `  "name": 1,`, `  "instructions": []`, `}`),
+			line: 4,
+			at:   `"name": 1,`,
+			err:  "unmarshal asmdb data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := decodeTestData(tt.src)
+			var de *DataError
+			if !errors.As(err, &de) {
+				t.Fatalf("error = %v, want DataError", err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %q, want %q", err, tt.err)
+			}
+			if tt.line != 0 && de.Line != tt.line {
+				t.Errorf("line = %d, want %d", de.Line, tt.line)
+			}
+			if tt.at != "" && !strings.HasPrefix(tt.src[de.Offset:], tt.at) {
+				t.Errorf("offset %d is at %q, want %q", de.Offset, tt.src[de.Offset:], tt.at)
+			}
+		})
+	}
+}
+
+func TestDecodeDataEntryErrors(t *testing.T) {
+	src := testData(
+		`{`,
+		`  "name": "test",`,
+		`  "instructions": [`,
+		`    ["add", "r32, r32", "MR", "01 /r"],`,
+		`    ["", "r32, r32", "MR", "29 /r", "ANY"],`,
+		`    ["mul", "r32", "M", "F7 /4", "ANY"]`,
+		`  ]`,
+		`}`,
+	)
+	_, names, _, err := decodeTestData(src)
+	var errs EntryErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error = %v, want EntryErrors", err)
+	}
+	if len(errs) != 2 || errs[0].Index != 0 || errs[1].Index != 1 {
+		t.Errorf("errors = %v, want the entries 0 and 1", err)
+	}
+	if got := strings.Join(names, " "); got != "mul" {
+		t.Errorf("instructions = %q, want %q", got, "mul")
+	}
+}
+
+func FuzzDecodeData(f *testing.F) {
+	f.Add(testData(testDataLines...))
+	f.Add("\xef\xbb\xbf" + strings.ReplaceAll(testData(testDataLines...), "\n", "\r\n"))
+	f.Add(markJSONBegin + "\n" + strings.Join(testDataLines, "\n") + "\n")
+	f.Add(testData(`{`, `  "name": "test", // comment`, `  "instructions": [["add", "r32", "M", "FF /0", "ANY"],],`, `}`))
+	f.Add(testData(`{`, `  "name": 1,`, `  "instructions": [["add"], ["", "", "", "", ""]]`, `}`))
+	f.Fuzz(func(t *testing.T, src string) {
+		_, _, _, err := decodeTestData(src)
+		switch err := err.(type) {
+		case nil, EntryErrors:
+		case *DataError:
+			if err.Offset < 0 || err.Offset > int64(len(src)) {
+				t.Errorf("offset %d of %v is out of the %d bytes", err.Offset, err, len(src))
+			}
+		default:
+			t.Errorf("error = %T %v, want DataError or EntryErrors", err, err)
+		}
+	})
+}
+
+func TestDataReaderSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "line comment",
+			in:   "[1, // one\n2]\n",
+			want: "[1,       \n2]\n",
+		},
+		{
+			name: "comment line",
+			in:   "// [0],\n[1]\n",
+			want: "       \n[1]\n",
+		},
+		{
+			name: "slashes in string",
+			in:   "[\"http://x\"]\n",
+			want: "[\"http://x\"]\n",
+		},
+		{
+			name: "escaped quote in string",
+			in:   "[\"a\\\"//b\", \"c\\\\\"] // d\n",
+			want: "[\"a\\\"//b\", \"c\\\\\"]     \n",
+		},
+		{
+			name: "trailing comma in array",
+			in:   "[1, 2,]\n",
+			want: "[1, 2 ]\n",
+		},
+		{
+			name: "trailing comma in object",
+			in:   "{\"a\": 1,\n}\n",
+			want: "{\"a\": 1 \n}\n",
+		},
+		{
+			name: "trailing comma before comment",
+			in:   "[1, // one\n// two\n]\n",
+			want: "[1        \n      \n]\n",
+		},
+		{
+			name: "trailing commas nested",
+			in:   "[[1,],\n]\n",
+			want: "[[1 ] \n]\n",
+		},
+		{
+			name: "comma before bracket in string",
+			in:   "[\",]\", \"a\"]\n",
+			want: "[\",]\", \"a\"]\n",
+		},
+		{
+			name: "CRLF",
+			in:   "[1, // one\r\n2,\r\n]\r\n",
+			want: "[1,       \r\n2 \r\n]\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := markJSONBegin + "\n" + tt.in + markJSONEnd + "\n"
+			got, err := io.ReadAll(newDataReader(strings.NewReader(src)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("sanitized %q = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// loadModels returns the models of the embedded asmdb data.
+func loadModels(t testing.TB) (x86, arm *Model) {
+	data, _, err := readData("", asmdbX86DataJS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x86, err = decodeX86Model(data); err != nil {
+		t.Fatal(err)
+	}
+	if data, _, err = readData("", asmdbArmDataJS); err != nil {
+		t.Fatal(err)
+	}
+	if arm, err = decodeArmModel(data); err != nil {
+		t.Fatal(err)
+	}
+	return x86, arm
+}
+
+var (
+	testModelsOnce sync.Once
+	testX86Model   *Model
+	testArmModel   *Model
+)
+
+// testModels returns the models of the embedded asmdb data loaded once for all the tests, which don't modify them.
+func testModels(t *testing.T) (x86, arm *Model) {
+	testModelsOnce.Do(func() {
+		testX86Model, testArmModel = loadModels(t)
+	})
+	if testX86Model == nil || testArmModel == nil {
+		t.Fatal("the asmdb data isn't loaded")
+	}
+	return testX86Model, testArmModel
+}
+
+// testX86Form returns the x86 form of the name and the operands, as written in the asmdb data.
+func testX86Form(t *testing.T, name, operands string) *X86Instruction {
+	m, _ := testModels(t)
+	var forms []string
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		if inst.Name != name {
+			continue
+		}
+		if inst.Operands == operands {
+			return inst
+		}
+		forms = append(forms, inst.Operands)
+	}
+	t.Fatalf("no x86 form %s %q, the forms are %q", name, operands, forms)
+	return nil
+}
+
+// testArmForm returns the ARM form of the name, the operands, as written in the asmdb data, and the instruction set.
+func testArmForm(t *testing.T, name, operands, arch string) *ArmInstruction {
+	_, m := testModels(t)
+	var forms []string
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		if inst.Name != name {
+			continue
+		}
+		if inst.Operands == operands && inst.Arch == arch {
+			return inst
+		}
+		forms = append(forms, inst.Arch+" "+inst.Operands)
+	}
+	t.Fatalf("no ARM form %s %s %q, the forms are %q", arch, name, operands, forms)
+	return nil
+}
+
+func BenchmarkLoad(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		loadModels(b)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	x86, arm := loadModels(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range x86.X86Instructions {
+			inst := &x86.X86Instructions[j]
+			if _, err := inst.ParseOperands(); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := inst.ParseOpCode(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		for j := range arm.ArmInstructions {
+			inst := &arm.ArmInstructions[j]
+			if _, err := inst.ParseOperands(); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := inst.ParseEncoding(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	setFlags(b, map[string]string{
+		"o":      b.TempDir(),
+		"format": "go,json",
+	})
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(w)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := gen(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLookupForm(b *testing.B) {
+	x86, arm := loadModels(b)
+	for _, m := range []*Model{x86, arm} {
+		// the last form is the worst case of the search by the mnemonic and the operands
+		forms := diffForms(m)
+		key := forms[len(forms)-1].key
+		b.Run(m.Arch, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if lookupForm(m, key) < 0 {
+					b.Fatalf("no form %s", key)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkInstructionsWithExtension(b *testing.B) {
+	x86, arm := loadModels(b)
+	b.Run("x86", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if len(x86.X86.InstructionsWithExtension(x86.X86Instructions, "AVX512_F")) == 0 {
+				b.Fatal("no AVX512_F forms")
+			}
+		}
+	})
+	b.Run("arm", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if len(arm.Arm.InstructionsWithExtension(arm.ArmInstructions, "CRC32")) == 0 {
+				b.Fatal("no CRC32 forms")
+			}
+		}
+	})
+}
@@ -0,0 +1,286 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("memory", "memory.json", "write the %s forms accessing memory and their accesses as JSON to `file`", []*MemoryAccessForm{}, x86MemoryAccessForm, armMemoryAccessForm)
+}
+
+// MemoryAccess represents a memory access of an instruction form.
+type MemoryAccess struct {
+	// Operand is the index of the memory operand, or -1 for the stack access of the form without the operand,
+	// like "push" and "ret".
+	Operand int `json:"operand"`
+
+	Read  bool `json:"read,omitzero"`
+	Write bool `json:"write,omitzero"`
+
+	// Widths is the sorted list of the widths in bits of the accessed memory, like 128 and 32 for "xmm/m128/b32".
+	// It's empty if the width depends on the mode or the implementation, like "mem" and the stack of "call".
+	Widths []int `json:"widths,omitzero"`
+
+	// Repeated reports whether the form accesses several elements of the width, like the register lists,
+	// the gathers and scatters and "pusha".
+	Repeated bool `json:"repeated,omitzero"`
+
+	// Implicit reports whether the address isn't encoded, like "<ds:zsi>" of the string operations and the stack.
+	Implicit bool `json:"implicit,omitzero"`
+
+	// Offsets is the list of the sizes of the absolute offset of the moffs operand in the modes of the form,
+	// like "mov eax, moff32" encoded by "A1" with the offset following the opcode.
+	Offsets []*MoffsOffset `json:"offsets,omitzero"`
+}
+
+// MoffsOffset represents the size of the absolute offset of a moffs operand in a processor mode.
+type MoffsOffset struct {
+	// Mode is the processor mode bits, 32 or 64.
+	Mode int `json:"mode"`
+
+	// Bytes is the size of the offset in bytes, the address size of the mode.
+	Bytes int `json:"bytes"`
+
+	// OverrideBytes is the size of the offset in bytes with the 0x67 address size prefix.
+	OverrideBytes int `json:"overrideBytes"`
+}
+
+// x86AddressOnlyNames is the list of the forms whose memory operand is an address which isn't accessed,
+// like "lea", the prefetches and the cache line operations.
+var x86AddressOnlyNames = map[string]bool{
+	"lea": true, "nop": true, "invlpg": true, "monitor": true, "monitorx": true,
+	"bndcl": true, "bndcn": true, "bndcu": true, "bndmk": true,
+	"clflush": true, "clflushopt": true, "clwb": true, "cldemote": true,
+	"prefetch": true, "prefetchnta": true, "prefetcht0": true, "prefetcht1": true, "prefetcht2": true,
+	"prefetchw": true, "prefetchwt1": true,
+	"vgatherpf0dpd": true, "vgatherpf0dps": true, "vgatherpf0qpd": true, "vgatherpf0qps": true,
+	"vgatherpf1dpd": true, "vgatherpf1dps": true, "vgatherpf1qpd": true, "vgatherpf1qps": true,
+	"vscatterpf0dpd": true, "vscatterpf0dps": true, "vscatterpf0qpd": true, "vscatterpf0qps": true,
+	"vscatterpf1dpd": true, "vscatterpf1dps": true, "vscatterpf1qpd": true, "vscatterpf1qps": true,
+}
+
+// x86StackAccesses is the stack access of the forms without the stack operand, "R" popping and "W" pushing,
+// followed by "+" if several elements are accessed.
+var x86StackAccesses = map[string]string{
+	"push": "W", "pushf": "W", "pushfd": "W", "pushfq": "W", "pusha": "W+", "pushad": "W+",
+	"pop": "R", "popf": "R", "popfd": "R", "popfq": "R", "popa": "R+", "popad": "R+",
+	"call": "W", "lcall": "W+", "ret": "R", "retf": "R+", "iret": "R+", "iretd": "R+", "iretq": "R+",
+	"int": "W+", "int1": "W+", "int3": "W+", "into": "W+", "enter": "W+", "leave": "R",
+}
+
+// x86StringPrefixes is the list of the names of the string operations, whose width is the suffix of the name, like "movsb".
+var x86StringPrefixes = []string{"movs", "cmps", "lods", "stos", "scas", "ins", "outs"}
+
+// x86StringWidths maps the suffix of the string operation to its width in bits.
+var x86StringWidths = map[byte]int{'b': 8, 'w': 16, 'd': 32, 'q': 64}
+
+// x86MemWidths returns the sorted widths in bits of the memory kinds, the element width of the vector memory
+// and the broadcast, and reports whether the access is repeated.
+func x86MemWidths(name string, kinds []string) ([]int, bool) {
+	var widths []int
+	repeated := false
+	add := func(w int) {
+		if w > 0 && !containsInt(widths, w) {
+			widths = append(widths, w)
+		}
+	}
+	for _, kind := range kinds {
+		switch {
+		case x86MemSizes[kind] > 0:
+			add(x86MemSizes[kind] * 8)
+		case strings.HasPrefix(kind, "vm"):
+			// "vm32x" is the vector of the 32-bit elements
+			repeated = true
+			if strings.HasPrefix(kind, "vm32") {
+				add(32)
+			} else {
+				add(64)
+			}
+		case kind == "b16", kind == "b32", kind == "b64":
+			add(map[string]int{"b16": 16, "b32": 32, "b64": 64}[kind])
+		case kind == "tmem":
+			repeated = true
+		case strings.Contains(kind, ":"):
+			for _, prefix := range x86StringPrefixes {
+				if strings.HasPrefix(name, prefix) && len(name) == len(prefix)+1 {
+					add(x86StringWidths[name[len(name)-1]])
+				}
+			}
+		}
+	}
+	sort.Ints(widths)
+	return widths, repeated
+}
+
+// MemoryAccesses returns the memory accesses of the instruction, the memory operands which are read or written,
+// the implicit operands of the string operations and the stack accesses, or nil if it doesn't access memory.
+func (x *X86) MemoryAccesses(inst *X86Instruction) ([]*MemoryAccess, error) {
+	name := inst.Names()[0]
+	if x86AddressOnlyNames[name] {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	var accesses []*MemoryAccess
+	for _, op := range ops {
+		if !op.IsMem() {
+			continue
+		}
+		var kinds []string
+		for _, kind := range op.Kinds {
+			if x86KindType(kind)&X86OperandMem != 0 || x86IsBroadcast(kind) {
+				kinds = append(kinds, kind)
+			}
+		}
+		widths, repeated := x86MemWidths(name, kinds)
+		access := &MemoryAccess{Operand: op.Index, Read: op.IsRead(), Write: op.IsWrite(), Widths: widths, Repeated: repeated, Implicit: op.Implicit}
+		if op.IsMoffs() {
+			for _, mode := range x86FormModes(x, inst) {
+				bytes, err := op.MoffsOffsetSize(mode, false)
+				if err != nil {
+					return nil, err
+				}
+				override, err := op.MoffsOffsetSize(mode, true)
+				if err != nil {
+					return nil, err
+				}
+				access.Offsets = append(access.Offsets, &MoffsOffset{Mode: mode, Bytes: bytes, OverrideBytes: override})
+			}
+		}
+		accesses = append(accesses, access)
+	}
+
+	if stack, ok := x86StackAccesses[name]; ok {
+		access := &MemoryAccess{Operand: -1, Read: stack[0] == 'R', Write: stack[0] == 'W', Repeated: strings.HasSuffix(stack, "+"), Implicit: true}
+		for _, op := range ops {
+			// the pushed or popped value is the width of the register or memory operand
+			for _, kind := range op.Kinds {
+				w := x86RegWidths[x86RegClass(kind)]
+				if w == 0 {
+					w = x86MemSizes[kind] * 8
+				}
+				if w > 0 && !access.Repeated && !containsInt(access.Widths, w) {
+					access.Widths = append(access.Widths, w)
+				}
+			}
+		}
+		sort.Ints(access.Widths)
+		accesses = append(accesses, access)
+	}
+	return accesses, nil
+}
+
+// armMemoryPrefixes is the list of the prefixes of the ARM loads and stores, longest first,
+// whose remaining suffix is the width, like "b" of "ldrexb" and "sht" of "ldrsht".
+var armMemoryPrefixes = []string{"ldaex", "stlex", "ldrex", "strex", "lda", "stl", "ldr", "str"}
+
+// armMemoryWidths maps the suffix of the load or store to its width in bits.
+var armMemoryWidths = map[string]int{"": 32, "b": 8, "h": 16, "d": 64}
+
+// armMemoryAccess returns the read and write access, the width in bits and whether the access is repeated
+// of the lowercased ARM mnemonic, or false if it doesn't access memory.
+func armMemoryAccess(mnemonic string) (read, write bool, width int, repeated, ok bool) {
+	for _, prefix := range armMemoryPrefixes {
+		if !strings.HasPrefix(mnemonic, prefix) {
+			continue
+		}
+		suffix := strings.TrimPrefix(mnemonic, prefix)
+		if prefix == "ldr" || prefix == "str" {
+			// the unprivileged "t" and the signed "s" suffixes, like "ldrsbt"
+			suffix = strings.TrimPrefix(strings.TrimSuffix(suffix, "t"), "s")
+		}
+		width, ok := armMemoryWidths[suffix]
+		if !ok {
+			return false, false, 0, false, false
+		}
+		return prefix[0] == 'l', prefix[0] == 's', width, false, true
+	}
+
+	switch {
+	case mnemonic == "pop":
+		return true, false, 32, false, true
+	case mnemonic == "push":
+		return false, true, 32, false, true
+	case strings.HasPrefix(mnemonic, "ldm"), strings.HasPrefix(mnemonic, "rfe"):
+		return true, false, 32, true, true
+	case strings.HasPrefix(mnemonic, "stm"), strings.HasPrefix(mnemonic, "srs"):
+		return false, true, 32, true, true
+	case strings.HasPrefix(mnemonic, "fldm"):
+		return true, false, 64, true, true
+	case strings.HasPrefix(mnemonic, "fstm"):
+		return false, true, 64, true, true
+	case mnemonic == "swp":
+		return true, true, 32, false, true
+	case mnemonic == "swpb":
+		return true, true, 8, false, true
+	case mnemonic == "tbb":
+		// the table branches load the branch offset from the table at the base register
+		return true, false, 8, false, true
+	case mnemonic == "tbh":
+		return true, false, 16, false, true
+	}
+	return false, false, 0, false, false
+}
+
+// MemoryAccesses returns the memory accesses of the instruction, the memory operand of the loads and the stores,
+// or the stack of "push" and "pop", or nil if it doesn't access memory. The preloads, like "pld", don't access memory.
+func (a *Arm) MemoryAccesses(inst *ArmInstruction) ([]*MemoryAccess, error) {
+	mnemonic, _ := splitArmName(inst.Name)
+	read, write, width, repeated, ok := armMemoryAccess(strings.ToLower(mnemonic))
+	if !ok {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	access := &MemoryAccess{Operand: -1, Read: read, Write: write, Widths: []int{width}, Repeated: repeated, Implicit: true}
+	for _, op := range ops {
+		switch op.Type {
+		case ArmOperandMem:
+			access.Operand, access.Implicit = op.Index, false
+		case ArmOperandRegList:
+			// "push" and "pop" of the register list, like "RdList"
+			access.Repeated = true
+		}
+	}
+	return []*MemoryAccess{access}, nil
+}
+
+// MemoryAccessForm represents an instruction form accessing memory.
+type MemoryAccessForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "RM", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode   string          `json:"opcode"`
+	Accesses []*MemoryAccess `json:"accesses"`
+}
+
+// x86MemoryAccessForm returns the form of inst if it accesses memory, or nil.
+func x86MemoryAccessForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	accesses, err := x.MemoryAccesses(inst)
+	if err != nil || len(accesses) == 0 {
+		return nil, err
+	}
+	return &MemoryAccessForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Accesses: accesses}, nil
+}
+
+// armMemoryAccessForm returns the form of inst if it accesses memory, or nil.
+func armMemoryAccessForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	accesses, err := a.MemoryAccesses(inst)
+	if err != nil || len(accesses) == 0 {
+		return nil, err
+	}
+	return &MemoryAccessForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Accesses: accesses}, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86MemoryAccesses(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     []*MemoryAccess
+	}{
+		{"add", "X:~r32,~r32/m32", []*MemoryAccess{{Operand: 1, Read: true, Widths: []int{32}}}},
+		{"vaddps", "W:ymm {kz},~ymm,~ymm/m256/b32", []*MemoryAccess{{Operand: 2, Read: true, Widths: []int{32, 256}}}},
+		{"vgatherdps", "X:xmm, vm32x, X:xmm", []*MemoryAccess{{Operand: 1, Read: true, Widths: []int{32}, Repeated: true}}},
+		{"mov", "W:rax, moff64", []*MemoryAccess{{Operand: 1, Read: true, Widths: []int{64}, Offsets: []*MoffsOffset{{Mode: 64, Bytes: 8, OverrideBytes: 4}}}}},
+		// x86AddressOnlyNames
+		{"lea", "W:r32, mem", nil},
+		{"prefetcht0", "R:mem", nil},
+		// x86StringPrefixes
+		{"movsb", "W:<es:zdi>, R:<ds:zsi>", []*MemoryAccess{
+			{Operand: 0, Write: true, Widths: []int{8}, Implicit: true},
+			{Operand: 1, Read: true, Widths: []int{8}, Implicit: true},
+		}},
+		// x86StackAccesses
+		{"push", "R:r32/m32", []*MemoryAccess{
+			{Operand: 0, Read: true, Widths: []int{32}},
+			{Operand: -1, Write: true, Widths: []int{32}, Implicit: true},
+		}},
+		{"pusha", "", []*MemoryAccess{{Operand: -1, Write: true, Repeated: true, Implicit: true}}},
+		{"ret", "", []*MemoryAccess{{Operand: -1, Read: true, Implicit: true}}},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.MemoryAccesses(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MemoryAccesses(%s %s) = %s, %v, want %s", tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
+
+func TestArmMemoryAccesses(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     []*MemoryAccess
+	}{
+		// armMemoryPrefixes
+		{"ldrb", "Rd!=XX, [Rn!=PC, #ImmZ]", ArmT32, []*MemoryAccess{{Operand: 1, Read: true, Widths: []int{8}}}},
+		{"strexd", "Rd!=PC, Rs!=PC, Rs2==Rs+1, [Rn!=PC]", ArmA32, []*MemoryAccess{{Operand: 3, Write: true, Widths: []int{64}}}},
+		// the other loads and stores
+		{"ldm", "[Rn!=PC]{!}, RdList", ArmT32, []*MemoryAccess{{Operand: 0, Read: true, Widths: []int{32}, Repeated: true}}},
+		{"pop", "Rd!=SP", ArmA32, []*MemoryAccess{{Operand: -1, Read: true, Widths: []int{32}, Implicit: true}}},
+		{"swpb", "Rd!=PC, Rs!=PC, [Rn!=PC]", ArmA32, []*MemoryAccess{{Operand: 2, Read: true, Write: true, Widths: []int{8}}}},
+		{"tbb", "Rn!=SP, Rn!=XX", ArmT32, []*MemoryAccess{{Operand: -1, Read: true, Widths: []int{8}, Implicit: true}}},
+		{"tbh", "Rn!=SP, Rn!=XX", ArmT32, []*MemoryAccess{{Operand: -1, Read: true, Widths: []int{16}, Implicit: true}}},
+		{"pld", "[Rn!=PC, #ImmZ]", ArmT32, nil},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.MemoryAccesses(testArmForm(t, tt.name, tt.operands, tt.arch))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MemoryAccesses(%s %s %s) = %s, %v, want %s", tt.arch, tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nasmSample represents a sample of the instruction form to be assembled by NASM.
+type nasmSample struct {
+	inst *X86Instruction
+	name string
+	asm  string
+}
+
+// validateNASM assembles the samples of insts in the NASM syntax with nasm found in PATH,
+// and confirms each sample is accepted and its encoding contains the opcode of a form of the same name.
+// The opcode of the other forms is accepted since the assembler can choose the shorter encoding,
+// like "83 /0 ib" for "add r32, id" with a small immediate.
+//
+// The failed samples are logged and the number of the failures is returned as the error.
+func validateNASM(ctx context.Context, x86 *X86, insts []X86Instruction) error {
+	nasm, err := exec.LookPath("nasm")
+	if err != nil {
+		return fmt.Errorf("nasm validation: %w", err)
+	}
+	f, err := NewX86Formatter(X86SyntaxNASM, insts)
+	if err != nil {
+		return err
+	}
+
+	opcodes := make(map[string][]*X86OpCode)
+	for i := range insts {
+		opcode, err := insts[i].ParseOpCode()
+		if err != nil {
+			return fmt.Errorf("%s: %w", insts[i].Name, err)
+		}
+		for _, name := range insts[i].Names() {
+			opcodes[name] = append(opcodes[name], opcode)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "genasmdb-nasm")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	total, failed := 0, 0
+	for _, mode := range []int{32, 64} {
+		var samples []*nasmSample
+		for i := range insts {
+			inst := &insts[i]
+			if !containsInt(x86FormModes(x86, inst), mode) {
+				continue
+			}
+			ops, err := inst.ParseOperands()
+			if err != nil {
+				return fmt.Errorf("%s: %w", inst.Name, err)
+			}
+			for _, name := range inst.Names() {
+				for _, sample := range x86Samples(name, ops, mode) {
+					samples = append(samples, &nasmSample{inst: inst, name: name, asm: f.Format(sample)})
+				}
+			}
+		}
+
+		encs, errs, err := runNASM(ctx, nasm, dir, mode, samples)
+		if err != nil {
+			return err
+		}
+		addProgress(&progress.Validated, 1)
+		for i, s := range samples {
+			total++
+			reason := errs[i]
+			if reason == "" && !nasmMatchOpCodes(encs[i], opcodes[s.name]) {
+				reason = fmt.Sprintf("encoding %X doesn't match the opcode of %s", encs[i], s.name)
+			}
+			if reason != "" {
+				failed++
+				log.Printf("nasm: bits %d: %s %s: %q: %s", mode, s.inst.Name, s.inst.Operands, s.asm, reason)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("nasm validation: %d of %d samples failed", failed, total)
+	}
+
+	return nil
+}
+
+// nasmErrorRe matches the NASM error message, like "x.asm:12: error: invalid combination of opcode and operands".
+var nasmErrorRe = regexp.MustCompile(`^[^:]+:(\d+): error: (.*)$`)
+
+// nasmListRe matches the NASM listing line with the encoding, like "    12 00000010 B801000000   mov eax, 1".
+//
+// The encoding ends with '-' if it's continued to the following line.
+var nasmListRe = regexp.MustCompile(`^\s*(\d+) [0-9A-F]{8} ([0-9A-F\[\]()]+)(-?)`)
+
+// runNASM assembles the samples in the mode and returns the encoding and the error message of each sample.
+//
+// The samples rejected by the first run are blanked out and the rest are assembled again to get the listing,
+// so the line numbers are kept.
+func runNASM(ctx context.Context, nasm, dir string, mode int, samples []*nasmSample) (encs [][]byte, errs []string, err error) {
+	src := filepath.Join(dir, fmt.Sprintf("bits%d.asm", mode))
+	list := filepath.Join(dir, fmt.Sprintf("bits%d.lst", mode))
+	out := filepath.Join(dir, fmt.Sprintf("bits%d.bin", mode))
+
+	errs = make([]string, len(samples))
+	for pass := 0; pass < 2; pass++ {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "bits %d\n", mode)
+		for i, s := range samples {
+			if errs[i] == "" {
+				sb.WriteString(s.asm)
+			}
+			sb.WriteByte('\n')
+		}
+		if err := os.WriteFile(src, []byte(sb.String()), 0o644); err != nil {
+			return nil, nil, err
+		}
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, nasm, "-f", "bin", "-o", out, "-l", list, src)
+		cmd.Stderr = &stderr
+		runErr := cmd.Run()
+
+		sc := bufio.NewScanner(&stderr)
+		for sc.Scan() {
+			m := nasmErrorRe.FindStringSubmatch(sc.Text())
+			if m == nil {
+				continue
+			}
+			// the first line is the "bits" directive
+			line, _ := strconv.Atoi(m[1])
+			if i := line - 2; i >= 0 && i < len(samples) && errs[i] == "" {
+				errs[i] = m[2]
+			}
+		}
+		if runErr == nil {
+			break
+		}
+		if pass == 1 {
+			return nil, nil, fmt.Errorf("run nasm: %w: %s", runErr, stderr.String())
+		}
+	}
+
+	encs = make([][]byte, len(samples))
+	data, err := os.ReadFile(list)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		m := nasmListRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		i := n - 2
+		if i < 0 || i >= len(samples) {
+			continue
+		}
+		// the relocated and relative values are enclosed by the brackets and the parentheses
+		b, err := hex.DecodeString(strings.NewReplacer("[", "", "]", "", "(", "", ")", "").Replace(m[2]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse nasm listing %q: %w", line, err)
+		}
+		encs[i] = append(encs[i], b...)
+	}
+
+	return encs, errs, nil
+}
+
+// nasmMatchOpCodes reports whether the encoding contains any of the opcodes.
+func nasmMatchOpCodes(enc []byte, opcodes []*X86OpCode) bool {
+	for _, opcode := range opcodes {
+		if nasmMatchOpCode(enc, opcode) {
+			return true
+		}
+	}
+	return false
+}
+
+// nasmMatchOpCode reports whether the encoding contains the opcode bytes.
+//
+// The low 3 bits of the last opcode byte are ignored for the "+r" and "+i" opcodes.
+// Only the primary opcode byte is compared for the VEX, EVEX and XOP encodings.
+func nasmMatchOpCode(enc []byte, opcode *X86OpCode) bool {
+	want := opcode.Bytes
+	if opcode.Prefix != "" {
+		want = want[len(want)-1:]
+	}
+	mask := byte(0xFF)
+	if opcode.PlusR || opcode.PlusI {
+		mask = 0xF8
+	}
+
+	for i := 0; i+len(want) <= len(enc); i++ {
+		last := len(want) - 1
+		if bytes.Equal(enc[i:i+last], want[:last]) && enc[i+last]&mask == want[last]&mask {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-json-experiment/json"
+)
+
+func init() {
+	for _, arch := range []string{"x86", "arm"} {
+		registerEmitter(arch, "json", "provenance.json", "write the source data and the fields of the "+arch+" forms set by the patches JSON to `file`", &emitterFunc{name: arch + "-provenance", fn: func(m *Model, w io.Writer) error {
+			return writeProvenance(w, m)
+		}, out: Provenance{}})
+	}
+}
+
+// PatchForm represents the change of an instruction form of the patch file.
+//
+// The op is one of:
+//
+//	add       add the form of the key with the fields of set after the instructions
+//	fix       set the fields of the form of the key to the values of set
+//	annotate  append the values of set to the fields of the form of the key, separated by a space
+//	remove    remove the form of the key
+//
+// The fields are named as in the diff, "encoding", "opcode" and "metadata" for x86, and "opcode" and "metadata" for ARM.
+type PatchForm struct {
+	Op  string            `json:"op"`
+	Key FormKey           `json:"key"`
+	Set map[string]string `json:"set,omitzero"`
+}
+
+// patchFile represents the patch file of the forms of each architecture, like:
+//
+//	{"arm": [{"op": "fix", "key": {"arch": "A64", "name": "adc", "operands": "Wd, Wn, Wm"}, "set": {"metadata": "ARMv8+"}}]}
+type patchFile struct {
+	X86 []PatchForm `json:"x86,omitzero"`
+	Arm []PatchForm `json:"arm,omitzero"`
+}
+
+// FieldSource represents the provenance of a field of the instruction form set by the patch file, or of the form
+// removed by the patch file if the field is empty.
+type FieldSource struct {
+	Key    FormKey `json:"key"`
+	Op     string  `json:"op"`
+	Field  string  `json:"field,omitzero"`
+	Source string  `json:"source"`
+}
+
+// applyPatches applies the forms of the architecture of m of the patch files to m in the order of files and of the forms,
+// appends each applied file with its SHA-256 checksum to m.Source, and records the changed fields in m.Provenance.
+//
+// The sources take precedence in the order of merging, the upstream data first, then each patch file in the order of files,
+// so the field set by several patches has the value of the last one. The keys of m.Provenance are the keys
+// of the forms when the patch was applied, a removed form shifts the index of the following forms of the same key.
+func applyPatches(m *Model, files []string) error {
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("read patch: %w", err)
+		}
+		var patch patchFile
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return fmt.Errorf("unmarshal patch %s: %w", name, err)
+		}
+
+		forms := patch.X86
+		if m.Arch == "arm" {
+			forms = patch.Arm
+		}
+		if len(forms) == 0 {
+			continue
+		}
+		for _, pf := range forms {
+			if err := applyPatchForm(m, pf, filepath.ToSlash(name)); err != nil {
+				return fmt.Errorf("patch %s: %s %s: %w", name, pf.Op, pf.Key, err)
+			}
+		}
+		m.Source += fmt.Sprintf(" patched by %s (sha256:%x)", filepath.ToSlash(name), sha256.Sum256(data))
+	}
+
+	return nil
+}
+
+// lookupForm returns the index of the form of key in the instructions of m, or -1.
+func lookupForm(m *Model, key FormKey) int {
+	for i, f := range diffForms(m) {
+		if f.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// formField returns the named field of the i-th instruction of m, or nil if there is no such field.
+func formField(m *Model, i int, field string) *string {
+	if m.Arch == "x86" {
+		inst := &m.X86Instructions[i]
+		switch field {
+		case "encoding":
+			return &inst.Encoding
+		case "opcode":
+			return &inst.OpCode
+		case "metadata":
+			return &inst.Metadata
+		}
+		return nil
+	}
+
+	inst := &m.ArmInstructions[i]
+	switch field {
+	case "opcode":
+		return &inst.OpCode
+	case "metadata":
+		return &inst.Metadata
+	}
+	return nil
+}
+
+// applyPatchForm applies pf of the patch file source to m.
+func applyPatchForm(m *Model, pf PatchForm, source string) error {
+	i := lookupForm(m, pf.Key)
+	switch pf.Op {
+	case "add":
+		if i >= 0 {
+			return fmt.Errorf("form already exists")
+		}
+		if pf.Key.Index != lookupFormCount(m, pf.Key) {
+			return fmt.Errorf("index %d isn't the next index of the forms of the same key", pf.Key.Index)
+		}
+		if m.Arch == "x86" {
+			m.X86Instructions = append(m.X86Instructions, X86Instruction{Name: pf.Key.Name, Operands: pf.Key.Operands})
+			i = len(m.X86Instructions) - 1
+		} else {
+			m.ArmInstructions = append(m.ArmInstructions, ArmInstruction{Name: pf.Key.Name, Operands: pf.Key.Operands, Arch: pf.Key.Arch})
+			i = len(m.ArmInstructions) - 1
+		}
+	case "fix", "annotate":
+		if i < 0 {
+			return fmt.Errorf("no such form")
+		}
+	case "remove":
+		if i < 0 {
+			return fmt.Errorf("no such form")
+		}
+		if len(pf.Set) != 0 {
+			return fmt.Errorf("remove sets the fields")
+		}
+		if m.Arch == "x86" {
+			m.X86Instructions = append(m.X86Instructions[:i], m.X86Instructions[i+1:]...)
+		} else {
+			m.ArmInstructions = append(m.ArmInstructions[:i], m.ArmInstructions[i+1:]...)
+		}
+		m.Provenance = append(m.Provenance, FieldSource{Key: pf.Key, Op: pf.Op, Source: source})
+		return nil
+	default:
+		return fmt.Errorf("unknown op")
+	}
+
+	fields := make([]string, 0, len(pf.Set))
+	for field := range pf.Set {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		p := formField(m, i, field)
+		if p == nil {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if pf.Op == "annotate" && *p != "" {
+			*p += " " + pf.Set[field]
+		} else {
+			*p = pf.Set[field]
+		}
+		m.Provenance = append(m.Provenance, FieldSource{Key: pf.Key, Op: pf.Op, Field: field, Source: source})
+	}
+
+	return nil
+}
+
+// lookupFormCount returns the number of the forms of m of the same key regardless of its index.
+func lookupFormCount(m *Model, key FormKey) int {
+	n := 0
+	for _, f := range diffForms(m) {
+		f.key.Index = key.Index
+		if f.key == key {
+			n++
+		}
+	}
+	return n
+}
+
+// Provenance represents the source data of the forms and the fields of the forms set by the patches.
+type Provenance struct {
+	Source  string        `json:"source"`
+	Version string        `json:"version,omitzero"`
+	Fields  []FieldSource `json:"fields"`
+}
+
+// writeProvenance writes the source data of m and the fields set by the patches as JSON to w.
+func writeProvenance(w io.Writer, m *Model) error {
+	provenance := &Provenance{m.Source, m.Version, m.Provenance}
+	if provenance.Fields == nil {
+		provenance.Fields = []FieldSource{}
+	}
+
+	return writeJSON(w, m.Arch+" provenance", provenance)
+}
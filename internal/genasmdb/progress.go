@@ -0,0 +1,88 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress is the progress of the generation of all the architectures so far.
+type Progress struct {
+	// Parsed is the number of the instruction entries parsed from the asmdb data.
+	Parsed int64
+
+	// Written is the number of the output files written.
+	Written int64
+
+	// Validated is the number of the validations run, like the validation of the forms and the nasm and go runs.
+	Validated int64
+}
+
+var (
+	// progress is the progress so far, updated atomically by the generators.
+	progress Progress
+
+	// progressHook is called with the progress after each step if not nil.
+	// The generators of the architectures call it concurrently.
+	progressHook func(p Progress)
+)
+
+// addProgress adds n to the counter of progress and calls the progressHook.
+func addProgress(counter *int64, n int64) {
+	atomic.AddInt64(counter, n)
+	if progressHook != nil {
+		progressHook(Progress{
+			Parsed:    atomic.LoadInt64(&progress.Parsed),
+			Written:   atomic.LoadInt64(&progress.Written),
+			Validated: atomic.LoadInt64(&progress.Validated),
+		})
+	}
+}
+
+// progressLine renders the progress as a single status line on the terminal w, rewritten at most
+// every interval, like "parsed 9214 entries, wrote 12 files, ran 3 validations".
+type progressLine struct {
+	w        io.Writer
+	interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+	p    Progress
+}
+
+// newProgressLine returns the progressLine writing to w.
+func newProgressLine(w io.Writer) *progressLine {
+	return &progressLine{w: w, interval: 100 * time.Millisecond}
+}
+
+// update is the progressHook rewriting the line with p.
+func (l *progressLine) update(p Progress) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.p = p
+	if now := time.Now(); now.Sub(l.last) >= l.interval {
+		l.last = now
+		l.write()
+	}
+}
+
+// done rewrites the line with the last progress and ends it, if l is not nil.
+func (l *progressLine) done() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.write()
+	fmt.Fprintln(l.w)
+}
+
+// write rewrites the line from its start.
+func (l *progressLine) write() {
+	fmt.Fprintf(l.w, "\rparsed %d entries, wrote %d files, ran %d validations", l.p.Parsed, l.p.Written, l.p.Validated)
+}
@@ -0,0 +1,78 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+func init() {
+	registerFormsEmitter("rep", "rep.json", "write the %s forms accepting the REP prefixes and their semantics as JSON to `file`", []*RepForm{}, x86RepForm, nil)
+}
+
+// RepPrefix represents the REP prefixes accepted by an x86 instruction form and their semantics.
+//
+// The repeated form executes while the counter isn't zero, decrementing it after every iteration,
+// and doesn't execute nor modify the flags if the counter is initially zero.
+type RepPrefix struct {
+	// Prefixes is the list of the accepted prefixes, "REP", or "REPE" of the forms comparing the elements,
+	// like "cmpsb", and "REPNE".
+	Prefixes []string `json:"prefixes"`
+
+	// Counter is the implicit counter register sized by the address size, "zcx" of "cx", "ecx" and "rcx",
+	// or empty if the prefixes are ignored.
+	Counter string `json:"counter,omitzero"`
+
+	// Condition is the flag ending the iterations of "REPE" if it's clear and of "REPNE" if it's set, "FLAGS.ZF",
+	// or empty if the form ends only by the counter, like "movsb".
+	Condition string `json:"condition,omitzero"`
+
+	// Ignored reports whether the prefixes have no effect, like the BND prefix of the branches.
+	Ignored bool `json:"ignored,omitzero"`
+}
+
+// RepPrefix returns the REP prefixes accepted by the instruction and their semantics, or nil if the form doesn't accept them.
+//
+// The prefixes are derived from the "REP", "REPNE" and "RepIgnored" attributes of the metadata, and the condition
+// from the zero flag written by the form.
+func (x *X86) RepPrefix(inst *X86Instruction) *RepPrefix {
+	meta := x.ParseMetadata(inst.Metadata)
+	_, rep := meta.Attributes["REP"]
+	_, repne := meta.Attributes["REPNE"]
+	if !rep && !repne {
+		return nil
+	}
+	_, ignored := meta.Attributes["RepIgnored"]
+
+	r := &RepPrefix{Prefixes: []string{}, Ignored: ignored}
+	if !ignored {
+		r.Counter = "zcx"
+		if meta.SpecialRegs["FLAGS.ZF"] == "W" {
+			r.Condition = "FLAGS.ZF"
+		}
+	}
+	switch {
+	case rep && r.Condition != "":
+		r.Prefixes = append(r.Prefixes, "REPE")
+	case rep:
+		r.Prefixes = append(r.Prefixes, "REP")
+	}
+	if repne {
+		r.Prefixes = append(r.Prefixes, "REPNE")
+	}
+	return r
+}
+
+// RepForm represents an x86 instruction form accepting the REP prefixes.
+type RepForm struct {
+	Name     string     `json:"name"`
+	Operands string     `json:"operands,omitzero"`
+	Encoding string     `json:"encoding"`
+	OpCode   string     `json:"opcode"`
+	Rep      *RepPrefix `json:"rep"`
+}
+
+// x86RepForm returns the form of inst if it accepts the REP prefixes, or nil.
+func x86RepForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	if r := x.RepPrefix(inst); r != nil {
+		return &RepForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Rep: r}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86RepPrefix(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *RepPrefix
+	}{
+		{"movsb", "W:<es:zdi>, R:<ds:zsi>", &RepPrefix{Prefixes: []string{"REP", "REPNE"}, Counter: "zcx"}},
+		{"insb", "W:es:zdi, dx", &RepPrefix{Prefixes: []string{"REP", "REPNE"}, Counter: "zcx"}},
+		// the forms comparing the elements
+		{"cmpsb", "R:<ds:zsi>, R:<es:zdi>", &RepPrefix{Prefixes: []string{"REPE", "REPNE"}, Counter: "zcx", Condition: "FLAGS.ZF"}},
+		{"scasb", "R:<al>, R:<es:zdi>", &RepPrefix{Prefixes: []string{"REPE", "REPNE"}, Counter: "zcx", Condition: "FLAGS.ZF"}},
+		// the ignored prefixes
+		{"ret", "uw", &RepPrefix{Prefixes: []string{"REP", "REPNE"}, Ignored: true}},
+		{"jmp", "rel8", &RepPrefix{Prefixes: []string{"REPNE"}, Ignored: true}},
+		{"add", "X:~r32/m32,~r32", nil},
+	}
+	for _, tt := range tests {
+		if got := x86.X86.RepPrefix(testX86Form(t, tt.name, tt.operands)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RepPrefix(%s %s) = %+v, want %+v", tt.name, tt.operands, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect of the generated schemas.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema represents the JSON Schema of a JSON output, or of a part of it, in the subset the Go types need.
+type JSONSchema struct {
+	Schema string `json:"$schema,omitzero"`
+	Title  string `json:"title,omitzero"`
+	Ref    string `json:"$ref,omitzero"`
+
+	// Type is the list of the JSON types of the value, like "object" and "null".
+	Type jsonSchemaTypes `json:"type,omitzero"`
+
+	// Properties and Required are the members of the object in the order of the Go struct fields,
+	// and the names of the members which are always written.
+	Properties jsonSchemaProps `json:"properties,omitzero"`
+	Required   []string        `json:"required,omitzero"`
+
+	// AdditionalProperties is the *JSONSchema of the values of the object of the Go map,
+	// or false for the Go struct whose objects have no other members.
+	AdditionalProperties interface{} `json:"additionalProperties,omitzero"`
+
+	Items *JSONSchema `json:"items,omitzero"`
+
+	// AnyOf is the list of the schemas of which the value follows at least one, like the reference and null
+	// of the pointer to the named struct type.
+	AnyOf []*JSONSchema `json:"anyOf,omitzero"`
+
+	// Defs is the schemas of the named Go struct types referenced by Ref, like "#/$defs/ArmOperand".
+	Defs jsonSchemaProps `json:"$defs,omitzero"`
+}
+
+// jsonSchemaTypes is the JSON types of the schema, marshaled as the single string if there's one.
+type jsonSchemaTypes []string
+
+// MarshalNextJSON implements json.MarshalerV2.
+func (t jsonSchemaTypes) MarshalNextJSON(enc *json.Encoder, opts json.MarshalOptions) error {
+	if len(t) == 1 {
+		return enc.WriteToken(json.String(t[0]))
+	}
+	return opts.MarshalNext(enc, []string(t))
+}
+
+// jsonSchemaProp is the named schema of the property or the definition.
+type jsonSchemaProp struct {
+	name   string
+	schema *JSONSchema
+}
+
+// jsonSchemaProps is the list of the named schemas marshaled as the JSON object of the members in the order of the list.
+type jsonSchemaProps []*jsonSchemaProp
+
+// MarshalNextJSON implements json.MarshalerV2.
+func (p jsonSchemaProps) MarshalNextJSON(enc *json.Encoder, opts json.MarshalOptions) error {
+	if err := enc.WriteToken(json.ObjectStart); err != nil {
+		return err
+	}
+	for _, prop := range p {
+		if err := enc.WriteToken(json.String(prop.name)); err != nil {
+			return err
+		}
+		if err := opts.MarshalNext(enc, prop.schema); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(json.ObjectEnd)
+}
+
+// lookup returns the schema of the name, or nil.
+func (p jsonSchemaProps) lookup(name string) *JSONSchema {
+	for _, prop := range p {
+		if prop.name == name {
+			return prop.schema
+		}
+	}
+	return nil
+}
+
+// newJSONSchema returns the JSON Schema of the JSON encoding of the Go type of v with the title.
+func newJSONSchema(title string, v interface{}) *JSONSchema {
+	defs := make(map[string]*JSONSchema)
+	s := jsonSchemaOf(reflect.TypeOf(v), defs)
+	s.Schema, s.Title = jsonSchemaDraft, title
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.Defs = append(s.Defs, &jsonSchemaProp{name: name, schema: defs[name]})
+	}
+	return s
+}
+
+// jsonSchemaOf returns the schema of the JSON encoding of the type t. The schemas of the named struct types
+// are added to defs and referenced, so the recursive types, like ArmOperand, are described once.
+//
+// The nil pointers, slices and maps are encoded as null, so their schemas accept null too.
+func jsonSchemaOf(t reflect.Type, defs map[string]*JSONSchema) *JSONSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := jsonSchemaOf(t.Elem(), defs)
+		switch {
+		case s.Ref != "":
+			return &JSONSchema{AnyOf: []*JSONSchema{s, {Type: jsonSchemaTypes{"null"}}}}
+		case len(s.Type) > 0 && !containsString(s.Type, "null"):
+			s.Type = append(s.Type, "null")
+		}
+		return s
+	case reflect.Bool:
+		return &JSONSchema{Type: jsonSchemaTypes{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: jsonSchemaTypes{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: jsonSchemaTypes{"number"}}
+	case reflect.String:
+		return &JSONSchema{Type: jsonSchemaTypes{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// the bytes are encoded as the base64 string
+			return &JSONSchema{Type: jsonSchemaTypes{"string", "null"}}
+		}
+		return &JSONSchema{Type: jsonSchemaTypes{"array", "null"}, Items: jsonSchemaOf(t.Elem(), defs)}
+	case reflect.Array:
+		return &JSONSchema{Type: jsonSchemaTypes{"array"}, Items: jsonSchemaOf(t.Elem(), defs)}
+	case reflect.Map:
+		return &JSONSchema{Type: jsonSchemaTypes{"object", "null"}, AdditionalProperties: jsonSchemaOf(t.Elem(), defs)}
+	case reflect.Interface:
+		return &JSONSchema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return jsonSchemaStruct(t, defs)
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // the recursive references see the definition in progress
+			defs[t.Name()] = jsonSchemaStruct(t, defs)
+		}
+		return &JSONSchema{Ref: "#/$defs/" + t.Name()}
+	}
+	panic(fmt.Sprintf("no JSON schema of the type %s", t))
+}
+
+// jsonSchemaStruct returns the schema of the object of the struct type t, the members named by the json tags.
+// The members of the fields without omitzero and omitempty are required.
+func jsonSchemaStruct(t reflect.Type, defs map[string]*JSONSchema) *JSONSchema {
+	s := &JSONSchema{Type: jsonSchemaTypes{"object"}, Properties: jsonSchemaProps{}, AdditionalProperties: false}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties = append(s.Properties, &jsonSchemaProp{name: name, schema: jsonSchemaOf(f.Type, defs)})
+		if !strings.Contains(opts, "omitzero") && !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// writeJSONSchema writes the JSON Schema s as JSON to w.
+func writeJSONSchema(w io.Writer, s *JSONSchema) error {
+	return writeJSON(w, s.Title+" schema", s)
+}
+
+// validateJSON returns the error of the JSON data which doesn't follow the schema s, the first violation
+// with its JSON Pointer, like "/3/operands/0: got string, want object", or nil.
+func validateJSON(data []byte, s *JSONSchema) error {
+	// the outputs are marshaled from the Go values, so the slow check of the duplicate member names is skipped
+	var v interface{}
+	if err := (json.UnmarshalOptions{}).UnmarshalFull(json.DecodeOptions{AllowDuplicateNames: true}, bytes.NewReader(data), &v); err != nil {
+		return err
+	}
+	return s.validate(s, "", v)
+}
+
+// validate returns the error of the value v at the JSON Pointer ptr which doesn't follow s, root is the schema of the document.
+func (s *JSONSchema) validate(root *JSONSchema, ptr string, v interface{}) error {
+	if s.Ref != "" {
+		def := root.Defs.lookup(strings.TrimPrefix(s.Ref, "#/$defs/"))
+		if def == nil {
+			return fmt.Errorf("%s: undefined %s", ptr, s.Ref)
+		}
+		return def.validate(root, ptr, v)
+	}
+	if len(s.AnyOf) > 0 {
+		var first error
+		for _, as := range s.AnyOf {
+			err := as.validate(root, ptr, v)
+			if err == nil {
+				return nil
+			}
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+
+	var typ string
+	switch x := v.(type) {
+	case nil:
+		typ = "null"
+	case bool:
+		typ = "boolean"
+	case float64:
+		typ = "number"
+		if x == float64(int64(x)) && containsString(s.Type, "integer") {
+			typ = "integer"
+		}
+	case string:
+		typ = "string"
+	case []interface{}:
+		typ = "array"
+	case map[string]interface{}:
+		typ = "object"
+	}
+	if len(s.Type) > 0 && !containsString(s.Type, typ) {
+		return fmt.Errorf("%s: got %s, want %s", ptr, typ, strings.Join(s.Type, " or "))
+	}
+
+	switch x := v.(type) {
+	case []interface{}:
+		if s.Items != nil {
+			for i, e := range x {
+				if err := s.Items.validate(root, fmt.Sprintf("%s/%d", ptr, i), e); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := x[name]; !ok {
+				return fmt.Errorf("%s: missing required member %q", ptr, name)
+			}
+		}
+		names := make([]string, 0, len(x))
+		for name := range x {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ps := s.Properties.lookup(name)
+			if ps == nil {
+				switch as := s.AdditionalProperties.(type) {
+				case *JSONSchema:
+					ps = as
+				case bool:
+					if !as {
+						return fmt.Errorf("%s: unknown member %q", ptr, name)
+					}
+				}
+			}
+			if ps == nil {
+				continue
+			}
+			if err := ps.validate(root, ptr+"/"+jsonPointerEscape(name), x[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonPointerEscape escapes the reference token of the JSON Pointer, "~" as "~0" and "/" as "~1".
+func jsonPointerEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,92 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("segments", "segments.json", "write the segments of the %s memory operands and their overrides as JSON to `file`", []*SegmentForm{}, x86SegmentForm, nil)
+}
+
+// SegmentUse represents the segment of a memory operand of an x86 instruction form.
+//
+// In the 64-bit mode the "cs", "ds", "es" and "ss" segments have the zero base, so only the "fs" and "gs"
+// overrides change the address.
+type SegmentUse struct {
+	// Operand is the index of the memory operand, or -1 for the memory without the operand,
+	// like the stack of "push" and the table of "xlatb".
+	Operand int `json:"operand"`
+
+	// Segment is the default segment, "ds" of the encoded memory, or "ss" if its base is "rsp" or "rbp",
+	// the implicit segment, like "es" of "<es:zdi>", or "ss" of the stack.
+	Segment string `json:"segment"`
+
+	// Overridable reports whether the segment prefix overrides the default segment.
+	// The "es" of the string destinations and the stack can't be overridden.
+	Overridable bool `json:"overridable,omitzero"`
+}
+
+// x86ImplicitSegments is the segment of the implicit memory of the forms without the memory operand.
+var x86ImplicitSegments = map[string]string{
+	"xlatb": "ds",
+}
+
+// x86OffsetNames is the list of the forms whose memory operand is an offset which isn't translated by the segment,
+// like "lea" and the bound checks.
+var x86OffsetNames = map[string]bool{
+	"lea": true, "nop": true, "bndcl": true, "bndcn": true, "bndcu": true, "bndmk": true,
+}
+
+// Segments returns the segments of the memory operands of the instruction, or nil if the form doesn't address memory.
+//
+// The segments are derived from the "seg:reg" operands, like "<es:zdi>", and the stack accesses.
+func (x *X86) Segments(inst *X86Instruction) ([]*SegmentUse, error) {
+	name := inst.Names()[0]
+	if x86OffsetNames[name] {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	var segs []*SegmentUse
+	for _, op := range ops {
+		kind := op.Kinds[0]
+		switch {
+		case strings.Contains(kind, ":"):
+			seg := kind[:strings.IndexByte(kind, ':')]
+			segs = append(segs, &SegmentUse{Operand: op.Index, Segment: seg, Overridable: seg != "es"})
+		case op.IsMem():
+			segs = append(segs, &SegmentUse{Operand: op.Index, Segment: "ds", Overridable: true})
+		}
+	}
+	if _, ok := x86StackAccesses[name]; ok {
+		segs = append(segs, &SegmentUse{Operand: -1, Segment: "ss"})
+	}
+	if seg, ok := x86ImplicitSegments[name]; ok {
+		segs = append(segs, &SegmentUse{Operand: -1, Segment: seg, Overridable: true})
+	}
+	return segs, nil
+}
+
+// SegmentForm represents an x86 instruction form addressing memory.
+type SegmentForm struct {
+	Name     string        `json:"name"`
+	Operands string        `json:"operands,omitzero"`
+	Encoding string        `json:"encoding"`
+	OpCode   string        `json:"opcode"`
+	Segments []*SegmentUse `json:"segments"`
+}
+
+// x86SegmentForm returns the form of inst if it addresses memory, or nil.
+func x86SegmentForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	segs, err := x.Segments(inst)
+	if err != nil || len(segs) == 0 {
+		return nil, err
+	}
+	return &SegmentForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Segments: segs}, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86Segments(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     []*SegmentUse
+	}{
+		{"add", "X:~r32/m32,~r32", []*SegmentUse{{Operand: 0, Segment: "ds", Overridable: true}}},
+		// the "seg:reg" operands
+		{"movsb", "W:<es:zdi>, R:<ds:zsi>", []*SegmentUse{
+			{Operand: 0, Segment: "es"},
+			{Operand: 1, Segment: "ds", Overridable: true},
+		}},
+		{"insd", "W:es:zdi, dx", []*SegmentUse{{Operand: 0, Segment: "es"}}},
+		// x86StackAccesses
+		{"push", "R:r32/m32", []*SegmentUse{
+			{Operand: 0, Segment: "ds", Overridable: true},
+			{Operand: -1, Segment: "ss"},
+		}},
+		// x86ImplicitSegments
+		{"xlatb", "", []*SegmentUse{{Operand: -1, Segment: "ds", Overridable: true}}},
+		// x86OffsetNames
+		{"lea", "W:r32, mem", nil},
+		{"nop", "R:r32/m32", nil},
+		{"bndcl", "R:bnd, r64/m64", nil},
+		{"mov", "W:creg, r64", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.Segments(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Segments(%s %s) = %s, %v, want %s", tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
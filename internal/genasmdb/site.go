@@ -0,0 +1,233 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// SiteForm represents an instruction form on the mnemonic page of the site.
+type SiteForm struct {
+	Name     string
+	Operands string
+
+	// Encoding is the operand encoding of the x86 form, like "RVM-FV", or the instruction set of the ARM form, like "T32".
+	Encoding string
+
+	OpCode     string
+	Extensions []string
+	Metadata   string
+}
+
+// SitePage represents the page of a mnemonic of the site.
+type SitePage struct {
+	Arch     string
+	Mnemonic string
+
+	// Extensions is the sorted list of the extensions of the forms, "none" for the forms requiring no extension.
+	Extensions []string
+
+	Forms []*SiteForm
+}
+
+// SiteExtension represents an extension and its mnemonics on the index page of the site.
+type SiteExtension struct {
+	Name      string
+	Mnemonics []string
+}
+
+// SiteIndex represents the index page of the site of an architecture.
+type SiteIndex struct {
+	Arch       string
+	Source     string
+	Extensions []*SiteExtension
+}
+
+// SiteSearchEntry represents a mnemonic in the search index of the site.
+type SiteSearchEntry struct {
+	Mnemonic   string   `json:"mnemonic"`
+	URL        string   `json:"url"`
+	Extensions []string `json:"extensions"`
+}
+
+// siteTemplates is the templates of the index and the mnemonic pages of the site.
+var siteTemplates = template.Must(template.New("site").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+code, td { font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.5em; text-align: left; }
+ul.mnemonics { columns: 8em; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "root"}}{{template "head" "Instructions"}}<h1>Instructions</h1>
+<ul>
+{{- range .}}
+<li><a href="{{.}}/index.html">{{.}}</a></li>
+{{- end}}
+</ul>
+</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "head" printf "%s instructions" .Arch}}<p><a href="../index.html">Instructions</a></p>
+<h1>{{.Arch}} instructions</h1>
+<p>Generated from {{.Source}}. The search index of the mnemonics is <a href="search.json">search.json</a>.</p>
+<h2>Extensions</h2>
+<ul>
+{{- range .Extensions}}
+<li><a href="#ext-{{.Name}}">{{.Name}}</a> ({{len .Mnemonics}})</li>
+{{- end}}
+</ul>
+{{- range .Extensions}}
+<h2 id="ext-{{.Name}}">{{.Name}}</h2>
+<ul class="mnemonics">
+{{- range .Mnemonics}}
+<li><a href="{{.}}.html">{{.}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+{{end}}
+
+{{define "page"}}{{template "head" printf "%s - %s instructions" .Mnemonic .Arch}}<p><a href="../index.html">Instructions</a> / <a href="index.html">{{.Arch}}</a></p>
+<h1>{{.Mnemonic}}</h1>
+<p>Extensions:
+{{- range .Extensions}} <a href="index.html#ext-{{.}}">{{.}}</a>{{end}}</p>
+<table>
+<tr><th>Name</th><th>Operands</th><th>Encoding</th><th>Opcode</th><th>Metadata</th></tr>
+{{- range .Forms}}
+<tr><td>{{.Name}}</td><td>{{.Operands}}</td><td>{{.Encoding}}</td><td>{{.OpCode}}</td><td>{{.Metadata}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+{{end}}
+`))
+
+// sitePages returns the mnemonic pages of the forms of m in the sorted order of the mnemonics.
+//
+// The x86 form of several names is on the page of each name. The ARM mnemonics are lowercased,
+// like the completion data, so the flag setting "adcS" is on the page of "adcs".
+func sitePages(m *Model) []*SitePage {
+	pages := make(map[string]*SitePage)
+	add := func(mnemonic string, form *SiteForm) {
+		p := pages[mnemonic]
+		if p == nil {
+			p = &SitePage{Arch: m.Arch, Mnemonic: mnemonic}
+			pages[mnemonic] = p
+		}
+		p.Forms = append(p.Forms, form)
+		exts := form.Extensions
+		if len(exts) == 0 {
+			exts = []string{"none"}
+		}
+		for _, ext := range exts {
+			if !containsString(p.Extensions, ext) {
+				p.Extensions = append(p.Extensions, ext)
+			}
+		}
+	}
+
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		form := &SiteForm{
+			Name:       inst.Name,
+			Operands:   inst.Operands,
+			Encoding:   inst.Encoding,
+			OpCode:     inst.OpCode,
+			Extensions: m.X86.ParseMetadata(inst.Metadata).Extensions,
+			Metadata:   inst.Metadata,
+		}
+		for _, name := range inst.Names() {
+			add(name, form)
+		}
+	}
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		mnemonic, _ := splitArmName(inst.Name)
+		add(strings.ToLower(mnemonic), &SiteForm{
+			Name:       inst.Name,
+			Operands:   inst.Operands,
+			Encoding:   inst.Arch,
+			OpCode:     inst.OpCode,
+			Extensions: m.Arm.ParseMetadata(inst.Metadata).Extensions,
+			Metadata:   inst.Metadata,
+		})
+	}
+
+	list := make([]*SitePage, 0, len(pages))
+	for _, p := range pages {
+		sort.Strings(p.Extensions)
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Mnemonic < list[j].Mnemonic })
+	return list
+}
+
+// writeSite writes the static HTML site of the forms of m into dir, the index page of the mnemonics by extension,
+// the page of each mnemonic with its forms and the search index of the mnemonics as JSON.
+func writeSite(dir string, m *Model) error {
+	pages := sitePages(m)
+
+	index := &SiteIndex{Arch: m.Arch, Source: m.Source}
+	exts := make(map[string]*SiteExtension)
+	var search []*SiteSearchEntry
+	for _, p := range pages {
+		for _, name := range p.Extensions {
+			ext := exts[name]
+			if ext == nil {
+				ext = &SiteExtension{Name: name}
+				exts[name] = ext
+				index.Extensions = append(index.Extensions, ext)
+			}
+			ext.Mnemonics = append(ext.Mnemonics, p.Mnemonic)
+		}
+		search = append(search, &SiteSearchEntry{Mnemonic: p.Mnemonic, URL: p.Mnemonic + ".html", Extensions: p.Extensions})
+
+		if err := writeFile(filepath.Join(dir, p.Mnemonic+".html"), func(w io.Writer) error {
+			return siteTemplates.ExecuteTemplate(w, "page", p)
+		}); err != nil {
+			return err
+		}
+	}
+	sort.Slice(index.Extensions, func(i, j int) bool { return index.Extensions[i].Name < index.Extensions[j].Name })
+
+	if err := writeFile(filepath.Join(dir, "index.html"), func(w io.Writer) error {
+		return siteTemplates.ExecuteTemplate(w, "index", index)
+	}); err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(dir, "search.json"), func(w io.Writer) error {
+		if err := (json.MarshalOptions{}).MarshalFull(json.EncodeOptions{}, w, search); err != nil {
+			return fmt.Errorf("marshal %s search index: %w", m.Arch, err)
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+}
+
+// writeSiteRoot writes the root index page of the site in dir linking the sites of archs in their subdirectories.
+func writeSiteRoot(dir string, archs []string) error {
+	return writeFile(filepath.Join(dir, "index.html"), func(w io.Writer) error {
+		return siteTemplates.ExecuteTemplate(w, "root", archs)
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("stack", "stack.json", "write the %s forms changing the stack pointer and their stack effect as JSON to `file`", []*StackEffectForm{}, x86StackEffectForm, armStackEffectForm)
+}
+
+// StackEffect represents the change of the stack pointer by an instruction form, like "push" and "ret".
+type StackEffect struct {
+	// Deltas is the list of the fixed changes of the stack pointer in the modes of the form.
+	Deltas []*StackDelta `json:"deltas"`
+
+	// Operand is the index of the operand adding to the fixed change, or -1 if the change is fixed.
+	// It's the released bytes of "ret uw" and "retf uw", the allocated bytes of "enter" followed by its nesting level,
+	// each level pushing a frame pointer, and the register list of the ARM "push" and "pop", 4 bytes per register.
+	Operand int `json:"operand"`
+
+	// Frame reports whether the stack pointer is loaded from the frame pointer before the change, like "leave".
+	Frame bool `json:"frame,omitzero"`
+}
+
+// StackDelta represents the fixed change of the stack pointer in a mode.
+type StackDelta struct {
+	// Mode is the processor mode bits, 32 or 64.
+	Mode int `json:"mode"`
+
+	// Bytes is the change of the stack pointer in bytes, negative for the pushes.
+	Bytes int `json:"bytes"`
+}
+
+// x86StackSlots is the number of the stack slots pushed, negative, or popped by the forms changing the stack pointer.
+// The interrupts, like "int", change the stack of the handler, so they aren't listed.
+var x86StackSlots = map[string]int{
+	"push": -1, "pushf": -1, "pushfd": -1, "pushfq": -1, "pusha": -8, "pushad": -8,
+	"pop": 1, "popf": 1, "popfd": 1, "popfq": 1, "popa": 8, "popad": 8,
+	"call": -1, "lcall": -2, "ret": 1, "retf": 2, "iret": 3, "iretd": 3, "iretq": 3,
+	"enter": -1, "leave": 1,
+}
+
+// x86FarStackNames is the list of the far transfers, whose stack slots default to 32 bits in the 64-bit mode.
+var x86FarStackNames = map[string]bool{
+	"lcall": true, "retf": true, "iret": true, "iretd": true, "iretq": true,
+}
+
+// StackEffect returns the change of the stack pointer by the instruction, or nil if the form doesn't change it.
+//
+// The size of the stack slot is the operand size of the form, 16 bits with the 0x66 prefix, 64 bits with REX.W,
+// the width of the register operand, like "push r16", or the default of the mode. The "iret" forms return
+// to the same privilege, popping the stack pointer and the stack segment only in the 64-bit mode.
+func (x *X86) StackEffect(inst *X86Instruction) (*StackEffect, error) {
+	name := inst.Names()[0]
+	slots, ok := x86StackSlots[name]
+	if !ok {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+	op, err := inst.ParseOpCode()
+	if err != nil {
+		return nil, err
+	}
+
+	size := 0
+	switch {
+	case bytes.IndexByte(op.Prefixes, 0x66) >= 0:
+		size = 2
+	case op.REXW:
+		size = 8
+	}
+	for _, o := range ops {
+		for _, kind := range o.Kinds {
+			if w := x86RegWidths[x86RegClass(kind)]; size == 0 && w > 0 {
+				size = w / 8
+			}
+		}
+	}
+
+	se := &StackEffect{Operand: -1, Frame: name == "leave"}
+	for _, mode := range x86FormModes(x, inst) {
+		n, s := slots, size
+		if s == 0 {
+			s = mode / 8
+			if x86FarStackNames[name] {
+				s = 4
+			}
+		}
+		if strings.HasPrefix(name, "iret") && mode == 64 {
+			n = 5
+		}
+		se.Deltas = append(se.Deltas, &StackDelta{Mode: mode, Bytes: n * s})
+	}
+	switch name {
+	case "ret", "retf", "enter":
+		if len(ops) > 0 {
+			se.Operand = 0
+		}
+	}
+	return se, nil
+}
+
+// StackEffect returns the change of the stack pointer by the instruction, or nil if the form doesn't change it.
+//
+// Only "push" and "pop" are modeled, the loads and stores writing back SP, like "ldm sp!", change it by the base register.
+func (a *Arm) StackEffect(inst *ArmInstruction) (*StackEffect, error) {
+	mnemonic, _ := splitArmName(inst.Name)
+	mnemonic = strings.ToLower(mnemonic)
+	sign := 0
+	switch mnemonic {
+	case "push":
+		sign = -1
+	case "pop":
+		sign = 1
+	default:
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	se := &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: sign * 4}}, Operand: -1}
+	for _, op := range ops {
+		if op.Type == ArmOperandRegList {
+			se.Deltas[0].Bytes, se.Operand = 0, op.Index
+		}
+	}
+	return se, nil
+}
+
+// StackEffectForm represents an instruction form changing the stack pointer.
+type StackEffectForm struct {
+	Name     string `json:"name"`
+	Operands string `json:"operands,omitzero"`
+
+	// Encoding is the operand encoding of the x86 form, like "O", or the instruction set of the ARM form, like "T32".
+	Encoding string `json:"encoding"`
+
+	OpCode string       `json:"opcode"`
+	Effect *StackEffect `json:"effect"`
+}
+
+// x86StackEffectForm returns the form of inst if it changes the stack pointer, or nil.
+func x86StackEffectForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	se, err := x.StackEffect(inst)
+	if err != nil || se == nil {
+		return nil, err
+	}
+	return &StackEffectForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Effect: se}, nil
+}
+
+// armStackEffectForm returns the form of inst if it changes the stack pointer, or nil.
+func armStackEffectForm(a *Arm, inst *ArmInstruction) (interface{}, error) {
+	se, err := a.StackEffect(inst)
+	if err != nil || se == nil {
+		return nil, err
+	}
+	return &StackEffectForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Arch, OpCode: inst.OpCode, Effect: se}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86StackEffect(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *StackEffect
+	}{
+		// the operand size of the prefix, the register and the mode
+		{"push", "R:r16/m16", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: -2}, {Mode: 64, Bytes: -2}}, Operand: -1}},
+		{"push", "R:r32/m32", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: -4}}, Operand: -1}},
+		{"pop", "W:r64/m64", &StackEffect{Deltas: []*StackDelta{{Mode: 64, Bytes: 8}}, Operand: -1}},
+		{"call", "rel32", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: -4}, {Mode: 64, Bytes: -8}}, Operand: -1}},
+		// x86StackSlots
+		{"pusha", "", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: -16}}, Operand: -1}},
+		{"enter", "iw/uw, ib/ub", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: -4}, {Mode: 64, Bytes: -8}}, Operand: 0}},
+		{"leave", "", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: 4}, {Mode: 64, Bytes: 8}}, Operand: -1, Frame: true}},
+		// x86FarStackNames
+		{"retf", "uw", &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: 8}, {Mode: 64, Bytes: 8}}, Operand: 0}},
+		{"iretq", "", &StackEffect{Deltas: []*StackDelta{{Mode: 64, Bytes: 40}}, Operand: -1}},
+		{"add", "X:~r32/m32,~r32", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.StackEffect(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("StackEffect(%s %s) = %s, %v, want %s", tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
+
+func TestArmStackEffect(t *testing.T) {
+	_, arm := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		arch     string
+		want     *StackEffect
+	}{
+		{"push", "Rs!=SP", ArmA32, &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: -4}}, Operand: -1}},
+		{"pop", "Rd!=SP", ArmT32, &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: 4}}, Operand: -1}},
+		// the register lists
+		{"push", "RsList", ArmT32, &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: 0}}, Operand: 0}},
+		{"pop", "RdList", ArmT16, &StackEffect{Deltas: []*StackDelta{{Mode: 32, Bytes: 0}}, Operand: 0}},
+		{"ldr", "Rd!=HI, [Rn!=HI, #ImmZ*4]", ArmT16, nil},
+	}
+	for _, tt := range tests {
+		got, err := arm.Arm.StackEffect(testArmForm(t, tt.name, tt.operands, tt.arch))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("StackEffect(%s %s %s) = %s, %v, want %s", tt.arch, tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func init() {
+	for _, arch := range []string{"x86", "arm"} {
+		registerEmitter(arch, "json", "stats.json", "write the statistics of the "+arch+" forms per extension, encoding and opcode map JSON to `file`", &emitterFunc{name: arch + "-stats", fn: func(m *Model, w io.Writer) error {
+			return writeStats(w, m)
+		}, out: Stats{}})
+	}
+}
+
+// Stats represents the statistics of the instruction forms of an architecture.
+type Stats struct {
+	Arch string `json:"arch"`
+
+	// Forms is the number of the instruction forms.
+	Forms int `json:"forms"`
+
+	// Mnemonics is the number of the distinct mnemonics of the forms.
+	Mnemonics int `json:"mnemonics"`
+
+	// Extensions is the number of the forms of each extension, the forms requiring no extension as "none".
+	Extensions []*StatsCount `json:"extensions"`
+
+	// Encodings is the number of the forms of each encoding class, the x86 operand encoding like "RVM",
+	// or the ARM instruction set like "T32".
+	Encodings []*StatsCount `json:"encodings"`
+
+	// OpCodeMaps is the number of the x86 forms of each opcode map, like "0F38" and "EVEX.MAP5",
+	// the one-byte legacy opcodes as "none".
+	OpCodeMaps []*StatsCount `json:"opcode_maps,omitzero"`
+
+	// FormsPerMnemonic is the distribution of the number of the forms of a mnemonic, in the increasing order of forms.
+	FormsPerMnemonic []*MnemonicCount `json:"forms_per_mnemonic"`
+}
+
+// StatsCount represents the number of the forms of the name.
+type StatsCount struct {
+	Name  string `json:"name"`
+	Forms int    `json:"forms"`
+}
+
+// MnemonicCount represents the number of the mnemonics having the number of forms.
+type MnemonicCount struct {
+	Forms     int `json:"forms"`
+	Mnemonics int `json:"mnemonics"`
+}
+
+// newStats returns the statistics of the forms of m.
+func newStats(m *Model) (*Stats, error) {
+	encs := make(map[string]int)
+	maps := make(map[string]int)
+	mnemonics := make(map[string]int)
+
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		encs[strings.SplitN(inst.Encoding, "-", 2)[0]]++
+		op, err := inst.ParseOpCode()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", inst.Name, inst.Operands, err)
+		}
+		maps[op.opCodeMap()]++
+		for _, name := range inst.Names() {
+			mnemonics[name]++
+		}
+	}
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		encs[inst.Arch]++
+		mnemonics[inst.Name]++
+	}
+
+	perMnemonic := make(map[int]int)
+	for _, n := range mnemonics {
+		perMnemonic[n]++
+	}
+	var dist []*MnemonicCount
+	for forms, n := range perMnemonic {
+		dist = append(dist, &MnemonicCount{Forms: forms, Mnemonics: n})
+	}
+	sort.Slice(dist, func(i, j int) bool { return dist[i].Forms < dist[j].Forms })
+
+	return &Stats{
+		Arch:             m.Arch,
+		Forms:            len(m.X86Instructions) + len(m.ArmInstructions),
+		Mnemonics:        len(mnemonics),
+		Extensions:       statsCounts(countExtensions(m)),
+		Encodings:        statsCounts(encs),
+		OpCodeMaps:       statsCounts(maps),
+		FormsPerMnemonic: dist,
+	}, nil
+}
+
+// countExtensions returns the number of the forms of m of each extension, the forms requiring no extension as "none".
+func countExtensions(m *Model) map[string]int {
+	exts := make(map[string]int)
+	count := func(names []string) {
+		if len(names) == 0 {
+			exts["none"]++
+		}
+		for _, ext := range names {
+			exts[ext]++
+		}
+	}
+	for _, inst := range m.X86Instructions {
+		count(m.X86.ParseMetadata(inst.Metadata).Extensions)
+	}
+	for _, inst := range m.ArmInstructions {
+		count(m.Arm.ParseMetadata(inst.Metadata).Extensions)
+	}
+	return exts
+}
+
+// statsCounts returns the counts of the names of counts in the sorted order of the names.
+func statsCounts(counts map[string]int) []*StatsCount {
+	var list []*StatsCount
+	for name, n := range counts {
+		list = append(list, &StatsCount{Name: name, Forms: n})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// opCodeMap returns the opcode map of op, like "0F38" for the legacy escape bytes and "EVEX.MAP5" for the prefix,
+// the prefix alone if it has no map, or "none" for the one-byte legacy opcodes.
+func (op *X86OpCode) opCodeMap() string {
+	if op.Prefix != "" && op.Map != "" {
+		return op.Prefix + "." + op.Map
+	}
+	if op.Prefix != "" {
+		return op.Prefix
+	}
+	if len(op.Bytes) < 2 || op.Bytes[0] != 0x0F {
+		return "none"
+	}
+	if len(op.Bytes) > 2 {
+		switch op.Bytes[1] {
+		case 0x38:
+			return "0F38"
+		case 0x3A:
+			return "0F3A"
+		}
+	}
+	return "0F"
+}
+
+// writeStats writes the statistics of the forms of m as JSON to w.
+func writeStats(w io.Writer, m *Model) error {
+	stats, err := newStats(m)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, m.Arch+" stats", stats)
+}
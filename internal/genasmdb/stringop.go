@@ -0,0 +1,99 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+func init() {
+	registerFormsEmitter("strings", "strings.json", "write the %s string operations and their implicit operands as JSON to `file`", []*StringOperationForm{}, x86StringOperationForm, nil)
+}
+
+// StringOperation represents the implicit operands of an x86 string operation, like "movsb" and "scasd".
+//
+// The address registers are incremented by the width of the element if the direction flag is clear
+// and decremented if it's set.
+type StringOperation struct {
+	// Width is the width of the element in bits, the suffix of the name.
+	Width int `json:"width"`
+
+	// Direction is the flag deciding the direction of the address registers, "FLAGS.DF".
+	Direction string `json:"direction"`
+
+	Operands []*StringOperand `json:"operands"`
+}
+
+// StringOperand represents an implicit operand of an x86 string operation.
+type StringOperand struct {
+	// Operand is the index of the operand.
+	Operand int `json:"operand"`
+
+	// Register is the register of the operand, "zsi" and "zdi" sized by the address size for the addresses,
+	// the accumulator, like "al" and "rax", or "dx" of the I/O port.
+	Register string `json:"register"`
+
+	// Segment is the default segment of the address, "ds" of "zsi" and "es" of "zdi", or empty for the registers.
+	Segment string `json:"segment,omitzero"`
+
+	// Overridable reports whether the segment prefix overrides the default segment, only "ds" of "zsi".
+	Overridable bool `json:"overridable,omitzero"`
+
+	Read  bool `json:"read,omitzero"`
+	Write bool `json:"write,omitzero"`
+}
+
+// StringOperation returns the implicit operands of the string operation, or nil if the instruction isn't one.
+func (x *X86) StringOperation(inst *X86Instruction) (*StringOperation, error) {
+	name := inst.Names()[0]
+	width := 0
+	for _, prefix := range x86StringPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) == len(prefix)+1 {
+			width = x86StringWidths[name[len(name)-1]]
+		}
+	}
+	if width == 0 {
+		return nil, nil
+	}
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StringOperation{Width: width, Direction: "FLAGS.DF"}
+	addressed := false
+	for _, op := range ops {
+		kind := op.Kinds[0]
+		so := &StringOperand{Operand: op.Index, Register: kind, Read: op.IsRead(), Write: op.IsWrite()}
+		if i := strings.IndexByte(kind, ':'); i >= 0 {
+			so.Segment, so.Register = kind[:i], kind[i+1:]
+			so.Overridable = so.Segment == "ds"
+			addressed = true
+		}
+		s.Operands = append(s.Operands, so)
+	}
+	if !addressed {
+		// "movsd xmm, xmm/m64" of SSE2 isn't a string operation
+		return nil, nil
+	}
+	return s, nil
+}
+
+// StringOperationForm represents an x86 string operation.
+type StringOperationForm struct {
+	Name      string           `json:"name"`
+	Operands  string           `json:"operands,omitzero"`
+	Encoding  string           `json:"encoding"`
+	OpCode    string           `json:"opcode"`
+	Operation *StringOperation `json:"operation"`
+}
+
+// x86StringOperationForm returns the form of inst if it is a string operation, or nil.
+func x86StringOperationForm(x *X86, inst *X86Instruction) (interface{}, error) {
+	s, err := x.StringOperation(inst)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return &StringOperationForm{Name: inst.Name, Operands: inst.Operands, Encoding: inst.Encoding, OpCode: inst.OpCode, Operation: s}, nil
+}
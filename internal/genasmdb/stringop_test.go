@@ -0,0 +1,45 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestX86StringOperation(t *testing.T) {
+	x86, _ := testModels(t)
+	tests := []struct {
+		name     string
+		operands string
+		want     *StringOperation
+	}{
+		// x86StringPrefixes and x86StringWidths
+		{"movsd", "W:<es:zdi>, R:<ds:zsi>", &StringOperation{Width: 32, Direction: "FLAGS.DF", Operands: []*StringOperand{
+			{Operand: 0, Register: "zdi", Segment: "es", Write: true},
+			{Operand: 1, Register: "zsi", Segment: "ds", Overridable: true, Read: true},
+		}}},
+		{"cmpsw", "R:<ds:zsi>, R:<es:zdi>", &StringOperation{Width: 16, Direction: "FLAGS.DF", Operands: []*StringOperand{
+			{Operand: 0, Register: "zsi", Segment: "ds", Overridable: true, Read: true},
+			{Operand: 1, Register: "zdi", Segment: "es", Read: true},
+		}}},
+		{"lodsq", "W:<rax>, R:<ds:zsi>", &StringOperation{Width: 64, Direction: "FLAGS.DF", Operands: []*StringOperand{
+			{Operand: 0, Register: "rax", Write: true},
+			{Operand: 1, Register: "zsi", Segment: "ds", Overridable: true, Read: true},
+		}}},
+		{"outsw", "R:dx, R:ds:zsi", &StringOperation{Width: 16, Direction: "FLAGS.DF", Operands: []*StringOperand{
+			{Operand: 0, Register: "dx", Read: true},
+			{Operand: 1, Register: "zsi", Segment: "ds", Overridable: true, Read: true},
+		}}},
+		// the forms sharing the names of the string operations
+		{"movsd", "W:xmm[63:0], m64", nil},
+		{"mov", "W:r32, r32/m32", nil},
+	}
+	for _, tt := range tests {
+		got, err := x86.X86.StringOperation(testX86Form(t, tt.name, tt.operands))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("StringOperation(%s %s) = %s, %v, want %s", tt.name, tt.operands, jsonString(got), err, jsonString(tt.want))
+		}
+	}
+}
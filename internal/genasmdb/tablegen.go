@@ -0,0 +1,668 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+func init() {
+	registerEmitter("x86", "json", "tablegen.json", "write the LLVM TableGen records JSON in the llvm-tblgen --dump-json format to `file`", &emitterFunc{name: "x86-tablegen", fn: func(m *Model, w io.Writer) error {
+		return writeTableGen(w, m.X86, m.X86Instructions)
+	}, out: tableGenSchema()})
+}
+
+// tableGenSchema returns the JSON Schema of the llvm-tblgen --dump-json object, the version and the record names
+// of each class followed by the records by their names.
+func tableGenSchema() *JSONSchema {
+	s := newJSONSchema("tablegen", map[string]*TableGenRecord(nil))
+	s.Type = jsonSchemaTypes{"object"}
+	s.Properties = jsonSchemaProps{
+		{name: "!tablegen_json_version", schema: &JSONSchema{Type: jsonSchemaTypes{"integer"}}},
+		{name: "!instanceof", schema: &JSONSchema{
+			Type:                 jsonSchemaTypes{"object"},
+			AdditionalProperties: &JSONSchema{Type: jsonSchemaTypes{"array"}, Items: &JSONSchema{Type: jsonSchemaTypes{"string"}}},
+		}},
+	}
+	s.Required = []string{"!tablegen_json_version", "!instanceof"}
+	return s
+}
+
+// TableGenRecord represents an asmdb x86 instruction form in the shape of the X86 Instruction record
+// of `llvm-tblgen --dump-json`, so it can be compared with the LLVM x86 instruction definitions.
+//
+// The form is split to one record per combination of the explicit operand kinds, like LLVM defines
+// "ADC8rr" and "ADC8mr" for "r8/m8, r8". The record names are not LLVM names, so records should be
+// matched by the opcode, the encoding fields and the operand classes.
+type TableGenRecord struct {
+	Name         string   `json:"!name"`
+	Anonymous    bool     `json:"!anonymous"`
+	Fields       []string `json:"!fields"`
+	Superclasses []string `json:"!superclasses"`
+
+	// AsmString is the assembly string with the $operand references in the "{AT&T|Intel}" variants.
+	AsmString string `json:"AsmString"`
+
+	OutOperandList *TableGenDag `json:"OutOperandList"`
+	InOperandList  *TableGenDag `json:"InOperandList"`
+
+	// Constraints is the tied operand constraints, like "$src1 = $dst".
+	Constraints string `json:"Constraints"`
+
+	// Opcode is the bits<8> of the primary opcode byte in the least significant bit first order.
+	Opcode [8]int `json:"Opcode"`
+
+	Form     *TableGenDef `json:"Form"`
+	OpMap    *TableGenDef `json:"OpMap"`
+	OpPrefix *TableGenDef `json:"OpPrefix"`
+	OpEnc    *TableGenDef `json:"OpEnc"`
+
+	HasREXW      int `json:"hasREX_W"`
+	HasVEX4V     int `json:"hasVEX_4V"`
+	HasVEXL      int `json:"hasVEX_L"`
+	HasEVEXL2    int `json:"hasEVEX_L2"`
+	HasEVEXK     int `json:"hasEVEX_K"`
+	HasEVEXZ     int `json:"hasEVEX_Z"`
+	HasEVEXB     int `json:"hasEVEX_B"`
+	MayLoad      int `json:"mayLoad"`
+	MayStore     int `json:"mayStore"`
+	IsBranch     int `json:"isBranch"`
+	IsCall       int `json:"isCall"`
+	IsReturn     int `json:"isReturn"`
+	IsBarrier    int `json:"isBarrier"`
+	IsTerminator int `json:"isTerminator"`
+
+	// Defs and Uses are the implicitly written and read registers.
+	Defs []*TableGenDef `json:"Defs"`
+	Uses []*TableGenDef `json:"Uses"`
+
+	// AsmdbName, AsmdbOperands, AsmdbEncoding and AsmdbOpCode are the source asmdb instruction form.
+	AsmdbName          string   `json:"AsmdbName"`
+	AsmdbOperands      string   `json:"AsmdbOperands"`
+	AsmdbEncoding      string   `json:"AsmdbEncoding"`
+	AsmdbOpCode        string   `json:"AsmdbOpCode"`
+	AsmdbArchitectures []string `json:"AsmdbArchitectures"`
+	AsmdbExtensions    []string `json:"AsmdbExtensions"`
+}
+
+// TableGenDef represents a reference to the def in the `llvm-tblgen --dump-json` output.
+type TableGenDef struct {
+	Def       string `json:"def"`
+	Kind      string `json:"kind"`
+	Printable string `json:"printable"`
+}
+
+// TableGenDag represents a dag value in the `llvm-tblgen --dump-json` output.
+//
+// Each element of Args is a pair of the operand class and the operand name.
+type TableGenDag struct {
+	Kind     string           `json:"kind"`
+	Operator *TableGenDef     `json:"operator"`
+	Args     [][2]interface{} `json:"args"`
+}
+
+// newTableGenDef returns the TableGenDef of name.
+func newTableGenDef(name string) *TableGenDef {
+	return &TableGenDef{Def: name, Kind: "def", Printable: name}
+}
+
+// newTableGenDag returns the empty TableGenDag with the operator, like "outs" and "ins".
+func newTableGenDag(operator string) *TableGenDag {
+	return &TableGenDag{Kind: "dag", Operator: newTableGenDef(operator), Args: [][2]interface{}{}}
+}
+
+// add adds the operand of the class and name to the dag.
+func (d *TableGenDag) add(class, name string) {
+	d.Args = append(d.Args, [2]interface{}{newTableGenDef(class), name})
+}
+
+// tableGenOpMaps maps the opcode escape bytes and the VEX map to the LLVM OpMap.
+var tableGenOpMaps = map[string]string{
+	"":     "OB",
+	"0F":   "TB",
+	"0F38": "T8",
+	"0F3A": "TA",
+	"0F0F": "ThreeDNow",
+	"MAP5": "T_MAP5",
+	"MAP6": "T_MAP6",
+	"M08":  "XOP8",
+	"M09":  "XOP9",
+	"M0A":  "XOPA",
+}
+
+// tableGenOpPrefixes maps the mandatory prefix to the LLVM OpPrefix.
+var tableGenOpPrefixes = map[string]string{
+	"66": "PD",
+	"F3": "XS",
+	"F2": "XD",
+	"NP": "PS",
+}
+
+// tableGenRegClasses maps the register kind to the LLVM register class.
+var tableGenRegClasses = map[string]string{
+	"r8":    "GR8",
+	"r16":   "GR16",
+	"r32":   "GR32",
+	"r64":   "GR64",
+	"mm":    "VR64",
+	"xmm":   "VR128",
+	"ymm":   "VR256",
+	"zmm":   "VR512",
+	"k":     "VK16",
+	"tmm":   "TILE",
+	"bnd":   "BNDR",
+	"sreg":  "SEGMENT_REG",
+	"creg":  "CONTROL_REG",
+	"dreg":  "DEBUG_REG",
+	"st(i)": "RSTi",
+}
+
+// tableGenMemClasses maps the memory kind to the LLVM memory operand class.
+var tableGenMemClasses = map[string]string{
+	"mem":    "anymem",
+	"mib":    "anymem",
+	"tmem":   "anymem",
+	"m8":     "i8mem",
+	"m16":    "i16mem",
+	"m32":    "i32mem",
+	"m64":    "i64mem",
+	"m128":   "i128mem",
+	"m256":   "i256mem",
+	"m512":   "i512mem",
+	"m16int": "i16mem",
+	"m32int": "i32mem",
+	"m64int": "i64mem",
+	"m32fp":  "f32mem",
+	"m64fp":  "f64mem",
+	"m80fp":  "f80mem",
+	"m80bcd": "f80mem",
+	"m80dec": "f80mem",
+	"m16_16": "opaquemem",
+	"m16_32": "opaquemem",
+	"m16_64": "opaquemem",
+	"moff8":  "offset64_8",
+	"moff16": "offset64_16",
+	"moff32": "offset64_32",
+	"moff64": "offset64_64",
+	"vm32x":  "vx32mem",
+	"vm32y":  "vy32mem",
+	"vm32z":  "vz32mem",
+	"vm64x":  "vx64mem",
+	"vm64y":  "vy64mem",
+	"vm64z":  "vz64mem",
+	"b16":    "f16mem",
+	"b32":    "f32mem",
+	"b64":    "f64mem",
+}
+
+// tableGenImmClasses maps the immediate and relative kind to the LLVM operand class.
+var tableGenImmClasses = map[string]string{
+	"i4":    "u4imm",
+	"u4":    "u4imm",
+	"ib":    "i8imm",
+	"ub":    "u8imm",
+	"iw":    "i16imm",
+	"uw":    "i16imm",
+	"id":    "i32imm",
+	"ud":    "i32imm",
+	"iq":    "i64imm",
+	"uq":    "i64imm",
+	"rel8":  "brtarget8",
+	"rel16": "brtarget16",
+	"rel32": "brtarget32",
+}
+
+// tableGenOperandClass returns the LLVM operand class of the operand kind.
+//
+// The EVEX encoded vector registers use the extended register classes, like "VR128X".
+func tableGenOperandClass(kind string, evex bool) string {
+	if class, ok := tableGenImmClasses[kind]; ok {
+		return class
+	}
+	if class, ok := tableGenMemClasses[kind]; ok {
+		return class
+	}
+	if class, ok := tableGenRegClasses[kind]; ok {
+		if evex && (kind == "xmm" || kind == "ymm") {
+			class += "X"
+		}
+		return class
+	}
+	if i := strings.IndexByte(kind, ':'); i >= 0 {
+		// segment:register, like "es:zdi"
+		return "srcidx"
+	}
+
+	// fixed register, like "al" and "xmm0"
+	return strings.ToUpper(kind)
+}
+
+// writeTableGen writes the TableGen records of insts to w in the `llvm-tblgen --dump-json` format.
+func writeTableGen(w io.Writer, x86 *X86, insts []X86Instruction) error {
+	f, err := NewX86Formatter(X86SyntaxATT, insts)
+	if err != nil {
+		return err
+	}
+
+	var records []*TableGenRecord
+	names := make(map[string]int)
+	for i := range insts {
+		recs, err := newTableGenRecords(x86, f, &insts[i])
+		if err != nil {
+			return err
+		}
+		for _, rec := range recs {
+			// the same kinds can be listed in multiple forms, like the legacy and the VEX forms
+			if n := names[rec.Name]; n > 0 {
+				names[rec.Name]++
+				rec.Name += "_" + strconv.Itoa(n)
+			}
+			names[rec.Name]++
+			records = append(records, rec)
+		}
+	}
+
+	instanceof := make([]string, len(records))
+	for i, rec := range records {
+		instanceof[i] = rec.Name
+	}
+	sort.Strings(instanceof)
+
+	opts := json.EncodeOptions{Indent: "\t"}
+	enc := opts.NewEncoder(w)
+	if err := enc.WriteToken(json.ObjectStart); err != nil {
+		return err
+	}
+	if err := writeTableGenField(enc, "!tablegen_json_version", 1); err != nil {
+		return err
+	}
+	if err := writeTableGenField(enc, "!instanceof", map[string][]string{"X86Inst": instanceof}); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := writeTableGenField(enc, rec.Name, rec); err != nil {
+			return err
+		}
+	}
+
+	return enc.WriteToken(json.ObjectEnd)
+}
+
+// writeTableGenField writes the name and value object member to enc.
+func writeTableGenField(enc *json.Encoder, name string, v interface{}) error {
+	if err := enc.WriteToken(json.String(name)); err != nil {
+		return err
+	}
+	if err := (json.MarshalOptions{}).MarshalNext(enc, v); err != nil {
+		return fmt.Errorf("marshal tablegen %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// newTableGenRecords returns the TableGen records of inst for all combinations of the explicit operand kinds.
+func newTableGenRecords(x86 *X86, f *X86Formatter, inst *X86Instruction) ([]*TableGenRecord, error) {
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inst.Name, err)
+	}
+	opcode, err := inst.ParseOpCode()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inst.Name, err)
+	}
+	meta := x86.ParseMetadata(inst.Metadata)
+
+	var explicit []*X86Operand
+	for _, op := range ops {
+		if !op.Implicit {
+			explicit = append(explicit, op)
+		}
+	}
+
+	var recs []*TableGenRecord
+	kinds := make([]string, len(explicit))
+	var walk func(i int)
+	walk = func(i int) {
+		if i == len(explicit) {
+			recs = append(recs, newTableGenRecord(f, inst, ops, explicit, kinds, opcode, meta))
+			return
+		}
+		for _, kind := range explicit[i].Kinds {
+			kinds[i] = kind
+			walk(i + 1)
+		}
+	}
+	walk(0)
+
+	return recs, nil
+}
+
+// newTableGenRecord returns the TableGen record of inst with the chosen kinds of the explicit operands.
+func newTableGenRecord(f *X86Formatter, inst *X86Instruction, ops, explicit []*X86Operand, kinds []string, opcode *X86OpCode, meta *X86Metadata) *TableGenRecord {
+	name := inst.Names()[0]
+	evex := opcode.Prefix == "EVEX"
+
+	rec := &TableGenRecord{
+		Name:               tableGenRecordName(name, kinds),
+		Fields:             []string{},
+		Superclasses:       []string{"Instruction", "X86Inst"},
+		OutOperandList:     newTableGenDag("outs"),
+		InOperandList:      newTableGenDag("ins"),
+		Defs:               []*TableGenDef{},
+		Uses:               []*TableGenDef{},
+		AsmdbName:          inst.Name,
+		AsmdbOperands:      inst.Operands,
+		AsmdbEncoding:      inst.Encoding,
+		AsmdbOpCode:        inst.OpCode,
+		AsmdbArchitectures: meta.Architectures,
+		AsmdbExtensions:    meta.Extensions,
+	}
+
+	var asm []string
+	var constraints []string
+	nsrc, ndst := 0, 0
+	srcName := func() string {
+		nsrc++
+		return "src" + strconv.Itoa(nsrc)
+	}
+	dstName := func() string {
+		if ndst++; ndst == 1 {
+			return "dst"
+		}
+		return "dst" + strconv.Itoa(ndst)
+	}
+	hasMem, hasMoff := false, false
+	for i, op := range explicit {
+		kind := kinds[i]
+		if strings.IndexByte(kind, '+') >= 0 {
+			// register groups are encoded by the previous operand
+			continue
+		}
+		class := tableGenOperandClass(kind, evex)
+		typ := x86KindType(kind)
+		if x86IsBroadcast(kind) {
+			typ = X86OperandMem
+			rec.HasEVEXB = 1
+		}
+
+		switch {
+		case kind == "1":
+			// the implicit shift count is a part of the instruction
+			asm = append(asm, "1")
+			continue
+
+		case typ&X86OperandMem != 0:
+			hasMem = true
+			hasMoff = hasMoff || typ&X86OperandMoffs != 0
+			name := ""
+			if op.IsWrite() {
+				rec.MayStore = 1
+				name = dstName()
+			} else {
+				name = srcName()
+			}
+			if op.IsRead() {
+				rec.MayLoad = 1
+			}
+			rec.InOperandList.add(class, name)
+			asm = append(asm, "$"+name)
+
+		case typ == X86OperandReg && op.IsWrite():
+			dst := dstName()
+			rec.OutOperandList.add(class, dst)
+			if op.IsRead() {
+				src := srcName()
+				rec.InOperandList.add(class, src)
+				constraints = append(constraints, "$"+src+" = $"+dst)
+			}
+			asm = append(asm, "$"+dst)
+
+		default:
+			src := srcName()
+			rec.InOperandList.add(class, src)
+			asm = append(asm, "$"+src)
+		}
+
+		for _, deco := range op.Decorators {
+			switch deco {
+			case "{k}", "{kz}":
+				rec.HasEVEXK = 1
+				rec.InOperandList.add("VK16WM", "mask")
+				asm[len(asm)-1] += " {${mask}}"
+				if deco == "{kz}" {
+					rec.HasEVEXZ = 1
+					asm[len(asm)-1] += " {z}"
+				}
+			case "{er}":
+				if !hasMem {
+					rec.HasEVEXB = 1
+					rec.InOperandList.add("AVX512RC", "rc")
+					asm = append(asm, "$rc")
+				}
+			case "{sae}":
+				if !hasMem {
+					rec.HasEVEXB = 1
+					asm = append(asm, "{sae}")
+				}
+			}
+		}
+	}
+	rec.AsmString = tableGenAsmString(name, f.ATTMnemonic(name, kinds), asm)
+	rec.Constraints = strings.Join(constraints, ", ")
+
+	for _, op := range ops {
+		if !op.Implicit {
+			continue
+		}
+		if op.IsMem() {
+			if op.IsRead() {
+				rec.MayLoad = 1
+			}
+			if op.IsWrite() {
+				rec.MayStore = 1
+			}
+			continue
+		}
+		for _, kind := range op.Kinds {
+			reg := newTableGenDef(strings.ToUpper(strings.NewReplacer("(", "", ")", "").Replace(kind)))
+			if op.IsRead() {
+				rec.Uses = append(rec.Uses, reg)
+			}
+			if op.IsWrite() {
+				rec.Defs = append(rec.Defs, reg)
+			}
+		}
+	}
+	readFlags, writeFlags := false, false
+	for reg, access := range meta.SpecialRegs {
+		if !strings.HasPrefix(reg, "FLAGS.") {
+			continue
+		}
+		if strings.ContainsAny(access, "RX") {
+			readFlags = true
+		}
+		if access != "R" {
+			writeFlags = true
+		}
+	}
+	if readFlags {
+		rec.Uses = append(rec.Uses, newTableGenDef("EFLAGS"))
+	}
+	if writeFlags {
+		rec.Defs = append(rec.Defs, newTableGenDef("EFLAGS"))
+	}
+
+	switch meta.Attributes["Control"] {
+	case "Call":
+		rec.IsCall = 1
+	case "Branch":
+		rec.IsBranch = 1
+		rec.IsTerminator = 1
+	case "Jump":
+		rec.IsBranch = 1
+		rec.IsBarrier = 1
+		rec.IsTerminator = 1
+	case "Return":
+		rec.IsReturn = 1
+		rec.IsBarrier = 1
+		rec.IsTerminator = 1
+	}
+
+	op := opcode.Opcode()
+	for i := range rec.Opcode {
+		rec.Opcode[i] = int(op>>i) & 1
+	}
+	rec.Form = newTableGenDef(tableGenForm(inst.Encoding, opcode, hasMem, hasMoff))
+	rec.OpMap = newTableGenDef(tableGenOpMap(opcode))
+	rec.OpPrefix = newTableGenDef(tableGenOpPrefix(opcode))
+
+	switch opcode.Prefix {
+	case "VEX":
+		rec.OpEnc = newTableGenDef("EncVEX")
+	case "EVEX":
+		rec.OpEnc = newTableGenDef("EncEVEX")
+	case "XOP":
+		rec.OpEnc = newTableGenDef("EncXOP")
+	default:
+		rec.OpEnc = newTableGenDef("EncNormal")
+	}
+	if opcode.REXW || opcode.W == "W1" {
+		rec.HasREXW = 1
+	}
+	if strings.ContainsRune(strings.SplitN(inst.Encoding, "-", 2)[0], 'V') {
+		rec.HasVEX4V = 1
+	}
+	switch opcode.L {
+	case "256", "L1":
+		rec.HasVEXL = 1
+	case "512":
+		rec.HasEVEXL2 = 1
+	}
+
+	return rec
+}
+
+// tableGenAsmString returns the AsmString of the Intel and AT&T mnemonics with the operands in the Intel order.
+func tableGenAsmString(intel, att string, ops []string) string {
+	mnemonic := intel
+	if att != intel {
+		mnemonic = "{" + att + "|" + intel + "}"
+	}
+	if len(ops) == 0 {
+		return mnemonic
+	}
+
+	attOps := make([]string, len(ops))
+	for i, op := range ops {
+		if op == "1" {
+			op = "$1"
+		}
+		attOps[len(ops)-1-i] = op
+	}
+
+	return mnemonic + "\t{" + strings.Join(attOps, ", ") + "|" + strings.Join(ops, ", ") + "}"
+}
+
+// tableGenRecordName returns the record name of the instruction name with the operand kinds, like "ADC_R8_M8".
+func tableGenRecordName(name string, kinds []string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToUpper(name))
+	for _, kind := range kinds {
+		sb.WriteByte('_')
+		sb.WriteString(strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+				return r
+			case r == '+':
+				return 'P'
+			}
+			return -1
+		}, kind))
+	}
+
+	return sb.String()
+}
+
+// tableGenForm returns the LLVM instruction Form of the encoding.
+func tableGenForm(encoding string, opcode *X86OpCode, hasMem, hasMoff bool) string {
+	enc := strings.SplitN(encoding, "-", 2)[0]
+	suffix := "Reg"
+	if hasMem {
+		suffix = "Mem"
+	}
+
+	switch {
+	case opcode.PlusR || opcode.PlusI:
+		return "AddRegFrm"
+	case hasMoff:
+		return "RawFrmMemOffs"
+	case opcode.ModRM == "r" && strings.HasPrefix(enc, "M"):
+		return "MRMDest" + suffix
+	case opcode.ModRM == "r" && strings.HasPrefix(enc, "RVS"):
+		return "MRMSrc" + suffix + "Op4"
+	case opcode.ModRM == "r" && strings.HasPrefix(enc, "RMV"):
+		return "MRMSrc" + suffix + "4VOp3"
+	case opcode.ModRM == "r":
+		return "MRMSrc" + suffix
+	case opcode.ModRM != "":
+		return "MRM" + opcode.ModRM + strings.ToLower(suffix[:1])
+	}
+
+	return "RawFrm"
+}
+
+// tableGenOpMap returns the LLVM OpMap of the opcode.
+func tableGenOpMap(opcode *X86OpCode) string {
+	if opcode.Prefix != "" {
+		return tableGenOpMaps[opcode.Map]
+	}
+
+	var escape string
+	switch b := opcode.Bytes; {
+	case len(b) >= 3 && b[0] == 0x0F && (b[1] == 0x38 || b[1] == 0x3A):
+		escape = fmt.Sprintf("%02X%02X", b[0], b[1])
+	case len(b) >= 3 && b[0] == 0x0F && b[1] == 0x0F:
+		escape = "0F0F"
+	case len(b) >= 2 && b[0] == 0x0F:
+		escape = "0F"
+	}
+
+	return tableGenOpMaps[escape]
+}
+
+// tableGenOpPrefix returns the LLVM OpPrefix of the opcode.
+func tableGenOpPrefix(opcode *X86OpCode) string {
+	if opcode.Prefix != "" {
+		if prefix, ok := tableGenOpPrefixes[opcode.PP]; ok {
+			return prefix
+		}
+		return "PS"
+	}
+
+	// the mandatory prefix is the last legacy prefix preceding the escape byte
+	prefix := "NoPrfx"
+	for _, b := range opcode.Prefixes {
+		switch b {
+		case 0x66:
+			prefix = "PD"
+		case 0xF3:
+			prefix = "XS"
+		case 0xF2:
+			prefix = "XD"
+		}
+	}
+	if prefix == "NoPrfx" && len(opcode.Bytes) > 1 && opcode.Bytes[0] == 0x0F {
+		prefix = "PS"
+	}
+
+	return prefix
+}
@@ -0,0 +1,56 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs is a list of the functions of the user templates in addition to the text/template ones.
+var templateFuncs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"trim":    strings.TrimSpace,
+	"split":   strings.Split,
+	"join":    strings.Join,
+	"replace": strings.ReplaceAll,
+	"quote":   strconv.Quote,
+	"compact": armCompactOperands,
+}
+
+// templateOutput returns the name of the output file of the user template, the template file name
+// without the ".tmpl" extension, like "tables.go" for "tables.go.tmpl", in the architecture subdirectory of the -o directory.
+func templateOutput(tmpl, arch string) string {
+	return filepath.Join(*flagOut, arch, strings.TrimSuffix(filepath.Base(tmpl), ".tmpl"))
+}
+
+// writeTemplate executes the user template file tmpl with the model data and writes the output to w.
+// The output is formatted if it's Go source, the output file name ends with ".go".
+func writeTemplate(w io.Writer, tmpl string, data *Model) error {
+	t, err := template.New(filepath.Base(tmpl)).Funcs(templateFuncs).ParseFiles(tmpl)
+	if err != nil {
+		return fmt.Errorf("parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	src := buf.Bytes()
+	if strings.HasSuffix(templateOutput(tmpl, data.Arch), ".go") {
+		if src, err = format.Source(src); err != nil {
+			return fmt.Errorf("format template output: %w", err)
+		}
+	}
+	_, err = w.Write(src)
+
+	return err
+}
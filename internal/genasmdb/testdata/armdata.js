@@ -0,0 +1,267 @@
+// [armdata.js]
+// ARM instruction-set data.
+//
+// [License]
+// Public Domain.
+
+
+// This file can be parsed as pure JSON, locate ${JSON:BEGIN} and ${JSON:END}
+// marks and strip everything outside, a sample JS function that would do the job:
+//
+// function strip(s) {
+//   return s.replace(/(^.*\$\{JSON:BEGIN\}\s+)|(\/\/\s*\$\{JSON:END\}\s*.*$)/g, "");
+// }
+
+
+// INSTRUCTION TUPLE
+// -----------------
+//
+// Each instruction tuple consists of 5 strings:
+//
+//   [0] - Instruction name.
+//   [1] - Instruction operands.
+//   [2] - Instruction type (specifies instruction's layout and architecture as well).
+//   [3] - Instruction opcode (fields separated by '|' forming the instruction word or halfword).
+//   [4] - Instruction metadata - CPU requirements, APSR (read/write), and other metadata.
+//
+// The fields should match ARM instruction reference manual as possible, however,
+// it's allowed to make changes that make parsing easier and data more consistent.
+
+
+// INSTRUCTION OPERANDS
+// --------------------
+//
+// Instruction operands contain standard operand field(s) as defined by ARM
+// instruction reference, and also additional metadata that is defined by
+// ARM, but in notes section (instead of instruction format section). Additional
+// data include:
+//
+//   - "R?!=HI" - The register cannot be R8..R15 (most T16 instructions).
+//   - "R?!=PC" - The register cannot be R15 (PC).
+//   - "R?!=SP" - The register cannot be R13 (SP).
+//   - "R?!=XX" - The register cannot be R13 (SP) or R15 (PC).
+//   - "??<=07" - The register must be from 0..7  (some ASIMD instructions).
+//   - "??<=15" - The register must be from 0..15 (some ASIMD instructions).
+//
+// Also, all instructions that use T16 layout were normalized into 3 operand
+// form to make these compatible with T32 and A32 architecturess. It was designed
+// for convenience. These are easily recognizable as they always share the first
+// two operands.
+//
+// Divergence from ARM Manual:
+//   - "Rdn" register (used by T16) was renamed to Rx to make the table easier to
+//     read when multiple instructions follow (register names have the same length).
+
+
+// METADATA
+// --------
+//
+// The following metadata is used to describe instructions:
+//
+//   "ARMv??"
+///    - Required ARM version:
+//       - '+' sign means it's supported by that version and above.
+//       - '-' sign means it's deprecated (and discontinued) by that version.
+//
+//   "APSR"
+//     - The instruction reads/writes APSR register:
+//       - [N|Z|C|V] - Which flags are read/written
+//       - Since most of ARM instructions provide conditional execution the
+//         APSR mostly defines APSR writes, as reads are controlled by IT
+//         or condition code {cond}, which is part of each instruction.
+//
+//   "APSR_IF_NOT_PC"
+//     - Instruction writes to APSR register only if the destination register
+//       is not R15 (PC). In that case APSR is not modified (ARM specific).
+//
+
+// TODO: MISSING/REVIEW:
+//   cdp
+//   cdp2
+//   chka
+//   cps
+//   enterx
+//   leavex
+//   HB, HBL, HBLP, HBP
+//   ldc / ldc2
+//   ADD 'mov' with shift spec.
+//   MRS/MSR <banked_reg>
+//   RFE
+//   SMC
+//   SRS
+//   STC / SRC2
+//   STC
+//   STM
+//   SUBS PC, LR
+//
+//   vl?
+//   vmrs
+//   vmsr
+//   vpop
+//   vpush
+//   vst?
+//   vstm
+//   vstr
+
+// TODO (Metadata):
+//   ARMv8-A removes UNPREDICTABLE for R13
+//   if ArchVersion() < 6 && d == n then UNPREDICTABLE;
+
+(function($export, $as) {
+"use strict";
+
+$export[$as] =
+// ${JSON:BEGIN}
+{
+  "architectures": [
+    "T16",
+    "T32",
+    "A32",
+    "A64"
+  ],
+
+  "cpuLevels": [
+    { "name": "ARMv4"    },
+    { "name": "ARMv4T"   },
+    { "name": "ARMv5T"   },
+    { "name": "ARMv5TE"  },
+    { "name": "ARMv5TEJ" },
+    { "name": "ARMv6"    },
+    { "name": "ARMv6K"   },
+    { "name": "ARMv6T2"  },
+    { "name": "ARMv7"    },
+    { "name": "ARMv8"    },
+    { "name": "ARMv8_1"  },
+    { "name": "ARMv8_2"  }
+  ],
+
+  "extensions": [
+    { "name": "VFPv2"            , "from": "ARMv8"    },
+    { "name": "VFPv3"            , "from": "ARMv8"    },
+    { "name": "VFPv3_FP16"       , "from": "ARMv8"    },
+    { "name": "VFPv4"            , "from": "ARMv8"    },
+    { "name": "IDIVT"            , "from": "ARMv7+"   },
+    { "name": "IDIVA"            , "from": "ARMv8+"   },
+    { "name": "CRC32"            , "from": "ARMv8_1+" },
+    { "name": "ASIMD"            , "from": ""         },
+    { "name": "AES"              , "from": ""         },
+    { "name": "SHA1"             , "from": ""         },
+    { "name": "SHA256"           , "from": ""         },
+    { "name": "SECURITY"         , "from": ""         }
+  ],
+
+  "attributes": [
+    { "name": "ALIAS_OF"         , "type": "string"      , "doc": "The instruction is an alias instruction of ... ." },
+    { "name": "PSEUDO_OF"        , "type": "string"      , "doc": "The instruction is a pseudo instruction of ... ." },
+    { "name": "IT_IN"            , "type": "flag"        , "doc": "Instruction can be executed inside IT block." },
+    { "name": "IT_OUT"           , "type": "flag"        , "doc": "Instruction can be executed outside IT block." },
+    { "name": "IT_LAST"          , "type": "flag"        , "doc": "Instruction must be executed last in IT block." },
+    { "name": "T16_LDM"          , "type": "flag"        , "doc": "Writeback is enabled if Rn is specified also in RdList." },
+    { "name": "T32_LDM"          , "type": "flag"        , "doc": "RdList can contain one of R15|R14 and requires at least 2 registers." },
+    { "name": "LSL_3_IF_SP"      , "type": "flag"        , "doc": "Restricts the shift operation to LSL by a maximum amount of 3 bit if the destination register is SP." },
+    { "name": "ARMv6T2_IF_LOW"   , "type": "flag"        , "doc": "ARMv6T2+ required if both registers are low (R0..R7)." },
+    { "name": "UNPRED_COMPLEX"   , "type": "flag"        , "doc": "Unpredictable based on complex rules." },
+    { "name": "UNPRED_IF_ALL_LOW", "type": "flag"        , "doc": "Unpredictable if both registers are low (R0..R7)." },
+    { "name": "VEC_NARROW"       , "type": "flag"        , "doc": "SIMD instruction that narrows input vector(s)." },
+    { "name": "VEC_WIDEN"        , "type": "flag"        , "doc": "SIMD instruction that widens input vector(s)." },
+    { "name": "Op_CMode"         , "type": "string[]"    , "doc": "Array of possible OP and CMode combinations." }
+  ],
+
+  "specialRegs": [
+    { "name": "APSR.N"           , "group": "APSR.N"     , "doc": "Negative flag." },
+    { "name": "APSR.Z"           , "group": "APSR.Z"     , "doc": "Zero flag." },
+    { "name": "APSR.C"           , "group": "APSR.C"     , "doc": "Carry or unsigned overflow flag." },
+    { "name": "APSR.V"           , "group": "APSR.V"     , "doc": "Signed overflow flag." },
+    { "name": "APSR.Q"           , "group": "APSR.Q"     , "doc": "Sticky saturation flag." },
+    { "name": "APSR.GE"          , "group": "APSR.GE"    , "doc": "Greater than or equal flag." },
+
+    { "name": "CPSR.IT"          , "group": "CPSR.IT"    , "doc": "If-then bits." },
+    { "name": "CPSR.J"           , "group": "CPSR.J"     , "doc": "Jazelle bit." },
+    { "name": "CPSR.E"           , "group": "CPSR.E"     , "doc": "Endianness bit." },
+    { "name": "CPSR.A"           , "group": "CPSR.A"     , "doc": "Imprecise abort disable bit." },
+    { "name": "CPSR.I"           , "group": "CPSR.I"     , "doc": "IRQ disable bit." },
+    { "name": "CPSR.F"           , "group": "CPSR.F"     , "doc": "FIQ disable bit." },
+    { "name": "CPSR.T"           , "group": "CPSR.T"     , "doc": "Thumb mode bit." },
+    { "name": "CPSR.M"           , "group": "CPSR.M"     , "doc": "Current processor mode." },
+
+    { "name": "IPSR.N"           , "group": "IPSR.N"     , "doc": "ISR number." },
+
+    { "name": "FPCSR.N"          , "group": "FPCSR.N"    , "doc": "Less than flag." },
+    { "name": "FPCSR.Z"          , "group": "FPCSR.Z"    , "doc": "Equal flag." },
+    { "name": "FPCSR.C"          , "group": "FPCSR.C"    , "doc": "Equal, greater than, or unordered flag." },
+    { "name": "FPCSR.V"          , "group": "FPCSR.V"    , "doc": "Unordered flag." },
+    { "name": "FPCSR.Q"          , "group": "FPCSR.Q"    , "doc": "Sticky saturation flag." },
+    { "name": "FPCSR.AHP"        , "group": "FPCSR.MODE" , "doc": "Alternative half-precision control bit." },
+    { "name": "FPCSR.DN"         , "group": "FPCSR.MODE" , "doc": "Default NaN mode enable bit." },
+    { "name": "FPCSR.FZ"         , "group": "FPCSR.MODE" , "doc": "Flush-to-zero mode enable bit." },
+    { "name": "FPCSR.RMode"      , "group": "FPCSR.MODE" , "doc": "Rounding mode control field." },
+    { "name": "FPCSR.Stride"     , "group": "FPCSR.VEC"  , "doc": "Vector stride." },
+    { "name": "FPCSR.Length"     , "group": "FPCSR.VEC"  , "doc": "Vector length." },
+    { "name": "FPCSR.IDE"        , "group": "FPCSR.EXC"  , "doc": "Input subnormal exception enable bit." },
+    { "name": "FPCSR.IXE"        , "group": "FPCSR.EXC"  , "doc": "Inexact exception enable bit." },
+    { "name": "FPCSR.UFE"        , "group": "FPCSR.EXC"  , "doc": "Underflow exception enable bit." },
+    { "name": "FPCSR.OFE"        , "group": "FPCSR.EXC"  , "doc": "Overflow exception enable bit." },
+    { "name": "FPCSR.DZE"        , "group": "FPCSR.EXC"  , "doc": "Division by zero exception enable bit." },
+    { "name": "FPCSR.IOE"        , "group": "FPCSR.EXC"  , "doc": "Invalid operation exception enable bit." },
+    { "name": "FPCSR.IDC"        , "group": "FPCSR.CUM"  , "doc": "Input subnormal cumulative flag." },
+    { "name": "FPCSR.IXC"        , "group": "FPCSR.CUM"  , "doc": "Inexact cumulative flag." },
+    { "name": "FPCSR.UFC"        , "group": "FPCSR.CUM"  , "doc": "Underflow cumulative flag." },
+    { "name": "FPCSR.OFC"        , "group": "FPCSR.CUM"  , "doc": "Overflow cumulative flag." },
+    { "name": "FPCSR.DZC"        , "group": "FPCSR.CUM"  , "doc": "Division by zero cumulative flag." },
+    { "name": "FPCSR.IOC"        , "group": "FPCSR.CUM"  , "doc": "Invalid operation cumulative flag." }
+  ],
+
+  "shortcuts": [
+    { "name": "APSR.NZ"          , "expand": "APSR.N|Z"     },
+    { "name": "APSR.NZC"         , "expand": "APSR.N|Z|C"   },
+    { "name": "APSR.NZCV"        , "expand": "APSR.N|Z|C|V" }
+  ],
+
+  "registers": {
+    "r"   : { "kind": "gp" , "any": "r", "names": ["r0-31"] },
+    "w"   : { "kind": "gp" , "any": "w", "names": ["w0-31"] },
+    "x"   : { "kind": "gp" , "any": "x", "names": ["x0-31"] },
+    "s"   : { "kind": "vec", "any": "s", "names": ["s0-31"] },
+    "d"   : { "kind": "vec", "any": "d", "names": ["d0-31"] },
+    "v"   : { "kind": "vec", "any": "v", "names": ["v0-31"] }
+  },
+
+  "instructions": [
+    ["adc"              , "Rd!=XX, Rn!=XX, #ImmA"                       , "T32", "1111|0|ImmA:1|0|1010|0|Rn|0|ImmA:3|Rd|ImmA:8"           , "ARMv6T2+ IT=ANY"],
+    ["adc"              , "Rd    , Rn    , #ImmA"                       , "A32", "Cond|001|0101|0|Rn|Rd|ImmA:12"                          , "ARMv4+"],
+    ["adc"              , "Rx!=HI, Rx!=HI, Rm!=HI"                      , "T16", "0100|000|101|Rm:3|Rx:3"                                 , "ARMv4T+ IT=IN"],
+    ["adc"              , "Rd    , Rn    , Rm    , {Sop #Shift}"        , "A32", "Cond|000|0101|0|Rn|Rd|Shift:5|Sop:2|0|Rm"               , "ARMv4+"],
+    ["adc"              , "Rd!=XX, Rn!=XX, Rm!=XX, {Sop #Shift}"        , "T32", "1110|101|1010|0|Rn|0|Shift:3|Rd|Shift:2|Sop:2|Rm"       , "ARMv6T2+ IT=ANY"],
+    ["adc"              , "Rd!=PC, Rn!=PC, Rm!=PC, Sop Rs!=PC"          , "A32", "Cond|000|0101|0|Rn|Rd|Rs|0|Sop:2|1|Rm"                  , "ARMv4+"],
+    ["b"                , "#RelS*2"                                     , "T16", "1101|Cond|RelS:8"                                       , "ARMv4T+ IT=OUT"],
+    ["b"                , "#RelS*2"                                     , "T16", "1110|0|RelS:11"                                         , "ARMv4T+ IT=OUT|LAST"],
+    ["b"                , "#RelS*2"                                     , "T32", "1111|0|RelS[19]|Cond|RelS[16:11]|10|J|0|K|RelS[10:0]"   , "ARMv6T2+ IT=OUT"],
+    ["b"                , "#RelS*2"                                     , "T32", "1111|0|RelS[23]|     RelS[20:11]|10|J|1|K|RelS[10:0]"   , "ARMv6T2+ IT=OUT|LAST"],
+    ["b"                , "#RelS*4"                                     , "A32", "Cond|101|0|RelS:24"                                     , "ARMv4+"],
+    ["bl"               , "#RelS*2"                                     , "T32", "1111|0|RelS[23]|RelS[20:11]|11|Ja|1|Jb|RelS[10:0]"      , "ARMv4T+ IT=OUT|LAST"],
+    ["bl"               , "#RelS*4"                                     , "A32", "Cond|101|1|RelS:24"                                     , "ARMv4+"],
+    ["dmb"              , "#ImmZ"                                       , "T32", "1111|001|1101|1|1111|1000|1111|0101|ImmZ:4"             , "ARMv7+ IT=ANY"],
+    ["dmb"              , "#ImmZ"                                       , "A32", "1111|010|1011|1|1111|1111|0000|0101|ImmZ:4"             , "ARMv7+"],
+    ["ldr"              , "Rd!=HI, [Rn!=HI, #ImmZ*4]"                   , "T16", "0110|1|ImmZ:5|Rn:3|Rd:3"                                , "ARMv4T+ IT=ANY"],
+    ["ldr"              , "Rd!=HI, [Rn==SP, #ImmZ*4]"                   , "T16", "1001|1|Rd:3|ImmZ:8"                                     , "ARMv4T+ IT=ANY"],
+    ["ldr"              , "Rd!=HI, [Rn==PC, #ImmZ*4]"                   , "T16", "0100|1|Rd:3|ImmZ:8"                                     , "ARMv6T2+ IT=ANY"],
+    ["ldr"              , "Rd    , [Rn!=PC, #ImmZ]"                     , "T32", "1111|100|0110|1|Rn|Rd|ImmZ:12"                          , "ARMv6T2+ IT=ANY"],
+    ["ldr"              , "Rd    , [Rn!=PC, #+/-ImmZ]{!}"               , "T32", "1111|100|0010|1|Rn|Rd|1PUW|ImmZ:8"                      , "ARMv6T2+ IT=ANY"],
+    ["ldr"              , "Rd    , [Rn==PC, #+/-ImmZ]"                  , "T32", "1111|100|0U10|1|Rn|Rd|ImmZ:12"                          , "ARMv6T2+ IT=ANY"],
+    ["ldr"              , "Rd    , [Rn    , #+/-ImmZ]{!}"               , "A32", "Cond|010|PU0W|1|Rn|Rd|ImmZ:12"                          , "ARMv4+"],
+    ["ldr"              , "Rd!=HI, [Rn!=HI, Rm!=HI]"                    , "T16", "0101|100|Rm:3|Rn:3|Rd:3"                                , "ARMv4T+ IT=ANY"],
+    ["ldr"              , "Rd    , [Rn!=PC, Rm!=XX, {LSL #Shift}]"      , "T32", "1111|100|0010|1|Rn|Rd|0|00000|Shift:2|Rm"               , "ARMv6T2+ IT=ANY"],
+    ["ldr"              , "Rd    , [Rn    , +/-Rm!=PC, {Sop #Shift}]{!}", "A32", "Cond|011|PU0W|1|Rn|Rd|Shift:5|Sop:2|0|Rm"               , "ARMv4+"],
+    ["vadd.f32"         , "Sd, Sn, Sm"                                  , "T32", "1110|11100|Vd'|11|Vn|Vd|1010|Vn'|0|Vm'|0|Vm"            , "VFPv2"],
+    ["vadd.f32"         , "Sd, Sn, Sm"                                  , "A32", "Cond|11100|Vd'|11|Vn|Vd|1010|Vn'|0|Vm'|0|Vm"            , "VFPv2"],
+    ["vadd.f32"         , "Dd, Dn, Dm"                                  , "T32", "1110|11110|Vd'|00|Vn|Vd|1101|Vn'|0|Vm'|0|Vm"            , "ASIMD"],
+    ["vadd.f32"         , "Dd, Dn, Dm"                                  , "A32", "1111|00100|Vd'|00|Vn|Vd|1101|Vn'|0|Vm'|0|Vm"            , "ASIMD"],
+    ["vadd.f32"         , "Vd, Vn, Vm"                                  , "T32", "1110|11110|Vd'|00|Vn|Vd|1101|Vn'|1|Vm'|0|Vm"            , "ASIMD"],
+    ["vadd.f32"         , "Vd, Vn, Vm"                                  , "A32", "1111|00100|Vd'|00|Vn|Vd|1101|Vn'|1|Vm'|0|Vm"            , "ASIMD"]
+  ]
+}
+// ${JSON:END}
+;
+
+}).apply(this, typeof module === "object" && module && module.exports
+  ? [module, "exports"] : [this.asmdb || (this.asmdb = {}), "armdata"]);
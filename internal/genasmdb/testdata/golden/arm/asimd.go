@@ -0,0 +1,62 @@
+// Code generated by genasmdb from testdata/armdata.js (sha256:05b5dfa77639dd07b416acad9a15c29df789ecb26252f8a1bf23cd7f4ce1ab44). DO NOT EDIT.
+
+//go:build !arm_no_asimd
+// +build !arm_no_asimd
+
+package arm
+
+func init() {
+	tables[1] = table{ext: "ASIMD", encs: asimdEncodings[:], fields: asimdFields[:], ops: asimdOps[:]}
+}
+
+// asimdEncodings is the list of the instruction encodings requiring the ASIMD extension.
+var asimdEncodings = [...]Encoding{
+	{name: 0x1b408, operands: 0x1960a, Arch: T32, Width: 32, Mask: 0xffb00f50, Value: 0xef000d00, tab: 1, fields: 0, nfields: 6, args: 0, nargs: 3},
+	{name: 0x1b408, operands: 0x1aa0a, Arch: T32, Width: 32, Mask: 0xffb00f50, Value: 0xef000d40, tab: 1, fields: 6, nfields: 6, args: 3, nargs: 3},
+	{name: 0x1b408, operands: 0x1960a, Arch: A32, Width: 32, Mask: 0xffb00f50, Value: 0xf2000d00, tab: 1, fields: 12, nfields: 6, args: 6, nargs: 3},
+	{name: 0x1b408, operands: 0x1aa0a, Arch: A32, Width: 32, Mask: 0xffb00f50, Value: 0xf2000d40, tab: 1, fields: 18, nfields: 6, args: 9, nargs: 3},
+}
+
+// asimdFields is the list of the opcode fields of asimdEncodings.
+var asimdFields = [...]Field{
+	{0x1ce03, 22, 22, 0}, // Vd'
+	{0x1ae02, 19, 16, 0}, // Vn
+	{0x1aa02, 15, 12, 0}, // Vd
+	{0x1d403, 7, 7, 0},   // Vn'
+	{0x1d103, 5, 5, 0},   // Vm'
+	{0x1b202, 3, 0, 0},   // Vm
+	{0x1ce03, 22, 22, 0}, // Vd'
+	{0x1ae02, 19, 16, 0}, // Vn
+	{0x1aa02, 15, 12, 0}, // Vd
+	{0x1d403, 7, 7, 0},   // Vn'
+	{0x1d103, 5, 5, 0},   // Vm'
+	{0x1b202, 3, 0, 0},   // Vm
+	{0x1ce03, 22, 22, 0}, // Vd'
+	{0x1ae02, 19, 16, 0}, // Vn
+	{0x1aa02, 15, 12, 0}, // Vd
+	{0x1d403, 7, 7, 0},   // Vn'
+	{0x1d103, 5, 5, 0},   // Vm'
+	{0x1b202, 3, 0, 0},   // Vm
+	{0x1ce03, 22, 22, 0}, // Vd'
+	{0x1ae02, 19, 16, 0}, // Vn
+	{0x1aa02, 15, 12, 0}, // Vd
+	{0x1d403, 7, 7, 0},   // Vn'
+	{0x1d103, 5, 5, 0},   // Vm'
+	{0x1b202, 3, 0, 0},   // Vm
+}
+
+// asimdOps is the list of the parsed operands of asimdEncodings.
+var asimdOps = [...]Operand{
+	{field: 0x19602, Type: Reg, Scale: 1, Class: 'd'}, // Dd
+	{field: 0x19a02, Type: Reg, Scale: 1, Class: 'd'}, // Dn
+	{field: 0x19e02, Type: Reg, Scale: 1, Class: 'd'}, // Dm
+	{field: 0x1aa02, Type: Reg, Scale: 1, Class: 'v'}, // Vd
+	{field: 0x1ae02, Type: Reg, Scale: 1, Class: 'v'}, // Vn
+	{field: 0x1b202, Type: Reg, Scale: 1, Class: 'v'}, // Vm
+	{field: 0x19602, Type: Reg, Scale: 1, Class: 'd'}, // Dd
+	{field: 0x19a02, Type: Reg, Scale: 1, Class: 'd'}, // Dn
+	{field: 0x19e02, Type: Reg, Scale: 1, Class: 'd'}, // Dm
+	{field: 0x1aa02, Type: Reg, Scale: 1, Class: 'v'}, // Vd
+	{field: 0x1ae02, Type: Reg, Scale: 1, Class: 'v'}, // Vn
+	{field: 0x1b202, Type: Reg, Scale: 1, Class: 'v'}, // Vm
+}
@@ -0,0 +1,43 @@
+// Code generated by genasmdb from testdata/armdata.js (sha256:05b5dfa77639dd07b416acad9a15c29df789ecb26252f8a1bf23cd7f4ce1ab44). DO NOT EDIT.
+
+//go:build !arm_no_vfpv2
+// +build !arm_no_vfpv2
+
+package arm
+
+func init() {
+	tables[2] = table{ext: "VFPv2", encs: vfpv2Encodings[:], fields: vfpv2Fields[:], ops: vfpv2Ops[:]}
+}
+
+// vfpv2Encodings is the list of the instruction encodings requiring the VFPv2 extension.
+var vfpv2Encodings = [...]Encoding{
+	{name: 0x1b408, operands: 0x1a00a, Arch: T32, Width: 32, Mask: 0xffb00f50, Value: 0xee300a00, tab: 2, fields: 0, nfields: 6, args: 0, nargs: 3},
+	{name: 0x1b408, operands: 0x1a00a, Arch: A32, Width: 32, Mask: 0x0fb00f50, Value: 0x0e300a00, tab: 2, fields: 6, nfields: 7, args: 3, nargs: 3},
+}
+
+// vfpv2Fields is the list of the opcode fields of vfpv2Encodings.
+var vfpv2Fields = [...]Field{
+	{0x1ce03, 22, 22, 0}, // Vd'
+	{0x1ae02, 19, 16, 0}, // Vn
+	{0x1aa02, 15, 12, 0}, // Vd
+	{0x1d403, 7, 7, 0},   // Vn'
+	{0x1d103, 5, 5, 0},   // Vm'
+	{0x1b202, 3, 0, 0},   // Vm
+	{0x1ca04, 31, 28, 0}, // Cond
+	{0x1ce03, 22, 22, 0}, // Vd'
+	{0x1ae02, 19, 16, 0}, // Vn
+	{0x1aa02, 15, 12, 0}, // Vd
+	{0x1d403, 7, 7, 0},   // Vn'
+	{0x1d103, 5, 5, 0},   // Vm'
+	{0x1b202, 3, 0, 0},   // Vm
+}
+
+// vfpv2Ops is the list of the parsed operands of vfpv2Encodings.
+var vfpv2Ops = [...]Operand{
+	{field: 0x1a002, Type: Reg, Scale: 1, Class: 's'}, // Sd
+	{field: 0x1a402, Type: Reg, Scale: 1, Class: 's'}, // Sn
+	{field: 0x1a802, Type: Reg, Scale: 1, Class: 's'}, // Sm
+	{field: 0x1a002, Type: Reg, Scale: 1, Class: 's'}, // Sd
+	{field: 0x1a402, Type: Reg, Scale: 1, Class: 's'}, // Sn
+	{field: 0x1a802, Type: Reg, Scale: 1, Class: 's'}, // Sm
+}
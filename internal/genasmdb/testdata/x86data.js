@@ -0,0 +1,369 @@
+// [x86data.js]
+// X86/X64 instruction-set data.
+//
+// [License]
+// Public Domain.
+
+
+// This file can be parsed as pure JSON, locate ${JSON:BEGIN} and ${JSON:END}
+// marks and strip everything outside, a sample JS function that would do the job:
+//
+// function strip(s) {
+//   return s.replace(/(^.*\$\{JSON:BEGIN\}\s+)|(\/\/\s*\$\{JSON:END\}\s*.*$)/g, "");
+// }
+
+
+// INSTRUCTIONS
+// ============
+//
+// Each instruction definition consists of 5 strings:
+//
+//   [0] - Instruction name.
+//   [1] - Instruction operands.
+//   [2] - Instruction encoding.
+//   [3] - Instruction opcode.
+//   [4] - Instruction metadata - CPU features, FLAGS (read/write), and other metadata.
+//
+// The definition tries to match Intel and AMD instruction set manuals, but there
+// are small differences to make the definition more informative and compact.
+
+
+// OPERANDS
+// ========
+//
+//   * "op"    - Explicit operand, must always be part of the instruction. If a fixed
+//               register (like "cl") is used, it means that the instruction uses this
+//               register implicitly, but it must be specified anyway.
+//
+//   * "<op>"  - Implicit operand - some assemblers allow implicit operands the be passed
+//               explicitly for documenting purposes. And some assemblers like AsmJit's
+//               Compiler infrastructure requires implicit operands to be passed explicitly
+//               for register allocation purposes.
+//
+//   * "{op}"  - Optional operand. Mostly used by AVX_512:
+//
+//               - {k} mask selector.
+//               - {z} zeroing.
+//               - {1tox} broadcast.
+//               - {er} embedded-rounding.
+//               - {sae} suppress-all-exceptions.
+//
+//   * "?:Op"  - Each operand can provide metadata that can be used to describe which
+//               operands are used as a destination, and which operands are source-only.
+//               Each instruction in general assumes that the first operand is always
+//               read/write and all following operands are read-only. However, this is
+//               not correct for all instructions, thus, instructions that don't match
+//               this assumption must provide additional information:
+//
+//               - "R:Op" - The operand is read-only.
+//               - "w:Op" - The operand is write-only (does not zero-extend).
+//               - "W:Op" - The operand is write-only (implicit zero-extend).
+//               - "x:Op" - The operand is read/write (does not zero-extend).
+//               - "X:Op" - The operand is read/write (implicit zero-extend).
+//
+//   * Op[A:B] - Optional bit-range that describes which bits are read and written.
+//
+//   * "~Op"   - Operand is commutative with other operands prefixed by "~". Commutativity
+//               means that all operands marked by '~' can be swapped and the result of the
+//               instruction would be the same.
+
+// WHAT IS MISSING
+// ===============
+//
+// Here is a list of missing instructions to keep track of it:
+//
+// [ ] xlat/xlatb
+
+(function($export, $as) {
+"use strict";
+
+$export[$as] =
+// ${JSON:BEGIN}
+{
+  "architectures": [
+    "ANY",
+    "X86",
+    "X64"
+  ],
+
+  "extensions": [
+    { "name": "3DNOW"               },
+    { "name": "3DNOW2"              },
+    { "name": "ADX"                 },
+    { "name": "AESNI"               },
+    { "name": "AMX_TILE"            },
+    { "name": "AMX_BF16"            },
+    { "name": "AMX_INT8"            },
+    { "name": "AVX"                 },
+    { "name": "AVX_VNNI"            },
+    { "name": "AVX2"                },
+    { "name": "AVX512_4FMAPS"       },
+    { "name": "AVX512_4VNNIW"       },
+    { "name": "AVX512_BF16"         },
+    { "name": "AVX512_BITALG"       },
+    { "name": "AVX512_BW"           },
+    { "name": "AVX512_CDI"          },
+    { "name": "AVX512_DQ"           },
+    { "name": "AVX512_ERI"          },
+    { "name": "AVX512_F"            },
+    { "name": "AVX512_FP16"         },
+    { "name": "AVX512_IFMA"         },
+    { "name": "AVX512_PFI"          },
+    { "name": "AVX512_VBMI"         },
+    { "name": "AVX512_VBMI2"        },
+    { "name": "AVX512_VNNI"         },
+    { "name": "AVX512_VL"           },
+    { "name": "AVX512_VP2INTERSECT" },
+    { "name": "AVX512_VPOPCNTDQ"    },
+    { "name": "BMI"                 },
+    { "name": "BMI2"                },
+    { "name": "CET_IBT"             },
+    { "name": "CET_SS"              },
+    { "name": "CLDEMOTE"            },
+    { "name": "CLFLUSH"             },
+    { "name": "CLFLUSHOPT"          },
+    { "name": "CLWB"                },
+    { "name": "CLZERO"              },
+    { "name": "CMOV"                },
+    { "name": "CMPXCHG8B"           },
+    { "name": "CMPXCHG16B"          },
+    { "name": "ENCLV"               },
+    { "name": "ENQCMD"              },
+    { "name": "F16C"                },
+    { "name": "FMA"                 },
+    { "name": "FMA4"                },
+    { "name": "FSGSBASE"            },
+    { "name": "FXSR"                },
+    { "name": "GEODE"               },
+    { "name": "HLE"                 },
+    { "name": "HRESET"              },
+    { "name": "GFNI"                },
+    { "name": "I486"                },
+    { "name": "LAHFSAHF"            },
+    { "name": "LWP"                 },
+    { "name": "LZCNT"               },
+    { "name": "MCOMMIT"             },
+    { "name": "MMX"                 },
+    { "name": "MMX2"                },
+    { "name": "MONITOR"             },
+    { "name": "MONITORX"            },
+    { "name": "MOVBE"               },
+    { "name": "MOVDIR64B"           },
+    { "name": "MOVDIRI"             },
+    { "name": "MPX"                 },
+    { "name": "MSR"                 },
+    { "name": "OSPKE"               },
+    { "name": "PCLMULQDQ"           },
+    { "name": "PCOMMIT"             },
+    { "name": "PCONFIG"             },
+    { "name": "POPCNT"              },
+    { "name": "PREFETCHW"           },
+    { "name": "PREFETCHWT1"         },
+    { "name": "PTWRITE"             },
+    { "name": "RDPID"               },
+    { "name": "RDPRU"               },
+    { "name": "RDRAND"              },
+    { "name": "RDSEED"              },
+    { "name": "RDTSC"               },
+    { "name": "RDTSCP"              },
+    { "name": "RTM"                 },
+    { "name": "SEAM"                },
+    { "name": "SERIALIZE"           },
+    { "name": "SHA"                 },
+    { "name": "SKINIT"              },
+    { "name": "SMAP"                },
+    { "name": "SMX"                 },
+    { "name": "SNP"                 },
+    { "name": "SSE"                 },
+    { "name": "SSE2"                },
+    { "name": "SSE3"                },
+    { "name": "SSE4_1"              },
+    { "name": "SSE4_2"              },
+    { "name": "SSE4A"               },
+    { "name": "SSSE3"               },
+    { "name": "SVM"                 },
+    { "name": "TBM"                 },
+    { "name": "TSX"                 },
+    { "name": "TSXLDTRK"            },
+    { "name": "UINTR"               },
+    { "name": "VAES"                },
+    { "name": "VPCLMULQDQ"          },
+    { "name": "VMX"                 },
+    { "name": "WAITPKG"             },
+    { "name": "WBNOINVD"            },
+    { "name": "XOP"                 },
+    { "name": "XSAVE"               },
+    { "name": "XSAVEC"              },
+    { "name": "XSAVEOPT"            },
+    { "name": "XSAVES"              }
+  ],
+
+  "attributes": [
+    { "name": "Control"          , "type": "string"      , "doc": "Describes control flow." },
+    { "name": "Volatile"         , "type": "flag"        , "doc": "Instruction can have side effects (hint for instruction scheduler)." },
+    { "name": "Deprecated"       , "type": "flag"        , "doc": "Deprecated instruction." },
+
+    { "name": "AltForm"          , "type": "flag"        , "doc": "Alternative form that is shorter, but has restrictions." },
+    { "name": "Lock"             , "type": "flag"        , "doc": "Can be used with LOCK prefix." },
+    { "name": "ImplicitLock"     , "type": "flag"        , "doc": "Instruction is always atomic, regardless of use of the LOCK prefix." },
+    { "name": "XAcquire"         , "type": "flag"        , "doc": "A hint used to start lock elision on the instruction memory operand address." },
+    { "name": "XRelease"         , "type": "flag"        , "doc": "A hint used to end lock elision on the instruction memory operand address." },
+
+    { "name": "REP"              , "type": "flag"        , "doc": "Can be used with REP (REPE/REPZ) prefix." },
+    { "name": "REPNE"            , "type": "flag"        , "doc": "Can be used with REPNE (REPNZ) prefix." },
+    { "name": "RepIgnored"       , "type": "flag"        , "doc": "Rep prefix can be used, but has no effect." },
+
+    { "name": "AliasOf"          , "type": "string"      , "doc": "Instruction is an alias to another instruction, must apply to all instructions within the same group." },
+    { "name": "EncodeAs"         , "type": "string"      , "doc": "Similar to AliasOf, but doesn't apply to all instructions in the group." }
+  ],
+
+  "specialRegs": [
+    { "name": "FLAGS.CF"         , "group": "FLAGS.CF"   , "doc": "Carry flag." },
+    { "name": "FLAGS.PF"         , "group": "FLAGS.PF"   , "doc": "Parity flag." },
+    { "name": "FLAGS.AF"         , "group": "FLAGS.AF"   , "doc": "Adjust flag." },
+    { "name": "FLAGS.ZF"         , "group": "FLAGS.ZF"   , "doc": "Zero flag." },
+    { "name": "FLAGS.SF"         , "group": "FLAGS.SF"   , "doc": "Sign flag." },
+    { "name": "FLAGS.TF"         , "group": "FLAGS.TF"   , "doc": "Trap flag." },
+    { "name": "FLAGS.IF"         , "group": "FLAGS.IF"   , "doc": "Interrupt enable flag." },
+    { "name": "FLAGS.DF"         , "group": "FLAGS.DF"   , "doc": "Direction flag." },
+    { "name": "FLAGS.OF"         , "group": "FLAGS.OF"   , "doc": "Overflow flag." },
+    { "name": "FLAGS.AC"         , "group": "FLAGS.Other", "doc": "Alignment check flag." },
+    { "name": "FLAGS.IOPL"       , "group": "FLAGS.Other", "doc": "I/O privilege level." },
+    { "name": "FLAGS.NT"         , "group": "FLAGS.Other", "doc": "Nested task flag." },
+    { "name": "FLAGS.RF"         , "group": "FLAGS.Other", "doc": "Resume flag." },
+    { "name": "FLAGS.VM"         , "group": "FLAGS.Other", "doc": "Virtual 8086 mode flag." },
+    { "name": "FLAGS.VIF"        , "group": "FLAGS.Other", "doc": "Virtual interrupt flag." },
+    { "name": "FLAGS.VIP"        , "group": "FLAGS.Other", "doc": "Virtual interrupt pending." },
+    { "name": "FLAGS.CPUID"      , "group": "FLAGS.Other", "doc": "CPUID instruction available." },
+
+    { "name": "X87CW.INVALID_OP" , "group": "X87CW.EXC"  , "doc": "Invalid operation exception enable bit." },
+    { "name": "X87CW.DENORMAL"   , "group": "X87CW.EXC"  , "doc": "Dernormalized exception enable bit." },
+    { "name": "X87CW.ZERO_DIVIDE", "group": "X87CW.EXC"  , "doc": "Division by zero exception enable bit." },
+    { "name": "X87CW.OVERFLOW"   , "group": "X87CW.EXC"  , "doc": "Overflow exception enable bit." },
+    { "name": "X87CW.UNDERFLOW"  , "group": "X87CW.EXC"  , "doc": "Underflow exception enable bit." },
+    { "name": "X87CW.PRECISION"  , "group": "X87CW.EXC"  , "doc": "Lost of precision exception enable bit." },
+    { "name": "X87CW.PC"         , "group": "X87CW.PC"   , "doc": "Precision control." },
+    { "name": "X87CW.RC"         , "group": "X87CW.RC"   , "doc": "Rounding control." },
+
+    { "name": "X87SW.INVALID_OP" , "group": "X87SW.EXC"  , "doc": "Invalid operation exception flag." },
+    { "name": "X87SW.DENORMAL"   , "group": "X87SW.EXC"  , "doc": "Dernormalized exception flag." },
+    { "name": "X87SW.ZERO_DIVIDE", "group": "X87SW.EXC"  , "doc": "Division by zero exception flag." },
+    { "name": "X87SW.OVERFLOW"   , "group": "X87SW.EXC"  , "doc": "Overflow exception flag." },
+    { "name": "X87SW.UNDERFLOW"  , "group": "X87SW.EXC"  , "doc": "Underflow exception flag." },
+    { "name": "X87SW.PRECISION"  , "group": "X87SW.EXC"  , "doc": "Lost of precision exception flag." },
+    { "name": "X87SW.STACK_FAULT", "group": "X87SW.EXC"  , "doc": "Stack fault." },
+    { "name": "X87SW.EF"         , "group": "X87SW.EXC"  , "doc": "Exception flag." },
+    { "name": "X87SW.C0"         , "group": "X87SW.C0"   , "doc": "C0 condifion." },
+    { "name": "X87SW.C1"         , "group": "X87SW.C1"   , "doc": "C1 condifion." },
+    { "name": "X87SW.C2"         , "group": "X87SW.C2"   , "doc": "C2 condifion." },
+    { "name": "X87SW.TOP"        , "group": "X87SW.TOP"  , "doc": "Top of the FPU stack." },
+    { "name": "X87SW.C3"         , "group": "X87SW.C3"   , "doc": "C3 condifion." },
+
+    { "name": "MSR"              , "group": "MSR"        , "doc": "MSR register." },
+    { "name": "XCR"              , "group": "XCR"        , "doc": "XCR register." }
+  ],
+
+  "shortcuts": [
+    { "name": "CF"               , "expand": "FLAGS.CF" },
+    { "name": "PF"               , "expand": "FLAGS.PF" },
+    { "name": "AF"               , "expand": "FLAGS.AF" },
+    { "name": "ZF"               , "expand": "FLAGS.ZF" },
+    { "name": "SF"               , "expand": "FLAGS.SF" },
+    { "name": "TF"               , "expand": "FLAGS.TF" },
+    { "name": "IF"               , "expand": "FLAGS.IF" },
+    { "name": "DF"               , "expand": "FLAGS.DF" },
+    { "name": "OF"               , "expand": "FLAGS.OF" },
+    { "name": "AC"               , "expand": "FLAGS.AC" },
+
+    { "name": "C0"               , "expand": "X87SW.C0" },
+    { "name": "C1"               , "expand": "X87SW.C1" },
+    { "name": "C2"               , "expand": "X87SW.C2" },
+    { "name": "C3"               , "expand": "X87SW.C3" },
+
+    { "name": "_ILock"           , "expand": "Lock|ImplicitLock" },
+    { "name": "_XLock"           , "expand": "Lock|XAcquire|XRelease" },
+    { "name": "BND"              , "expand": "REPNE|RepIgnored" },
+    { "name": "_Rep"             , "expand": "REP|REPNE" },
+    { "name": "DummyRep"         , "expand": "REP|REPNE|RepIgnored" }
+  ],
+
+  "registers": {
+    "r8"  : { "kind": "gp"  , "any": "r8"   , "names": ["al", "cl", "dl", "bl", "spl", "bpl", "sil", "dil", "r8-15b"] },
+    "r8hi": { "kind": "gp"                  , "names": ["ah", "ch", "dh", "bh"] },
+    "r16" : { "kind": "gp"  , "any": "r16"  , "names": ["ax", "cx", "dx", "bx", "sp", "bp", "si", "di", "r8-15w"] },
+    "r32" : { "kind": "gp"  , "any": "r32"  , "names": ["eax", "ecx", "edx", "ebx", "esp", "ebp", "esi", "edi", "r8-15d"] },
+    "r64" : { "kind": "gp"  , "any": "r64"  , "names": ["rax", "rcx", "rdx", "rbx", "rsp", "rbp", "rsi", "rdi", "r8-15"] },
+    "rxx" : { "kind": "gp"                  , "names": ["zax", "zcx", "zdx", "zbx", "zsp", "zbp", "zsi", "zdi"] },
+    "sreg": { "kind": "sreg", "any": "sreg" , "names": ["es", "cs", "ss", "ds", "fs", "gs" ] },
+    "creg": { "kind": "creg", "any": "creg" , "names": ["cr0-15"]  },
+    "dreg": { "kind": "dreg", "any": "dreg" , "names": ["dr0-15"]  },
+    "bnd" : { "kind": "bnd" , "any": "bnd"  , "names": ["bnd0-3"]  },
+    "st"  : { "kind": "st"  , "any": "st(i)", "names": ["st(0-7)"] },
+    "mm"  : { "kind": "mm"  , "any": "mm"   , "names": ["mm0-7"]   },
+    "k"   : { "kind": "k"   , "any": "k"    , "names": ["k0-7"]    },
+    "xmm" : { "kind": "vec" , "any": "xmm"  , "names": ["xmm0-31"] },
+    "ymm" : { "kind": "vec" , "any": "ymm"  , "names": ["ymm0-31"] },
+    "zmm" : { "kind": "vec" , "any": "zmm"  , "names": ["zmm0-31"] },
+    "tmm" : { "kind": "tile", "any": "tmm"  , "names": ["tmm0-7"]  }
+  },
+
+  "instructions": [
+    ["cmpxchg"          , "x:r8/m8, r8, <al>"                               , "MR"      , "0F B0 /r"                     , "I486             _XLock Volatile OF=W SF=W ZF=W AF=W PF=W CF=W"],
+    ["cmpxchg"          , "x:r16/m16, r16, <ax>"                            , "MR"      , "66 0F B1 /r"                  , "I486             _XLock Volatile OF=W SF=W ZF=W AF=W PF=W CF=W"],
+    ["cmpxchg"          , "X:r32/m32, r32, <eax>"                           , "MR"      , "0F B1 /r"                     , "I486             _XLock Volatile OF=W SF=W ZF=W AF=W PF=W CF=W"],
+    ["cmpxchg"          , "X:r64/m64, r64, <rax>"                           , "MR"      , "REX.W 0F B1 /r"               , "I486 X64         _XLock Volatile OF=W SF=W ZF=W AF=W PF=W CF=W"],
+    ["jmp"              , "rel8"                                            , "D"       , "EB cb"                        , "ANY BND          Control=Jump"],
+    ["jmp"              , "rel16"                                           , "D"       , "66 E9 cw"                     , "X86 BND          Control=Jump"],
+    ["jmp"              , "rel32"                                           , "D"       , "E9 cd"                        , "ANY BND          Control=Jump"],
+    ["jmp"              , "R:r32/m32"                                       , "D"       , "FF /4"                        , "X86 BND          Control=Jump"],
+    ["jmp"              , "R:r64/m64"                                       , "D"       , "FF /4"                        , "X64 BND          Control=Jump"],
+    ["mov"              , "w:al, moff8"                                     , "NONE"    , "A0"                           , "ANY"],
+    ["mov"              , "w:ax, moff16"                                    , "NONE"    , "66 A1"                        , "ANY"],
+    ["mov"              , "W:eax, moff32"                                   , "NONE"    , "A1"                           , "ANY"],
+    ["mov"              , "W:rax, moff64"                                   , "NONE"    , "REX.W A1"                     , "X64"],
+    ["mov"              , "W:moff8, al"                                     , "NONE"    , "A2"                           , "ANY"],
+    ["mov"              , "W:moff16, ax"                                    , "NONE"    , "66 A3"                        , "ANY"],
+    ["mov"              , "W:moff32, eax"                                   , "NONE"    , "A3"                           , "ANY"],
+    ["mov"              , "W:moff64, rax"                                   , "NONE"    , "REX.W A3"                     , "X64"],
+    ["movsb"            , "W:<es:zdi>, R:<ds:zsi>"                          , "NONE"    , "A4"                           , "ANY _Rep         DF=R"],
+    ["push"             , "R:r16/m16"                                       , "M"       , "66 FF /6"                     , "ANY"],
+    ["push"             , "R:r32/m32"                                       , "M"       , "FF /6"                        , "X86"],
+    ["push"             , "R:r64/m64"                                       , "M"       , "FF /6"                        , "X64"],
+    ["push"             , "R:r16"                                           , "O"       , "66 50+r"                      , "ANY"],
+    ["push"             , "R:r32"                                           , "O"       , "50+r"                         , "X86"],
+    ["push"             , "R:r64"                                           , "O"       , "50+r"                         , "X64"],
+    ["push"             , "ib"                                              , "I"       , "6A ib"                        , "ANY"],
+    ["push"             , "iw"                                              , "I"       , "66 68 iw"                     , "ANY"],
+    ["push"             , "id/ud"                                           , "I"       , "68 id"                        , "X86"],
+    ["push"             , "id"                                              , "I"       , "68 id"                        , "X64"],
+    ["push"             , "R:cs"                                            , "NONE"    , "0E"                           , "X86"],
+    ["push"             , "R:ss"                                            , "NONE"    , "16"                           , "X86"],
+    ["push"             , "R:ds"                                            , "NONE"    , "1E"                           , "X86"],
+    ["push"             , "R:es"                                            , "NONE"    , "06"                           , "X86"],
+    ["push"             , "R:fs"                                            , "NONE"    , "0F A0"                        , "ANY"],
+    ["push"             , "R:gs"                                            , "NONE"    , "0F A8"                        , "ANY"],
+    ["in"               , "w:al, ib/ub"                                     , "I"       , "E4 ib"                        , "ANY              Volatile"],
+    ["in"               , "w:ax, ib/ub"                                     , "I"       , "66 E5 ib"                     , "ANY              Volatile"],
+    ["in"               , "W:eax, ib/ub"                                    , "I"       , "E5 ib"                        , "ANY              Volatile"],
+    ["in"               , "w:al, dx"                                        , "NONE"    , "EC"                           , "ANY              Volatile"],
+    ["in"               , "w:ax, dx"                                        , "NONE"    , "66 ED"                        , "ANY              Volatile"],
+    ["in"               , "W:eax, dx"                                       , "NONE"    , "ED"                           , "ANY              Volatile"],
+    ["lfence"           , ""                                                , "NONE"    , "0F AE E8"                     , "SSE2             Volatile"],
+    ["xlatb"            , ""                                                , "NONE"    , "D7"                           , "ANY              Volatile"],
+    ["fadd"             , "R:m32fp"                                         , "M"       , "D8 /0"                        , "FPU              C0=U C1=W C2=U C3=U"],
+    ["fadd"             , "R:m64fp"                                         , "M"       , "DC /0"                        , "FPU              C0=U C1=W C2=U C3=U"],
+    ["fadd"             , "st(0), st(i)"                                    , "O"       , "D8 C0+i"                      , "FPU              C0=U C1=W C2=U C3=U"],
+    ["fadd"             , "st(i), st(0)"                                    , "O"       , "DC C0+i"                      , "FPU              C0=U C1=W C2=U C3=U"],
+    ["addps"            , "X:~xmm, ~xmm/m128"                               , "RM"      , "0F 58 /r"                     , "SSE"],
+    ["vaddps"           , "W:xmm,~xmm,~xmm/m128"                            , "RVM"     , "VEX.128.0F.WIG 58 /r"         , "AVX"],
+    ["vaddps"           , "W:ymm,~ymm,~ymm/m256"                            , "RVM"     , "VEX.256.0F.WIG 58 /r"         , "AVX"],
+    ["vaddps"           , "W:xmm {kz},~xmm,~xmm/m128/b32"                   , "RVM-FV"  , "EVEX.128.0F.W0 58 /r"         , "AVX512_F-VL"],
+    ["vaddps"           , "W:ymm {kz},~ymm,~ymm/m256/b32"                   , "RVM-FV"  , "EVEX.256.0F.W0 58 /r"         , "AVX512_F-VL"],
+    ["vaddps"           , "W:zmm {kz},~zmm,~zmm/m512/b32 {er}"              , "RVM-FV"  , "EVEX.512.0F.W0 58 /r"         , "AVX512_F"]
+  ]
+}
+// ${JSON:END}
+;
+
+}).apply(this, typeof module === "object" && module && module.exports
+  ? [module, "exports"] : [this.asmdb || (this.asmdb = {}), "x86data"]);
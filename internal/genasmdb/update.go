@@ -0,0 +1,144 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+var (
+	// asmdbRepo is the upstream GitHub repository of the asmdb data.
+	asmdbRepo = "asmjit/asmdb"
+
+	// githubAPI and githubRaw are the base URLs of the GitHub REST API and the raw file contents.
+	githubAPI = "https://api.github.com"
+	githubRaw = "https://raw.githubusercontent.com"
+)
+
+// asmdbVersionFile is the file recording the upstream commit of the vendored asmdb data, in the asmdb directory.
+const asmdbVersionFile = "VERSION"
+
+// asmdbCommit represents the upstream commit of the GitHub REST API "GET /repos/{owner}/{repo}/commits/{ref}".
+type asmdbCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Date string `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// httpGet returns the body of the url.
+func httpGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// updateData downloads the asmdb data files of the upstream ref, like "master", verifies they parse,
+// and rewrites the vendored copies in dir with the VERSION file recording the upstream commit and its date.
+// The new findings of the opcodes which don't follow the grammar or don't agree with the operands are logged.
+// The Markdown changelog fragment of the changes from the vendored copies is written to w.
+//
+// The vendored copies are not changed if any file fails to download or parse.
+func updateData(ctx context.Context, w io.Writer, dir, ref string) error {
+	client := &http.Client{Timeout: time.Minute}
+
+	body, err := httpGet(ctx, client, fmt.Sprintf("%s/repos/%s/commits/%s", githubAPI, asmdbRepo, ref))
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	var commit asmdbCommit
+	if err := json.Unmarshal(body, &commit); err != nil {
+		return fmt.Errorf("unmarshal commit of %s: %w", ref, err)
+	}
+	if commit.SHA == "" {
+		return fmt.Errorf("resolve %s: no commit", ref)
+	}
+
+	files := map[string][]byte{}
+	var changelog bytes.Buffer
+	for _, f := range []struct {
+		name   string
+		decode func(data []byte) (*Model, error)
+	}{
+		{asmdbX86DataJS, decodeX86Model},
+		{asmdbArmDataJS, decodeArmModel},
+	} {
+		name := path.Base(f.name)
+		data, err := httpGet(ctx, client, fmt.Sprintf("%s/%s/%s/%s", githubRaw, asmdbRepo, commit.SHA, name))
+		if err != nil {
+			return fmt.Errorf("download %s: %w", name, err)
+		}
+		m, err := f.decode(data)
+		if err != nil {
+			return fmt.Errorf("parse %s of %s: %w", name, commit.SHA, err)
+		}
+		if len(m.X86Instructions) == 0 && len(m.ArmInstructions) == 0 {
+			return fmt.Errorf("parse %s of %s: no instruction", name, commit.SHA)
+		}
+		files[name] = data
+
+		// all instructions are new if the vendored data is missing or doesn't parse
+		old := &Model{Arch: m.Arch, X86: &X86{}, Arm: &Arm{}}
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			if vendored, err := f.decode(data); err == nil {
+				old = vendored
+			}
+		}
+		if err := writeChangelog(&changelog, old, m); err != nil {
+			return err
+		}
+
+		// the opcodes are validated at the bump, the findings already in the vendored data aren't repeated
+		known := make(map[string]bool)
+		for _, f := range validateOpCodes(old, diffForms(old)) {
+			known[f.String()] = true
+		}
+		for _, f := range validateOpCodes(m, diffForms(m)) {
+			if !known[f.String()] {
+				log.Printf("%s of %s: %s", name, commit.SHA, f)
+			}
+		}
+	}
+	version := fmt.Sprintf("%s %s %s", asmdbRepo, commit.SHA, commit.Commit.Committer.Date)
+	files[asmdbVersionFile] = []byte(version + "\n")
+
+	for _, name := range []string{path.Base(asmdbX86DataJS), path.Base(asmdbArmDataJS), asmdbVersionFile} {
+		data := files[name]
+		if err := writeFile(filepath.Join(dir, name), func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "## asmdb data update\n\nUpdated to %s.\n", version)
+	_, err = changelog.WriteTo(w)
+
+	return err
+}
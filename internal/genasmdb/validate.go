@@ -0,0 +1,366 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+func init() {
+	for _, arch := range []string{"x86", "arm"} {
+		registerEmitter(arch, "json", "findings.json", "write the findings of the validation of the "+arch+" forms JSON to `file`", &emitterFunc{name: arch + "-findings", fn: func(m *Model, w io.Writer) error {
+			return writeFindings(w, m)
+		}, out: []*Finding(nil)})
+	}
+}
+
+// Finding represents a problem of the instruction forms found by the validation.
+type Finding struct {
+	// Kind is the kind of the problem, "duplicate" for the form repeating another form with the same fields,
+	// "conflict" for the form of another instruction encoded by the same opcode pattern, "operand" for the operands
+	// which don't follow the grammar, "opcode" for the opcode which doesn't follow the grammar or doesn't agree
+	// with the operands, "encoding" for the x86 operand encoding which doesn't agree with the operands, or "roundtrip"
+	// for the operands, the opcode or the metadata which don't parse to the same structure once formatted.
+	Kind string `json:"kind"`
+
+	// Key is the form of the problem and Other is the earlier form it repeats or conflicts with, if any.
+	Key   FormKey `json:"key"`
+	Other FormKey `json:"other,omitzero"`
+
+	Message string `json:"message"`
+}
+
+// String returns the finding like `conflict: T32 and Rd!=XX, Rn!=XX, #ImmC: ...`.
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Kind, f.Key, f.Message)
+}
+
+// validateForms returns the findings of the opcodes, the duplicate and the conflicting forms of m in the order of the forms.
+func validateForms(m *Model) ([]*Finding, error) {
+	forms := diffForms(m)
+	findings := validateOpCodes(m, forms)
+
+	// the forms of the same key are told apart by the index, the duplicate repeats all fields too
+	seen := make(map[string]FormKey)
+	for _, f := range forms {
+		key := f.key
+		key.Index = 0
+		id := fmt.Sprintf("%s\x00%q", key, f.fields)
+		if other, ok := seen[id]; ok {
+			findings = append(findings, &Finding{Kind: "duplicate", Key: f.key, Other: other, Message: "same fields as " + other.String()})
+			continue
+		}
+		seen[id] = f.key
+	}
+
+	var conflicts []*Finding
+	var err error
+	switch m.Arch {
+	case "x86":
+		conflicts = x86Conflicts(m, forms)
+	case "arm":
+		conflicts, err = armConflicts(m, forms)
+	}
+	if err != nil {
+		return nil, err
+	}
+	addProgress(&progress.Validated, 1)
+	return append(findings, conflicts...), nil
+}
+
+// validateOpCodes returns the findings of the operands and the opcodes of m which don't follow the grammar,
+// and of the opcodes which don't agree with the operands.
+func validateOpCodes(m *Model, forms []diffForm) []*Finding {
+	var findings []*Finding
+	add := func(i int, kind string, probs []string) {
+		for _, p := range probs {
+			findings = append(findings, &Finding{Kind: kind, Key: forms[i].key, Message: p})
+		}
+	}
+	if len(m.X86Instructions) != 0 {
+		kinds := m.X86.operandKinds()
+		for i := range m.X86Instructions {
+			add(i, "operand", m.X86Instructions[i].validateOperands(kinds))
+			add(i, "opcode", m.X86Instructions[i].validateOpCode())
+		}
+		findings = append(findings, validateEncodings(m, forms)...)
+	}
+	for i := range m.ArmInstructions {
+		add(i, "operand", m.ArmInstructions[i].validateOperands())
+		add(i, "opcode", m.ArmInstructions[i].validateOpCode())
+	}
+	return append(findings, validateRoundTrips(m, forms)...)
+}
+
+// validateRoundTrips returns the findings of the x86 operands and opcodes, the ARM opcodes and the metadata of m
+// which don't parse to the same structure once formatted, so the parsers and the formatters stay in sync.
+// The strings which don't parse are the findings of the grammar.
+func validateRoundTrips(m *Model, forms []diffForm) []*Finding {
+	var findings []*Finding
+	check := func(i int, what, s string, parsed, reparsed interface{}) {
+		if !reflect.DeepEqual(parsed, reparsed) {
+			findings = append(findings, &Finding{Kind: "roundtrip", Key: forms[i].key, Message: fmt.Sprintf("%s %q doesn't parse to the same structure", what, s)})
+		}
+	}
+
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		if ops, err := inst.ParseOperands(); err == nil {
+			for _, op := range ops {
+				s := op.String()
+				again, err := parseX86Operand(s)
+				if err == nil {
+					again.Data, again.Index = op.Data, op.Index
+				}
+				check(i, "operand", s, op, again)
+			}
+		}
+		if op, err := inst.ParseOpCode(); err == nil {
+			s := op.String()
+			again, err := parseX86OpCode(s)
+			if err == nil {
+				again.Data = op.Data
+			}
+			check(i, "opcode", s, op, again)
+		}
+		meta := m.X86.ParseMetadata(inst.Metadata)
+		check(i, "metadata", meta.String(), meta, m.X86.ParseMetadata(meta.String()))
+	}
+
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		if enc, err := inst.ParseEncoding(); err == nil {
+			s := enc.String()
+			again, err := parseArmEncoding(s, enc.Width)
+			if err == nil {
+				again.Data = enc.Data
+			}
+			check(i, "opcode", s, enc, again)
+		}
+		meta := m.Arm.ParseMetadata(inst.Metadata)
+		check(i, "metadata", meta.String(), meta, m.Arm.ParseMetadata(meta.String()))
+	}
+
+	return findings
+}
+
+// validateEncodings returns the findings of the x86 forms of m whose operand encoding doesn't agree with the operands.
+func validateEncodings(m *Model, forms []diffForm) []*Finding {
+	if m.X86 == nil || m.X86.Register == nil {
+		return nil
+	}
+	classes := m.X86.Register.classes()
+	var findings []*Finding
+	for i := range m.X86Instructions {
+		for _, p := range m.X86Instructions[i].validateEncoding(classes) {
+			findings = append(findings, &Finding{Kind: "encoding", Key: forms[i].key, Message: p})
+		}
+	}
+	return findings
+}
+
+// checkEncodings returns the error of the x86 forms of m whose operand encoding doesn't agree with the operands,
+// in the format of EntryErrors, or nil.
+func checkEncodings(m *Model) error {
+	findings := validateEncodings(m, diffForms(m))
+	addProgress(&progress.Validated, 1)
+	if len(findings) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d x86 forms with the encoding not agreeing with the operands:", len(findings))
+	for _, f := range findings {
+		fmt.Fprintf(&b, "\n\t%s: %s", f.Key, f.Message)
+	}
+	return errors.New(b.String())
+}
+
+// x86Conflicts returns the conflicts of the x86 forms of another instruction with the same encoding and opcode,
+// which are available in the same mode and both accept or reject the memory operand of the ModR/M byte.
+func x86Conflicts(m *Model, forms []diffForm) []*Finding {
+	type form struct {
+		key   FormKey
+		inst  *X86Instruction
+		archs []string
+		mem   bool
+	}
+	groups := make(map[string][]form)
+	var findings []*Finding
+	for i := range m.X86Instructions {
+		inst := &m.X86Instructions[i]
+		f := form{key: forms[i].key, inst: inst, archs: m.X86.ParseMetadata(inst.Metadata).Architectures}
+		ops, err := inst.ParseOperands()
+		if err != nil {
+			continue
+		}
+		for _, op := range ops {
+			for _, kind := range op.Kinds {
+				// the segment:register of the ModR/M operand, like the "ds:r32" of umonitor, is the register form
+				f.mem = f.mem || x86KindType(kind)&X86OperandMem != 0 && !strings.Contains(kind, ":")
+			}
+		}
+
+		id := inst.Encoding + "\x00" + strings.Join(strings.Fields(inst.OpCode), " ")
+		for _, other := range groups[id] {
+			if other.inst.Name == inst.Name || other.mem != f.mem || !x86ArchsOverlap(other.archs, f.archs) {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Kind:    "conflict",
+				Key:     f.key,
+				Other:   other.key,
+				Message: fmt.Sprintf("%s %s is also the opcode of %s", inst.Encoding, inst.OpCode, other.key),
+			})
+		}
+		groups[id] = append(groups[id], f)
+	}
+	return findings
+}
+
+// x86ArchsOverlap reports whether the forms available in the architectures a and b, like "X86" and "X64",
+// are available in the same mode. The form of no architecture or "ANY" is available in all modes.
+func x86ArchsOverlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 || containsString(a, "ANY") || containsString(b, "ANY") {
+		return true
+	}
+	for _, arch := range a {
+		if containsString(b, arch) {
+			return true
+		}
+	}
+	return false
+}
+
+// armConflicts returns the conflicts of the ARM forms of another instruction with the same fixed bits and fields
+// in the same instruction set.
+//
+// The forms which differ only by the flag-setting "S" suffix are told apart by the IT block, and the IT forms
+// by the mask set from the mnemonic. The aliases, the "mov" of the shifted register as the shift instruction,
+// the forms of the ALIAS_OF, PSEUDO_OF or EncodeAs attribute and the forms constrained by the different values
+// of the same field attribute, like "Op_CMode", share the encoding by design.
+func armConflicts(m *Model, forms []diffForm) ([]*Finding, error) {
+	aliases, err := armAliases(m.Arm, m.ArmInstructions)
+	if err != nil {
+		return nil, err
+	}
+	isAlias := func(name string, of *ArmInstruction) bool {
+		for _, alias := range aliases {
+			if alias.Name == strings.TrimSuffix(name, "S") && alias.isAliasOf(of.Arch, of.Name, of.Operands) {
+				return true
+			}
+		}
+		return false
+	}
+
+	type form struct {
+		key   FormKey
+		inst  *ArmInstruction
+		enc   *ArmEncoding
+		attrs stringMap
+	}
+	type group struct {
+		arch        string
+		mask, value uint32
+	}
+	groups := make(map[group][]form)
+	var findings []*Finding
+	for i := range m.ArmInstructions {
+		inst := &m.ArmInstructions[i]
+		enc, err := inst.ParseEncoding()
+		if err != nil {
+			continue
+		}
+		f := form{key: forms[i].key, inst: inst, enc: enc, attrs: m.Arm.ParseMetadata(inst.Metadata).Attributes}
+		if _, ok := f.attrs["ALIAS_OF"]; ok {
+			continue
+		}
+		if _, ok := f.attrs["PSEUDO_OF"]; ok {
+			continue
+		}
+		if _, ok := f.attrs["EncodeAs"]; ok {
+			continue
+		}
+
+		id := group{arch: inst.Arch, mask: enc.Mask, value: enc.Value}
+	next:
+		for _, other := range groups[id] {
+			name, _ := splitArmName(inst.Name)
+			otherName, _ := splitArmName(other.inst.Name)
+			if strings.TrimSuffix(name, "S") == strings.TrimSuffix(otherName, "S") || !reflect.DeepEqual(enc.Fields, other.enc.Fields) {
+				continue
+			}
+			if strings.HasPrefix(name, "it") && strings.HasPrefix(otherName, "it") {
+				continue
+			}
+			if isAlias(name, other.inst) || isAlias(otherName, inst) || isArmShiftOf(inst, otherName) || isArmShiftOf(other.inst, name) {
+				continue
+			}
+			for attr, v := range f.attrs {
+				if strings.Contains(attr, "_") {
+					if ov, ok := other.attrs[attr]; ok && ov != v {
+						continue next
+					}
+				}
+			}
+			findings = append(findings, &Finding{
+				Kind:    "conflict",
+				Key:     f.key,
+				Other:   other.key,
+				Message: fmt.Sprintf("%s is also the opcode of %s", inst.OpCode, other.key),
+			})
+		}
+		groups[id] = append(groups[id], f)
+	}
+	return findings, nil
+}
+
+// isArmShiftOf reports whether inst is the form of the shifted register operand of the shift instruction name,
+// like "mov Rd, Rn, LSL #Shift" of "lsl".
+func isArmShiftOf(inst *ArmInstruction, name string) bool {
+	shift := strings.ToUpper(strings.TrimSuffix(name, "S"))
+	for _, op := range strings.Split(inst.Operands, ",") {
+		if f := strings.Fields(op); len(f) == 2 && f[0] == shift {
+			return true
+		}
+	}
+	return false
+}
+
+// numFindings is the number of the findings printed by -validate of all architectures.
+var numFindings int32
+
+// writeFindingsText writes the findings of the validation of m to w, one per line prefixed by the architecture,
+// and adds their number to numFindings.
+func writeFindingsText(w io.Writer, m *Model) error {
+	findings, err := validateForms(m)
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", m.Arch, f); err != nil {
+			return err
+		}
+	}
+	atomic.AddInt32(&numFindings, int32(len(findings)))
+
+	return nil
+}
+
+// writeFindings writes the findings of the validation of m as JSON to w.
+func writeFindings(w io.Writer, m *Model) error {
+	findings, err := validateForms(m)
+	if err != nil {
+		return err
+	}
+	if findings == nil {
+		findings = []*Finding{}
+	}
+
+	return writeJSON(w, m.Arch+" findings", findings)
+}
@@ -0,0 +1,96 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// findingsFile is the allowlist of the findings of the asmdb data, the known problems of the upstream data
+// like the D9 FE opcode of fsqrt repeated by another form, in the format printed by -validate.
+const findingsFile = "testdata/findings.txt"
+
+// checkFindings compares the findings of the kinds of the validation of the test models against the allowlist,
+// reporting both the new findings and the allowed findings which are gone.
+func checkFindings(t *testing.T, kinds ...string) {
+	data, err := os.ReadFile(findingsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	of := func(line string) bool {
+		f := strings.SplitN(line, ": ", 3)
+		return len(f) == 3 && containsString(kinds, f[1])
+	}
+	allowed := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		if of(line) {
+			allowed[line] = true
+		}
+	}
+
+	x86, arm := testModels(t)
+	for _, m := range []*Model{x86, arm} {
+		findings, err := validateForms(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range findings {
+			line := m.Arch + ": " + f.String()
+			if !of(line) {
+				continue
+			}
+			if !allowed[line] {
+				t.Errorf("new finding %s", line)
+			}
+			delete(allowed, line)
+		}
+	}
+	for line := range allowed {
+		t.Errorf("allowed finding %s is gone", line)
+	}
+	if t.Failed() {
+		t.Logf("run go run . -validate > %s to rewrite the allowlist", findingsFile)
+	}
+}
+
+func TestValidateOpCodes(t *testing.T) {
+	checkFindings(t, "opcode")
+}
+
+func TestValidateEncodings(t *testing.T) {
+	checkFindings(t, "encoding")
+}
+
+func TestValidateConflicts(t *testing.T) {
+	checkFindings(t, "conflict")
+}
+
+func TestValidateDuplicates(t *testing.T) {
+	checkFindings(t, "duplicate")
+}
+
+func TestValidateOperands(t *testing.T) {
+	checkFindings(t, "operand")
+
+	x86, _ := testModels(t)
+	kinds := x86.X86.operandKinds()
+	for _, operands := range []string{"W:r32, foo", "W:r32, ds:foo", "W:xmm {kz, xmm"} {
+		inst := &X86Instruction{Name: "test", Operands: operands}
+		if probs := inst.validateOperands(kinds); len(probs) == 0 {
+			t.Errorf("validateOperands(%s) = no findings, want some", operands)
+		}
+	}
+	for _, operands := range []string{"Rd, Rn, [Rm", "Rd, {Rn"} {
+		inst := &ArmInstruction{Name: "test", Operands: operands}
+		if probs := inst.validateOperands(); len(probs) == 0 {
+			t.Errorf("validateOperands(%s) = no findings, want some", operands)
+		}
+	}
+}
+
+func TestValidateRoundTrips(t *testing.T) {
+	checkFindings(t, "roundtrip")
+}
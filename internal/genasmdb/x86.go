@@ -3,6 +3,10 @@
 
 package main
 
+import (
+	"strings"
+)
+
 // x86data.js
 //
 // X86/X64 instruction-set data.
@@ -142,3 +146,10 @@ type X86Instruction struct {
 	OpCode   string `json:"opcode"`
 	Metadata string `json:"metadata"`
 }
+
+// Names returns the instruction names.
+//
+// The instruction name can list aliases separated by '/', like "setg/setnle".
+func (inst *X86Instruction) Names() []string {
+	return strings.Split(inst.Name, "/")
+}
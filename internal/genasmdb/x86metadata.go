@@ -0,0 +1,142 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+)
+
+// X86Metadata represents a parsed x86_x64 instruction metadata.
+type X86Metadata struct {
+	// Architectures is the list of architectures the instruction is available, like "ANY" and "X64".
+	Architectures []string `json:"architectures,omitzero"`
+
+	// Extensions is the list of required CPU extensions.
+	Extensions []string `json:"extensions,omitzero"`
+
+	// Attributes maps the attribute name to its value. Flag attributes have an empty value.
+	Attributes stringMap `json:"attributes,omitzero"`
+
+	// SpecialRegs maps the special register name to its access, like "FLAGS.CF": "W".
+	SpecialRegs stringMap `json:"specialRegs,omitzero"`
+}
+
+// ParseMetadata parses the instruction metadata with the x86 definitions.
+func (x *X86) ParseMetadata(s string) *X86Metadata {
+	meta := &X86Metadata{}
+	for _, field := range strings.Fields(s) {
+		name, value := field, ""
+		if i := strings.IndexByte(field, '='); i >= 0 {
+			name, value = field[:i], field[i+1:]
+		}
+		x.addMetadata(meta, name, value)
+	}
+
+	return meta
+}
+
+// String returns the metadata string of meta with the shortcuts expanded, the architectures, the extensions,
+// and the sorted attributes and special registers, like "X64 AVX512_F AVX512_VL OF=W".
+func (meta *X86Metadata) String() string {
+	var fields []string
+	fields = append(fields, meta.Architectures...)
+	fields = append(fields, meta.Extensions...)
+	fields = append(fields, meta.Attributes.metadataFields()...)
+	fields = append(fields, meta.SpecialRegs.metadataFields()...)
+	return strings.Join(fields, " ")
+}
+
+// addMetadata adds the name and value to meta with expanding shortcuts.
+func (x *X86) addMetadata(meta *X86Metadata, name, value string) {
+	if expand := x.shortcut(name); expand != "" {
+		for _, n := range expandShortcut(expand) {
+			x.addMetadata(meta, n, value)
+		}
+		return
+	}
+
+	switch {
+	case x.hasArchitecture(name):
+		meta.Architectures = append(meta.Architectures, name)
+	case x.hasSpecialReg(name):
+		if meta.SpecialRegs == nil {
+			meta.SpecialRegs = make(map[string]string)
+		}
+		meta.SpecialRegs[name] = value
+	case x.hasExtension(strings.SplitN(name, "-", 2)[0]):
+		// "AVX512_F-VL" means both of AVX512_F and AVX512_VL are required
+		parts := strings.Split(name, "-")
+		meta.Extensions = append(meta.Extensions, parts[0])
+		for _, part := range parts[1:] {
+			if !x.hasExtension(part) {
+				if i := strings.IndexByte(parts[0], '_'); i >= 0 {
+					part = parts[0][:i+1] + part
+				}
+			}
+			meta.Extensions = append(meta.Extensions, part)
+		}
+	default:
+		if meta.Attributes == nil {
+			meta.Attributes = make(map[string]string)
+		}
+		meta.Attributes[name] = value
+	}
+}
+
+// expandShortcut splits the shortcut expansion by '|'.
+//
+// The parts following a dotted part inherit its prefix, so "APSR.N|Z" expands to "APSR.N" and "APSR.Z".
+func expandShortcut(expand string) []string {
+	parts := strings.Split(expand, "|")
+	prefix := ""
+	for i, part := range parts {
+		if j := strings.LastIndexByte(part, '.'); j >= 0 {
+			prefix = part[:j+1]
+			continue
+		}
+		parts[i] = prefix + part
+	}
+
+	return parts
+}
+
+// shortcut returns the expansion of the name shortcut, or empty if name is not a shortcut.
+func (x *X86) shortcut(name string) string {
+	for _, sc := range x.Shortcuts {
+		if sc.Name == name {
+			return sc.Expand
+		}
+	}
+	return ""
+}
+
+// hasArchitecture reports whether the name is a known architecture.
+func (x *X86) hasArchitecture(name string) bool {
+	for _, arch := range x.Architectures {
+		if arch == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExtension reports whether the name is a known extension.
+func (x *X86) hasExtension(name string) bool {
+	for _, ext := range x.Extensions {
+		if ext.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSpecialReg reports whether the name is a known special register.
+func (x *X86) hasSpecialReg(name string) bool {
+	for _, reg := range x.SpecialRegs {
+		if reg.Name == name {
+			return true
+		}
+	}
+	return false
+}
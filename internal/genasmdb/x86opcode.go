@@ -0,0 +1,351 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// X86OpCode represents a parsed x86_x64 instruction opcode, like "EVEX.512.66.0F38.W0 58 /r".
+type X86OpCode struct {
+	// Data is the opcode string as written in x86data.js.
+	Data string `json:"data"`
+
+	// Prefix is the encoding prefix, one of "VEX", "EVEX", "XOP" or empty for the legacy encoding.
+	Prefix string `json:"prefix,omitzero"`
+
+	// L, PP, Map and W are the fields of the VEX, EVEX and XOP prefix, like "512", "66", "0F38" and "W0".
+	L   string `json:"l,omitzero"`
+	PP  string `json:"pp,omitzero"`
+	Map string `json:"map,omitzero"`
+	W   string `json:"w,omitzero"`
+
+	// REXW reports whether the legacy encoding requires the REX.W prefix.
+	REXW bool `json:"rexw,omitzero"`
+
+	// Prefixes is the list of legacy prefix bytes preceding the opcode, like 0x66 and 0xF3.
+	Prefixes []byte `json:"prefixes,omitzero"`
+
+	// Bytes is the opcode bytes including the legacy escape bytes, like 0x0F 0x38 0xF8.
+	Bytes []byte `json:"bytes"`
+
+	// ModRM is the ModRM usage, "r" for "/r", "0".."7" for "/digit" or empty if the ModRM is not used.
+	ModRM string `json:"modrm,omitzero"`
+
+	// PlusR reports whether the register is encoded in the low 3 bits of the opcode ("+r").
+	PlusR bool `json:"plusr,omitzero"`
+
+	// PlusI reports whether the FPU stack register is encoded in the low 3 bits of the opcode ("+i").
+	PlusI bool `json:"plusi,omitzero"`
+
+	// Imm is the list of immediates and code offsets following the opcode, like "ib", "cd" and "is4".
+	Imm []string `json:"imm,omitzero"`
+}
+
+// x86LegacyPrefixes is a list of legacy prefix bytes that can precede the opcode.
+var x86LegacyPrefixes = map[string]bool{
+	"66": true,
+	"67": true,
+	"9B": true,
+	"F0": true,
+	"F2": true,
+	"F3": true,
+}
+
+// x86OpCodeImms is a list of immediate and code offset tokens.
+var x86OpCodeImms = map[string]bool{
+	"ib": true,
+	"iw": true,
+	"id": true,
+	"iq": true,
+	"cb": true,
+	"cw": true,
+	"cd": true,
+}
+
+// ParseOpCode parses the instruction opcode.
+func (inst *X86Instruction) ParseOpCode() (*X86OpCode, error) {
+	return parseX86OpCode(inst.OpCode)
+}
+
+// parseX86OpCode parses the x86_x64 opcode string.
+func parseX86OpCode(s string) (*X86OpCode, error) {
+	op := &X86OpCode{
+		Data: s,
+	}
+
+	for _, tok := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(tok, "VEX."), strings.HasPrefix(tok, "EVEX."), strings.HasPrefix(tok, "XOP."):
+			if err := op.parseVEX(tok); err != nil {
+				return nil, err
+			}
+
+		case tok == "REX.W":
+			op.REXW = true
+
+		case tok == "/r":
+			op.ModRM = "r"
+
+		case len(tok) == 2 && tok[0] == '/' && tok[1] >= '0' && tok[1] <= '7':
+			op.ModRM = tok[1:]
+
+		case tok == "/is4":
+			op.Imm = append(op.Imm, "is4")
+
+		case x86OpCodeImms[tok]:
+			op.Imm = append(op.Imm, tok)
+
+		case len(op.Bytes) == 0 && op.Prefix == "" && x86LegacyPrefixes[tok]:
+			op.Prefixes = append(op.Prefixes, mustParseHexByte(tok))
+
+		default:
+			hex := tok
+			switch {
+			case strings.HasSuffix(tok, "+r"):
+				op.PlusR = true
+				hex = strings.TrimSuffix(tok, "+r")
+			case strings.HasSuffix(tok, "+i"):
+				op.PlusI = true
+				hex = strings.TrimSuffix(tok, "+i")
+			}
+			b, err := strconv.ParseUint(hex, 16, 8)
+			if err != nil || len(hex) != 2 {
+				return nil, fmt.Errorf("invalid opcode token %q in %q", tok, s)
+			}
+			op.Bytes = append(op.Bytes, byte(b))
+		}
+	}
+
+	// the prefix byte alone is the opcode, like "9B" of fwait
+	if len(op.Bytes) == 0 && len(op.Prefixes) > 0 {
+		op.Bytes = op.Prefixes[len(op.Prefixes)-1:]
+		op.Prefixes = op.Prefixes[:len(op.Prefixes)-1]
+	}
+	if len(op.Bytes) == 0 {
+		return nil, fmt.Errorf("no opcode byte in %q", s)
+	}
+
+	return op, nil
+}
+
+// parseVEX parses the VEX, EVEX and XOP prefix token, like "VEX.128.66.0F38.W0".
+func (op *X86OpCode) parseVEX(tok string) error {
+	fields := strings.Split(tok, ".")
+	op.Prefix = fields[0]
+	for _, f := range fields[1:] {
+		switch f {
+		case "128", "256", "512", "L0", "L1", "LIG", "LZ":
+			op.L = f
+		case "66", "F2", "F3", "NP", "P0":
+			op.PP = f
+		case "0F", "0F38", "0F3A", "MAP5", "MAP6", "M08", "M09", "M0A":
+			op.Map = f
+		case "W0", "W1", "WIG":
+			op.W = f
+		default:
+			return fmt.Errorf("invalid %s field %q in %q", op.Prefix, f, tok)
+		}
+	}
+
+	return nil
+}
+
+// String returns the opcode string of op, like "66 REX.W 0F 3A 16 /r ib" and "EVEX.512.66.0F38.W0 58 /r".
+func (op *X86OpCode) String() string {
+	var toks []string
+	if op.Prefix != "" {
+		tok := op.Prefix
+		for _, f := range []string{op.L, op.PP, op.Map, op.W} {
+			if f != "" {
+				tok += "." + f
+			}
+		}
+		toks = append(toks, tok)
+	}
+	for _, b := range op.Prefixes {
+		toks = append(toks, fmt.Sprintf("%02X", b))
+	}
+	if op.REXW {
+		toks = append(toks, "REX.W")
+	}
+	for i, b := range op.Bytes {
+		tok := fmt.Sprintf("%02X", b)
+		if i == len(op.Bytes)-1 {
+			switch {
+			case op.PlusR:
+				tok += "+r"
+			case op.PlusI:
+				tok += "+i"
+			}
+		}
+		toks = append(toks, tok)
+	}
+	if op.ModRM != "" {
+		toks = append(toks, "/"+op.ModRM)
+	}
+	for _, imm := range op.Imm {
+		if imm == "is4" {
+			imm = "/is4"
+		}
+		toks = append(toks, imm)
+	}
+	return strings.Join(toks, " ")
+}
+
+// Opcode returns the primary opcode byte.
+func (op *X86OpCode) Opcode() byte {
+	return op.Bytes[len(op.Bytes)-1]
+}
+
+// mustParseHexByte parses the two digits hexadecimal byte and panics if s is not valid.
+func mustParseHexByte(s string) byte {
+	b, err := strconv.ParseUint(s, 16, 8)
+	if err != nil {
+		panic(fmt.Sprintf("invalid hex byte %q", s))
+	}
+	return byte(b)
+}
+
+// x86OpCodeImmSizes is the size in bytes of the immediate and code offset tokens of the opcode.
+var x86OpCodeImmSizes = map[string]int{
+	"ib": 1, "iw": 2, "id": 4, "iq": 8,
+	"cb": 1, "cw": 2, "cd": 4,
+}
+
+// x86KindImmSize returns the size in bytes of the immediate or relative displacement operand kind, or 0.
+func x86KindImmSize(kind string) int {
+	switch kind {
+	case "ib", "ub", "i4", "u4", "rel8":
+		return 1
+	case "iw", "uw", "rel16":
+		return 2
+	case "id", "ud", "rel32":
+		return 4
+	case "iq", "uq":
+		return 8
+	}
+	return 0
+}
+
+// validateOpCode returns the problems of the opcode grammar of inst and of the agreement of the opcode with the
+// encoding and the operands, each token is recognized and appears once in its place, the operand encoding uses
+// the ModRM, the opcode register and the /is4 of the opcode, the moffs operands are the legacy offsets of the accumulator
+// without the ModRM, and the immediates follow the immediate operands.
+func (inst *X86Instruction) validateOpCode() []string {
+	op, err := inst.ParseOpCode()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var probs []string
+	var prefixes, modrms int
+	imm := false
+	for _, tok := range strings.Fields(inst.OpCode) {
+		switch {
+		case strings.HasPrefix(tok, "VEX."), strings.HasPrefix(tok, "EVEX."), strings.HasPrefix(tok, "XOP."):
+			prefixes++
+		case tok == "/r", len(tok) == 2 && tok[0] == '/':
+			modrms++
+		case tok == "/is4", x86OpCodeImms[tok]:
+			imm = true
+			continue
+		}
+		if imm {
+			probs = append(probs, fmt.Sprintf("token %q after the immediate", tok))
+		}
+	}
+	switch {
+	case prefixes > 1:
+		probs = append(probs, "more than one "+op.Prefix+" prefix")
+	case op.Prefix != "" && op.Map == "":
+		probs = append(probs, op.Prefix+" prefix without the opcode map")
+	case op.Prefix != "" && op.REXW:
+		probs = append(probs, "REX.W with the "+op.Prefix+" prefix")
+	}
+	if modrms > 1 {
+		probs = append(probs, "more than one ModRM")
+	}
+	if (op.PlusR || op.PlusI) && op.ModRM != "" {
+		probs = append(probs, "opcode register with the ModRM")
+	}
+
+	// the operand encoding, like "RVM" of "RVM-FV"
+	enc := strings.SplitN(inst.Encoding, "-", 2)[0]
+	if enc == "NONE" {
+		enc = ""
+	}
+	if strings.ContainsAny(enc, "RM") && op.ModRM == "" {
+		probs = append(probs, fmt.Sprintf("encoding %s without the ModRM", inst.Encoding))
+	}
+	if strings.Contains(enc, "O") != (op.PlusR || op.PlusI) {
+		probs = append(probs, fmt.Sprintf("encoding %s doesn't agree with the opcode register", inst.Encoding))
+	}
+	is4 := len(op.Imm) > 0 && op.Imm[len(op.Imm)-1] == "is4"
+	if strings.Contains(enc, "S") != is4 {
+		probs = append(probs, fmt.Sprintf("encoding %s doesn't agree with the /is4", inst.Encoding))
+	}
+
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return append(probs, err.Error())
+	}
+	// the moffs operand is the legacy absolute offset of the accumulator, like "A1" of "mov eax, moff32"
+	for _, o := range ops {
+		if !o.IsMoffs() {
+			continue
+		}
+		if op.Prefix != "" {
+			probs = append(probs, fmt.Sprintf("moffs operand %s with the %s prefix", o.Data, op.Prefix))
+		}
+		if op.ModRM != "" {
+			probs = append(probs, fmt.Sprintf("moffs operand %s with the ModRM", o.Data))
+		}
+		for _, other := range ops {
+			if other.Index != o.Index && !other.Implicit && !containsString([]string{"al", "ax", "eax", "rax"}, other.Kinds[0]) {
+				probs = append(probs, fmt.Sprintf("moffs operand %s with operand %s which isn't the accumulator", o.Data, other.Data))
+			}
+		}
+	}
+	var sizes [][]int // sizes of the kinds of each immediate operand
+	last := ""        // first kind of the last immediate operand
+	for _, o := range ops {
+		if o.Implicit || !o.IsImm() && !o.IsRel() || len(o.Kinds) == 1 && o.Kinds[0] == "1" {
+			continue
+		}
+		var s []int
+		for _, kind := range o.Kinds {
+			s = append(s, x86KindImmSize(kind))
+		}
+		sizes = append(sizes, s)
+		last = o.Kinds[0]
+	}
+	imms := op.Imm
+	if is4 {
+		imms = imms[:len(imms)-1]
+		// the /is4 byte also encodes the trailing 4-bit immediate, like the one of vpermil2ps
+		if len(sizes) > len(imms) && last == "i4" {
+			sizes = sizes[:len(sizes)-1]
+		}
+	}
+	if len(imms) != len(sizes) {
+		return append(probs, fmt.Sprintf("%d immediates for %d immediate operands", len(imms), len(sizes)))
+	}
+	// the immediates of the far pointer are in the reverse order of the operands, like "id iw" of "iw, id"
+	used := make([]bool, len(sizes))
+next:
+	for _, tok := range imms {
+		for i := range sizes {
+			if !used[i] && containsInt(sizes[i], x86OpCodeImmSizes[tok]) {
+				used[i] = true
+				continue next
+			}
+		}
+		probs = append(probs, fmt.Sprintf("immediate %s doesn't agree with the immediate operands", tok))
+	}
+
+	return probs
+}
@@ -0,0 +1,29 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func FuzzParseOpCode(f *testing.F) {
+	for _, s := range []string{"D9 FE", "0F 05", "REX.W 8B /r", "C7 /0 id", "B8+r iw", "66 0F 38 00 /r", "VEX.128.66.0F.WIG 58 /r", "EVEX.512.66.0F38.W0 90 /vsib", "F3 REX.W 0F B8 /r", "9B DF E0"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		op, err := parseX86OpCode(s)
+		if err != nil {
+			return
+		}
+		again, err := parseX86OpCode(op.String())
+		if err != nil {
+			t.Fatalf("parseX86OpCode(%q) of %q: %v", op.String(), s, err)
+		}
+		again.Data = op.Data
+		if !reflect.DeepEqual(op, again) {
+			t.Errorf("parseX86OpCode(%q) = %+v, want %+v of %q", op.String(), again, op, s)
+		}
+	})
+}
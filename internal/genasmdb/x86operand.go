@@ -0,0 +1,518 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// X86OperandType represents a type of x86_x64 operand kind.
+//
+// The operand can have multiple types if it accepts several kinds, like "r8/m8".
+type X86OperandType uint8
+
+const (
+	// X86OperandReg is a register operand.
+	X86OperandReg X86OperandType = 1 << iota
+	// X86OperandMem is a memory operand.
+	X86OperandMem
+	// X86OperandImm is an immediate operand.
+	X86OperandImm
+	// X86OperandRel is a relative displacement operand.
+	X86OperandRel
+	// X86OperandMoffs is a direct memory offset operand, like "moff32" of "mov eax, moff32", which is also
+	// a memory operand. The offset is encoded after the opcode without the ModRM.
+	X86OperandMoffs
+)
+
+// X86Operand represents a parsed x86_x64 instruction operand.
+type X86Operand struct {
+	// Data is the operand string as written in x86data.js.
+	Data string `json:"data"`
+
+	// Index is the operand position in the instruction operands.
+	Index int `json:"index"`
+
+	// Access is the read/write access prefix, one of "R", "w", "W", "x", "X".
+	// It's empty if the operand doesn't specify it.
+	Access string `json:"access,omitzero"`
+
+	// Kinds is the list of accepted operand kinds, like "r8" and "m8" for "r8/m8".
+	Kinds []string `json:"kinds"`
+
+	// Decorators is the list of AVX-512 decorators following the operand, like "{kz}" and "{er}".
+	Decorators []string `json:"decorators,omitzero"`
+
+	// Implicit reports whether the operand is implicit ("<op>").
+	Implicit bool `json:"implicit,omitzero"`
+
+	// Optional reports whether the operand is optional ("{op}").
+	Optional bool `json:"optional,omitzero"`
+
+	// Commutative reports whether the operand is commutative with other "~" operands.
+	Commutative bool `json:"commutative,omitzero"`
+
+	// RangeHi and RangeLo are the bit-range that is read and written ("op[hi:lo]").
+	// Both are -1 if the operand doesn't specify the bit-range.
+	RangeHi int `json:"rangeHi"`
+	RangeLo int `json:"rangeLo"`
+}
+
+// x86ImmKinds is a list of immediate operand kinds.
+var x86ImmKinds = map[string]bool{
+	"1":  true,
+	"i4": true, "u4": true,
+	"ib": true, "ub": true,
+	"iw": true, "uw": true,
+	"id": true, "ud": true,
+	"iq": true, "uq": true,
+}
+
+// x86KindType returns the X86OperandType of the operand kind.
+func x86KindType(kind string) X86OperandType {
+	switch {
+	case x86ImmKinds[kind]:
+		return X86OperandImm
+	case strings.HasPrefix(kind, "rel"):
+		return X86OperandRel
+	case strings.HasPrefix(kind, "moff"):
+		return X86OperandMem | X86OperandMoffs
+	case kind == "mem", kind == "mib", kind == "tmem",
+		strings.HasPrefix(kind, "vm"),
+		strings.Contains(kind, ":"), // implicit segment:register memory, like "ds:zsi"
+		len(kind) > 1 && kind[0] == 'm' && kind[1] >= '0' && kind[1] <= '9':
+		return X86OperandMem
+	}
+	return X86OperandReg
+}
+
+// Type returns the union of the X86OperandType of all operand kinds.
+func (op *X86Operand) Type() X86OperandType {
+	var t X86OperandType
+	for _, kind := range op.Kinds {
+		t |= x86KindType(kind)
+	}
+	return t
+}
+
+// IsReg reports whether the operand accepts a register.
+func (op *X86Operand) IsReg() bool { return op.Type()&X86OperandReg != 0 }
+
+// IsMem reports whether the operand accepts a memory.
+func (op *X86Operand) IsMem() bool { return op.Type()&X86OperandMem != 0 }
+
+// IsImm reports whether the operand accepts an immediate.
+func (op *X86Operand) IsImm() bool { return op.Type()&X86OperandImm != 0 }
+
+// IsRel reports whether the operand accepts a relative displacement.
+func (op *X86Operand) IsRel() bool { return op.Type()&X86OperandRel != 0 }
+
+// IsMoffs reports whether the operand accepts a direct memory offset.
+func (op *X86Operand) IsMoffs() bool { return op.Type()&X86OperandMoffs != 0 }
+
+// MoffsOffsetSize returns the size in bytes of the offset of the moffs operand in the processor mode bits,
+// 16, 32 or 64. It's the address size of the mode, which the 0x67 prefix overrides, like 8 bytes in the 64-bit
+// mode and 4 with the prefix. It returns 0 if the operand isn't a moffs operand.
+func (op *X86Operand) MoffsOffsetSize(mode int, addrOverride bool) (int, error) {
+	if !op.IsMoffs() {
+		return 0, nil
+	}
+
+	var size, override int
+	switch mode {
+	case 16:
+		size, override = 2, 4
+	case 32:
+		size, override = 4, 2
+	case 64:
+		size, override = 8, 4
+	default:
+		return 0, fmt.Errorf("invalid processor mode %d", mode)
+	}
+	if addrOverride {
+		return override, nil
+	}
+	return size, nil
+}
+
+// IsRead reports whether the operand is read by the instruction.
+//
+// If the operand doesn't specify the access, the first operand is assumed to be
+// read/write and all following operands are assumed to be read-only.
+func (op *X86Operand) IsRead() bool {
+	switch op.Access {
+	case "R", "x", "X":
+		return true
+	case "w", "W":
+		return false
+	}
+	return true
+}
+
+// IsWrite reports whether the operand is written by the instruction.
+//
+// If the operand doesn't specify the access, the first operand is assumed to be
+// read/write and all following operands are assumed to be read-only.
+func (op *X86Operand) IsWrite() bool {
+	switch op.Access {
+	case "w", "W", "x", "X":
+		return true
+	case "R":
+		return false
+	}
+	return op.Index == 0 && !op.IsImm() && !op.IsRel()
+}
+
+// ParseOperands parses the instruction operands.
+func (inst *X86Instruction) ParseOperands() ([]*X86Operand, error) {
+	return parseX86Operands(inst.Operands)
+}
+
+// parseX86Operands parses the comma separated x86_x64 operands string.
+func parseX86Operands(s string) ([]*X86Operand, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(s, ",")
+	ops := make([]*X86Operand, len(fields))
+	for i, field := range fields {
+		op, err := parseX86Operand(field)
+		if err != nil {
+			return nil, fmt.Errorf("parse operand %d of %q: %w", i, s, err)
+		}
+		op.Index = i
+		ops[i] = op
+	}
+
+	return ops, nil
+}
+
+// parseX86Operand parses the single x86_x64 operand string.
+func parseX86Operand(s string) (*X86Operand, error) {
+	op := &X86Operand{
+		Data:    strings.TrimSpace(s),
+		RangeHi: -1,
+		RangeLo: -1,
+	}
+
+	fields := strings.Fields(op.Data)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty operand")
+	}
+	main := fields[0]
+	for _, deco := range fields[1:] {
+		if len(deco) < 3 || deco[0] != '{' || deco[len(deco)-1] != '}' {
+			return nil, fmt.Errorf("invalid decorator %q", deco)
+		}
+		op.Decorators = append(op.Decorators, deco)
+	}
+
+	// the commutative mark can appear before or after the access prefix
+	if strings.HasPrefix(main, "~") {
+		op.Commutative = true
+		main = main[1:]
+	}
+	if len(main) > 2 && main[1] == ':' && strings.IndexByte("RwWxX", main[0]) >= 0 {
+		op.Access = main[:1]
+		main = main[2:]
+	}
+	if strings.HasPrefix(main, "~") {
+		op.Commutative = true
+		main = main[1:]
+	}
+
+	switch {
+	case strings.HasPrefix(main, "<"):
+		if !strings.HasSuffix(main, ">") {
+			return nil, fmt.Errorf("unmatched '<' in %q", main)
+		}
+		op.Implicit = true
+		main = main[1 : len(main)-1]
+	case strings.HasPrefix(main, "{"):
+		if !strings.HasSuffix(main, "}") {
+			return nil, fmt.Errorf("unmatched '{' in %q", main)
+		}
+		op.Optional = true
+		main = main[1 : len(main)-1]
+	}
+
+	if i := strings.IndexByte(main, '['); i >= 0 {
+		j := strings.IndexByte(main[i:], ']')
+		if j < 0 {
+			return nil, fmt.Errorf("unmatched '[' in %q", main)
+		}
+		hi, lo, err := parseX86BitRange(main[i+1 : i+j])
+		if err != nil {
+			return nil, err
+		}
+		op.RangeHi, op.RangeLo = hi, lo
+		main = main[:i] + main[i+j+1:]
+	}
+
+	for _, kind := range strings.Split(main, "/") {
+		if kind == "" {
+			return nil, fmt.Errorf("empty operand kind in %q", op.Data)
+		}
+		// the kinds are the names like "m16_32", "st(i)", "xmm+3" or "ds:zsi", the other marks belong to the operand
+		for _, r := range kind {
+			if isNotX86KindRune(r) {
+				return nil, fmt.Errorf("invalid character %q in operand kind %q", r, kind)
+			}
+		}
+		op.Kinds = append(op.Kinds, kind)
+	}
+
+	return op, nil
+}
+
+// isNotX86KindRune reports whether r isn't a letter, a digit or one of "()+:_" of the x86 operand kinds.
+func isNotX86KindRune(r rune) bool {
+	return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune("()+:_", r))
+}
+
+// String returns the operand string of op, like "X:~xmm[63:0]/m64 {kz}".
+//
+// The commutative mark follows the access prefix and the bit-range follows the first kind.
+func (op *X86Operand) String() string {
+	var b strings.Builder
+	if op.Access != "" {
+		b.WriteString(op.Access + ":")
+	}
+	if op.Commutative {
+		b.WriteByte('~')
+	}
+	switch {
+	case op.Implicit:
+		b.WriteByte('<')
+	case op.Optional:
+		b.WriteByte('{')
+	}
+	for i, kind := range op.Kinds {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		b.WriteString(kind)
+		if i == 0 && op.RangeHi >= 0 {
+			fmt.Fprintf(&b, "[%d:%d]", op.RangeHi, op.RangeLo)
+		}
+	}
+	switch {
+	case op.Implicit:
+		b.WriteByte('>')
+	case op.Optional:
+		b.WriteByte('}')
+	}
+	for _, deco := range op.Decorators {
+		b.WriteString(" " + deco)
+	}
+	return b.String()
+}
+
+// parseX86BitRange parses the "hi:lo" bit-range.
+func parseX86BitRange(s string) (hi, lo int, err error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid bit-range %q", s)
+	}
+	if hi, err = strconv.Atoi(s[:i]); err != nil {
+		return 0, 0, fmt.Errorf("invalid bit-range %q: %w", s, err)
+	}
+	if lo, err = strconv.Atoi(s[i+1:]); err != nil {
+		return 0, 0, fmt.Errorf("invalid bit-range %q: %w", s, err)
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("invalid bit-range %q: hi is less than lo", s)
+	}
+	if lo < 0 {
+		return 0, 0, fmt.Errorf("invalid bit-range %q: negative lo", s)
+	}
+
+	return hi, lo, nil
+}
+
+// classes returns the register data of each register class, like "r32" and "xmm".
+func (r *X86Register) classes() map[string]*X86RegisterData {
+	return map[string]*X86RegisterData{
+		"bnd": r.Bnd, "creg": r.Creg, "dreg": r.Dreg, "k": r.K, "mm": r.Mm,
+		"r8": r.R8, "r8hi": r.R8hi, "r16": r.R16, "r32": r.R32, "r64": r.R64, "rxx": r.Rxx,
+		"sreg": r.Sreg, "st": r.St, "tmm": r.Tmm, "xmm": r.Xmm, "ymm": r.Ymm, "zmm": r.Zmm,
+	}
+}
+
+// x86RegRangeRe matches the register names range, like "xmm0-31", "r8-15b" and "st(0-7)".
+var x86RegRangeRe = regexp.MustCompile(`^([a-z]+\(?)(\d+)-(\d+)(\)?[a-z]?)$`)
+
+// x86RegWidths maps the register class to its width in bits.
+var x86RegWidths = map[string]int{
+	"r8": 8, "r16": 16, "r32": 32, "r64": 64,
+	"mm": 64, "k": 64,
+	"xmm": 128, "ymm": 256, "zmm": 512,
+}
+
+// operandKinds returns the set of the operand kinds known by x, the register classes and their "any" names,
+// the register names with the ranges expanded, the immediates, the relative displacements and the memory kinds.
+func (x *X86) operandKinds() map[string]bool {
+	kinds := map[string]bool{
+		"1": true, "mem": true, "mib": true, "tmem": true,
+		"rel8": true, "rel16": true, "rel32": true,
+		"b16": true, "b32": true, "b64": true,
+		"vm32x": true, "vm32y": true, "vm32z": true, "vm64x": true, "vm64y": true, "vm64z": true,
+	}
+	for kind := range x86ImmKinds {
+		kinds[kind] = true
+	}
+	for kind := range x86MemSizes {
+		kinds[kind] = true
+	}
+	if x.Register == nil {
+		return kinds
+	}
+	for class, r := range x.Register.classes() {
+		kinds[class] = true
+		if r == nil {
+			continue
+		}
+		if r.Any != "" {
+			kinds[r.Any] = true
+		}
+		for _, name := range r.Names {
+			m := x86RegRangeRe.FindStringSubmatch(name)
+			if m == nil {
+				kinds[name] = true
+				continue
+			}
+			lo, _ := strconv.Atoi(m[2])
+			hi, _ := strconv.Atoi(m[3])
+			for n := lo; n <= hi; n++ {
+				kinds[m[1]+strconv.Itoa(n)+m[4]] = true
+			}
+		}
+	}
+	return kinds
+}
+
+// validateOperands returns the problems of the operand grammar of inst, each operand kind is known by kinds,
+// the access prefix is one of "R", "w", "W", "x" and "X", the brackets are matched, and the bit-range is in the operand.
+func (inst *X86Instruction) validateOperands(kinds map[string]bool) []string {
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var probs []string
+	for _, op := range ops {
+		for _, kind := range op.Kinds {
+			// the consecutive registers, like "xmm+3" of the 4 registers
+			if i := strings.IndexByte(kind, '+'); i > 0 && kinds[kind[:i]] {
+				if _, err := strconv.Atoi(kind[i+1:]); err == nil {
+					continue
+				}
+			}
+			// the implicit memory of the segment and the address register, like "ds:zsi"
+			if i := strings.IndexByte(kind, ':'); i >= 0 {
+				if !kinds[kind[:i]] || !kinds[kind[i+1:]] {
+					probs = append(probs, fmt.Sprintf("invalid access prefix or segment of operand %s", op.Data))
+				}
+				continue
+			}
+			switch {
+			case strings.ContainsAny(kind, "<>{}[]") || strings.ContainsAny(kind, "()") && !kinds[kind]:
+				probs = append(probs, fmt.Sprintf("unmatched bracket in operand %s", op.Data))
+			case !kinds[kind]:
+				probs = append(probs, fmt.Sprintf("unknown kind %q of operand %s", kind, op.Data))
+			}
+		}
+
+		if op.RangeHi < 0 {
+			continue
+		}
+		for _, kind := range op.Kinds {
+			width := x86RegWidths[kind]
+			if size, ok := x86MemSizes[kind]; ok {
+				width = size * 8
+			}
+			if width != 0 && op.RangeHi >= width {
+				probs = append(probs, fmt.Sprintf("bit-range [%d:%d] out of the %d bits of operand %s", op.RangeHi, op.RangeLo, width, op.Data))
+			}
+		}
+	}
+
+	return probs
+}
+
+// validateEncoding returns the problems of the agreement of the operand encoding of inst, like "RMI" of "RMI-FV",
+// with the encoded operands, the operands without the implicit ones, the fixed registers, like "al" and "dx",
+// the shift count "1", the absolute memory offset, the implicit segment memory, like "es:zdi", and the registers
+// following the first of the consecutive registers, like "xmm+1".
+//
+// Each letter is the encoding of the operand in order, "R" the register of the ModRM reg, "M" the register or memory
+// of the ModRM r/m, "V" the register of VEX.vvvv, "S" the register of /is4, "O" the register of the opcode,
+// "I" the immediate and "D" the relative displacement.
+func (inst *X86Instruction) validateEncoding(classes map[string]*X86RegisterData) []string {
+	ops, err := inst.ParseOperands()
+	if err != nil {
+		return nil
+	}
+
+	// the encoded operands and whether each accepts only registers
+	var encoded []*X86Operand
+	var regs []bool
+	for _, op := range ops {
+		if op.Implicit {
+			continue
+		}
+		skip, reg := true, true
+		for _, kind := range op.Kinds {
+			switch i := strings.IndexByte(kind, ':'); {
+			case strings.IndexByte(kind, '+') > 0, kind == "1", strings.HasPrefix(kind, "moff"):
+			case i >= 0:
+				// the segment and the register of the ModRM, like "es:r32" of movdir64b
+				if classes[kind[i+1:]] != nil {
+					skip = false
+				}
+			case x86KindType(kind) != X86OperandReg:
+				skip, reg = false, false
+			case classes[kind] != nil, kind == "st(i)":
+				skip = false
+			}
+		}
+		if !skip {
+			encoded = append(encoded, op)
+			regs = append(regs, reg)
+		}
+	}
+
+	letters := strings.SplitN(inst.Encoding, "-", 2)[0]
+	if letters == "NONE" {
+		letters = ""
+	}
+	if len(letters) != len(encoded) {
+		return []string{fmt.Sprintf("encoding %s of %d letters for %d encoded operands", inst.Encoding, len(letters), len(encoded))}
+	}
+
+	var probs []string
+	for i, c := range letters {
+		op := encoded[i]
+		ok := false
+		switch c {
+		case 'R', 'V', 'S', 'O':
+			ok = regs[i]
+		case 'M':
+			ok = !op.IsImm() && !op.IsRel()
+		case 'I':
+			ok = op.IsImm()
+		case 'D':
+			ok = op.IsRel()
+		}
+		if !ok {
+			probs = append(probs, fmt.Sprintf("encoding %c of %s doesn't agree with operand %s", c, inst.Encoding, op.Data))
+		}
+	}
+
+	return probs
+}
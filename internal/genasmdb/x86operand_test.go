@@ -0,0 +1,66 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoffsOffsetSize(t *testing.T) {
+	moffs, err := parseX86Operand("W:moff32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode         int
+		addrOverride bool
+		want         int
+	}{
+		{16, false, 2},
+		{16, true, 4},
+		{32, false, 4},
+		{32, true, 2},
+		{64, false, 8},
+		{64, true, 4},
+	}
+	for _, tt := range tests {
+		got, err := moffs.MoffsOffsetSize(tt.mode, tt.addrOverride)
+		if err != nil || got != tt.want {
+			t.Errorf("MoffsOffsetSize(%d, %t) = %d, %v, want %d", tt.mode, tt.addrOverride, got, err, tt.want)
+		}
+	}
+
+	if got, err := moffs.MoffsOffsetSize(8, false); err == nil {
+		t.Errorf("MoffsOffsetSize(8, false) = %d, want error", got)
+	}
+	mem, err := parseX86Operand("R:m32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := mem.MoffsOffsetSize(64, false); got != 0 || err != nil {
+		t.Errorf("MoffsOffsetSize of m32 = %d, %v, want 0", got, err)
+	}
+}
+
+func FuzzParseX86Operand(f *testing.F) {
+	for _, s := range []string{"W:r32", "X:~xmm {kz}", "R:m32fp", "vm32x", "ds:zsi", "xmm+3", "ib/ub", "~zmm/m512/b32 {er}", "W:moff32", "<xmm0>", "1"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		op, err := parseX86Operand(s)
+		if err != nil {
+			return
+		}
+		again, err := parseX86Operand(op.String())
+		if err != nil {
+			t.Fatalf("parseX86Operand(%q) of %q: %v", op.String(), s, err)
+		}
+		again.Data, again.Index = op.Data, op.Index
+		if !reflect.DeepEqual(op, again) {
+			t.Errorf("parseX86Operand(%q) = %+v, want %+v of %q", op.String(), again, op, s)
+		}
+	})
+}
@@ -0,0 +1,912 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// X86Syntax represents a flavor of the x86 assembly syntax.
+type X86Syntax uint8
+
+const (
+	// X86SyntaxIntel is the Intel syntax accepted by Keystone and the GNU assembler with ".intel_syntax noprefix".
+	X86SyntaxIntel X86Syntax = iota
+	// X86SyntaxATT is the AT&T syntax of the GNU toolchain.
+	X86SyntaxATT
+	// X86SyntaxNASM is the Intel syntax accepted by NASM.
+	X86SyntaxNASM
+)
+
+// String returns the name of the syntax.
+func (syntax X86Syntax) String() string {
+	switch syntax {
+	case X86SyntaxIntel:
+		return "intel"
+	case X86SyntaxATT:
+		return "att"
+	case X86SyntaxNASM:
+		return "nasm"
+	}
+	return "X86Syntax(" + strconv.Itoa(int(syntax)) + ")"
+}
+
+// x86Sample represents a concrete instruction synthesized from the instruction form
+// by choosing one kind of each explicit operand.
+type x86Sample struct {
+	name string
+	mode int // processor mode bits, 32 or 64
+	args []*x86Arg
+
+	// kinds is the chosen kinds of the explicit operands.
+	kinds []string
+
+	// er and sae are the trailing {er} and {sae} decorators.
+	er  bool
+	sae bool
+}
+
+// x86Arg represents a concrete operand of the x86Sample.
+type x86Arg struct {
+	typ X86OperandType
+
+	// reg is the register name of the register operand.
+	reg string
+
+	// seg, base and index are the segment override, base and index registers of the memory operand.
+	seg   string
+	base  string
+	index string
+	// size is the memory operand size in bytes, zero if the memory is unsized.
+	size int
+	// bcst is the element count of the broadcast memory operand, like 4 for {1to4}.
+	bcst int
+	// abs reports whether the memory operand is an absolute address.
+	abs bool
+
+	// imm is the literal of the immediate or relative operand.
+	imm string
+
+	// mask is the {k} mask register, and zero reports whether the {z} zeroing is used.
+	mask string
+	zero bool
+}
+
+// x86MemSizes maps the memory operand kind to its size in bytes.
+var x86MemSizes = map[string]int{
+	"m8":     1,
+	"m16":    2,
+	"m32":    4,
+	"m64":    8,
+	"m128":   16,
+	"m256":   32,
+	"m512":   64,
+	"m16int": 2,
+	"m32int": 4,
+	"m64int": 8,
+	"m32fp":  4,
+	"m64fp":  8,
+	"m80fp":  10,
+	"m80bcd": 10,
+	"m80dec": 10,
+	"m16_16": 4,
+	"m16_32": 6,
+	"m16_64": 10,
+	"moff8":  1,
+	"moff16": 2,
+	"moff32": 4,
+	"moff64": 8,
+}
+
+// x86VecSizes maps the vector register kind to its size in bytes.
+var x86VecSizes = map[string]int{
+	"xmm": 16,
+	"ymm": 32,
+	"zmm": 64,
+}
+
+// x86GPRegs is the pool of general purpose registers used for samples indexed by the size in bytes.
+//
+// The accumulator is reserved as the base register of memory operands.
+var x86GPRegs = map[int][]string{
+	1: {"bl", "cl", "dl"},
+	2: {"bx", "cx", "dx", "si", "di"},
+	4: {"ebx", "ecx", "edx", "esi", "edi"},
+	8: {"rbx", "rcx", "rdx", "rsi", "rdi"},
+}
+
+// x86RegAlloc allocates distinct sample registers.
+type x86RegAlloc struct {
+	gp int
+	n  map[string]int
+}
+
+// next returns the next register number of the class aligned to align.
+func (a *x86RegAlloc) next(class string, first, align int) int {
+	if a.n == nil {
+		a.n = make(map[string]int)
+	}
+	n, ok := a.n[class]
+	if !ok {
+		n = first
+	}
+	if r := n % align; r != 0 {
+		n += align - r
+	}
+	a.n[class] = n + align
+
+	return n
+}
+
+// gpReg returns the next general purpose register of size bytes.
+func (a *x86RegAlloc) gpReg(size int) string {
+	regs := x86GPRegs[size]
+	reg := regs[a.gp%len(regs)]
+	a.gp++
+
+	return reg
+}
+
+// x86Samples returns the samples of the instruction form for all combinations of the operand kinds in mode.
+func x86Samples(name string, ops []*X86Operand, mode int) []*x86Sample {
+	var explicit []*X86Operand
+	for _, op := range ops {
+		if !op.Implicit {
+			explicit = append(explicit, op)
+		}
+	}
+
+	var samples []*x86Sample
+	kinds := make([]string, len(explicit))
+	var walk func(i int)
+	walk = func(i int) {
+		if i == len(explicit) {
+			samples = append(samples, newX86Sample(name, explicit, kinds, mode))
+			return
+		}
+		for _, kind := range explicit[i].Kinds {
+			kinds[i] = kind
+			walk(i + 1)
+		}
+	}
+	walk(0)
+
+	return samples
+}
+
+// x86UnsizedMemNames is the list of the forms whose memory operand is written without the size,
+// the GNU assembler rejects the "zmmword ptr" of their "m512".
+var x86UnsizedMemNames = map[string]bool{
+	"movdir64b": true, "enqcmd": true, "enqcmds": true, "ldtilecfg": true, "sttilecfg": true,
+}
+
+// x86ST0Names is the list of the x87 forms whose "st(0)" destination is written before the "st(i)" source
+// although the form lists only "st(i)".
+var x86ST0Names = map[string]bool{
+	"fcmovb": true, "fcmovbe": true, "fcmove": true, "fcmovnb": true,
+	"fcmovnbe": true, "fcmovne": true, "fcmovnu": true, "fcmovu": true,
+}
+
+// newX86Sample returns the x86Sample of ops with the chosen kinds.
+func newX86Sample(name string, ops []*X86Operand, kinds []string, mode int) *x86Sample {
+	s := &x86Sample{
+		name:  name,
+		mode:  mode,
+		kinds: append([]string(nil), kinds...),
+	}
+
+	vecSize := 0
+	hasMem := false
+	addrMode := mode
+	for i, kind := range kinds {
+		if size := x86VecSize(ops[i]); size > vecSize {
+			vecSize = size
+		}
+		if x86KindType(kind)&X86OperandMem != 0 || x86IsBroadcast(kind) {
+			hasMem = true
+		}
+		if kind == "es:r32" {
+			// the address size follows the destination register, like "movdir64b r32, m512"
+			addrMode = 32
+		}
+	}
+
+	if x86ST0Names[name] {
+		s.args = append(s.args, &x86Arg{typ: X86OperandReg, reg: "st(0)"})
+	}
+	var alloc x86RegAlloc
+	for i, op := range ops {
+		// register groups, like "zmm+1, zmm+2, zmm+3", are the consecutive registers
+		// following the previous operand and are not written in the assembly
+		if strings.IndexByte(kinds[i], '+') >= 0 {
+			continue
+		}
+		align := 1
+		for j := i + 1; j < len(ops) && strings.IndexByte(kinds[j], '+') >= 0; j++ {
+			if align *= 2; align > 4 {
+				align = 4
+			}
+		}
+
+		// the broadcast element count follows the memory or vector size of the same operand,
+		// like 2 of "xmm[63:0]/m64/b32" and 4 of "xmm/m128/b32"
+		size := x86MemSize(op)
+		if size == 0 {
+			size = x86VecSize(op)
+		}
+		if size == 0 {
+			size = vecSize
+		}
+		arg := newX86Arg(&alloc, kinds[i], addrMode, size, align)
+		if arg.typ == X86OperandMem && x86UnsizedMemNames[name] {
+			arg.size = 0
+		}
+		for _, deco := range op.Decorators {
+			switch deco {
+			case "{k}":
+				arg.mask = "k1"
+			case "{kz}":
+				// zeroing-masking is not allowed with the memory and mask register destination
+				arg.mask = "k1"
+				arg.zero = arg.typ == X86OperandReg && x86RegClass(kinds[i]) != "k"
+			case "{er}":
+				s.er = !hasMem
+			case "{sae}":
+				s.sae = !hasMem
+			}
+		}
+		s.args = append(s.args, arg)
+	}
+
+	return s
+}
+
+// x86VecSize returns the largest vector size in bytes of the operand kinds.
+func x86VecSize(op *X86Operand) int {
+	size := 0
+	for _, kind := range op.Kinds {
+		n := x86VecSizes[x86RegClass(kind)]
+		if n == 0 && (kind == "m128" || kind == "m256" || kind == "m512") {
+			n = x86MemSizes[kind]
+		}
+		if n > size {
+			size = n
+		}
+	}
+	return size
+}
+
+// x86MemSize returns the largest memory size in bytes of the operand kinds.
+func x86MemSize(op *X86Operand) int {
+	size := 0
+	for _, kind := range op.Kinds {
+		if n := x86MemSizes[kind]; n > size {
+			size = n
+		}
+	}
+	return size
+}
+
+// x86IsBroadcast reports whether the kind is a broadcast memory, like "b32".
+func x86IsBroadcast(kind string) bool {
+	return len(kind) > 1 && kind[0] == 'b' && kind[1] >= '0' && kind[1] <= '9'
+}
+
+// x86RegClass returns the register class of the kind without the register group suffix, like "zmm" for "zmm+3".
+func x86RegClass(kind string) string {
+	if i := strings.IndexByte(kind, '+'); i >= 0 {
+		return kind[:i]
+	}
+	return kind
+}
+
+// newX86Arg returns the concrete x86Arg of the kind.
+//
+// The register number is aligned to align for the register groups.
+func newX86Arg(alloc *x86RegAlloc, kind string, mode, vecSize, align int) *x86Arg {
+	addr := "rax"
+	if mode == 32 {
+		addr = "eax"
+	}
+
+	switch {
+	case x86ImmKinds[kind]:
+		if kind == "1" {
+			return &x86Arg{typ: X86OperandImm, imm: "1"}
+		}
+		return &x86Arg{typ: X86OperandImm, imm: "0x1"}
+
+	case strings.HasPrefix(kind, "rel"):
+		return &x86Arg{typ: X86OperandRel, imm: "0x10"}
+
+	case x86IsBroadcast(kind):
+		elem, _ := strconv.Atoi(kind[1:])
+		arg := &x86Arg{typ: X86OperandMem, base: addr, size: elem / 8}
+		if vecSize > 0 {
+			arg.bcst = vecSize * 8 / elem
+		}
+		return arg
+
+	case strings.HasPrefix(kind, "vm"):
+		// VSIB memory, like "vm32x"
+		//
+		// The kind only specifies the index size, so the memory is left unsized.
+		class := map[byte]string{'x': "xmm", 'y': "ymm", 'z': "zmm"}[kind[len(kind)-1]]
+		return &x86Arg{
+			typ:   X86OperandMem,
+			base:  addr,
+			index: class + strconv.Itoa(alloc.next("vec", 1, 1)),
+		}
+
+	case kind == "mib", kind == "tmem":
+		return &x86Arg{typ: X86OperandMem, base: addr, index: strings.Replace(addr, "a", "c", 1)}
+
+	case strings.HasPrefix(kind, "moff"):
+		return &x86Arg{typ: X86OperandMem, size: x86MemSizes[kind], abs: true}
+
+	case strings.Contains(kind, ":"):
+		// segment:register, like "es:zdi" and "es:r64"
+		i := strings.IndexByte(kind, ':')
+		seg, reg := kind[:i], kind[i+1:]
+		switch reg {
+		case "r32":
+			return &x86Arg{typ: X86OperandReg, reg: alloc.gpReg(4)}
+		case "r64":
+			return &x86Arg{typ: X86OperandReg, reg: alloc.gpReg(8)}
+		}
+		reg = strings.TrimPrefix(reg, "z")
+		if mode == 32 {
+			reg = "e" + reg
+		} else {
+			reg = "r" + reg
+		}
+		arg := &x86Arg{typ: X86OperandMem, base: reg}
+		if seg != "ds" {
+			arg.seg = seg
+		}
+		return arg
+
+	case kind == "mem":
+		return &x86Arg{typ: X86OperandMem, base: addr}
+
+	case x86KindType(kind) == X86OperandMem:
+		return &x86Arg{typ: X86OperandMem, base: addr, size: x86MemSizes[kind]}
+	}
+
+	// register
+	class := x86RegClass(kind)
+	switch class {
+	case "r8":
+		return &x86Arg{typ: X86OperandReg, reg: alloc.gpReg(1)}
+	case "r16":
+		return &x86Arg{typ: X86OperandReg, reg: alloc.gpReg(2)}
+	case "r32":
+		return &x86Arg{typ: X86OperandReg, reg: alloc.gpReg(4)}
+	case "r64":
+		return &x86Arg{typ: X86OperandReg, reg: alloc.gpReg(8)}
+	case "xmm", "ymm", "zmm":
+		return &x86Arg{typ: X86OperandReg, reg: class + strconv.Itoa(alloc.next("vec", 1, align))}
+	case "k":
+		// k1 is reserved for the {k} mask
+		return &x86Arg{typ: X86OperandReg, reg: class + strconv.Itoa(alloc.next(class, 2, align))}
+	case "mm", "tmm", "bnd":
+		return &x86Arg{typ: X86OperandReg, reg: class + strconv.Itoa(alloc.next(class, 1, align))}
+	case "st(i)":
+		return &x86Arg{typ: X86OperandReg, reg: "st(1)"}
+	case "sreg":
+		return &x86Arg{typ: X86OperandReg, reg: "ds"}
+	case "creg":
+		return &x86Arg{typ: X86OperandReg, reg: "cr0"}
+	case "dreg":
+		return &x86Arg{typ: X86OperandReg, reg: "dr0"}
+	}
+
+	// fixed register, like "al" and "xmm0"
+	return &x86Arg{typ: X86OperandReg, reg: kind}
+}
+
+// x86IntelSizes maps the memory size in bytes to the Intel syntax size keyword.
+var x86IntelSizes = map[int]string{
+	1:  "byte",
+	2:  "word",
+	4:  "dword",
+	6:  "fword",
+	8:  "qword",
+	10: "tbyte",
+	16: "xmmword",
+	32: "ymmword",
+	64: "zmmword",
+}
+
+// Intel formats the sample in the Intel syntax accepted by Keystone and
+// the GNU assembler with ".intel_syntax noprefix".
+func (s *x86Sample) Intel() string {
+	args := make([]string, 0, len(s.args)+1)
+	for _, arg := range s.args {
+		args = append(args, arg.intel())
+	}
+	args = s.insertRC(args)
+
+	name := s.name
+	if (name == "lcall" || name == "ljmp") && len(s.args) == 1 && s.args[0].size == 6 {
+		// the far pointer of "m16_32" is the "fword ptr" of "call" and "jmp"
+		name = name[1:]
+	}
+	if len(args) == 0 {
+		return name
+	}
+	return name + " " + strings.Join(args, ", ")
+}
+
+// ATT formats the sample in the AT&T syntax accepted by the GNU assembler with the mnemonic.
+//
+// The operands are written in the reversed order of the Intel syntax.
+func (s *x86Sample) ATT(mnemonic string) string {
+	indirect := strings.HasSuffix(s.name, "jmp") || strings.HasSuffix(s.name, "call")
+	args := make([]string, 0, len(s.args)+1)
+	for _, arg := range s.args {
+		args = append(args, arg.att(indirect))
+	}
+	args = s.insertRC(args)
+	if x86ATTUnreversed[s.name] {
+		return mnemonic + " " + strings.Join(args, ", ")
+	}
+	for i, j := 0, len(args)-1; i < j; i, j = i+1, j-1 {
+		args[i], args[j] = args[j], args[i]
+	}
+	if (s.er || s.sae) && len(args) > 1 && strings.Contains(s.name, "si2s") {
+		// the integer source precedes the rounding control, like "vcvtsi2ssl %ebx, {rn-sae}, %xmm2, %xmm1"
+		args[0], args[1] = args[1], args[0]
+	}
+
+	if len(args) == 0 {
+		return mnemonic
+	}
+	return mnemonic + " " + strings.Join(args, ", ")
+}
+
+// NASM formats the sample in the Intel syntax accepted by NASM.
+//
+// NASM spells the far pointer instructions as "call far" and "jmp far" and the direct far
+// pointer as "segment:offset".
+func (s *x86Sample) NASM() string {
+	name, far := s.name, false
+	switch name {
+	case "lcall", "ljmp":
+		name, far = name[1:], true
+		if len(s.args) == 2 && s.args[0].typ == X86OperandImm && s.args[1].typ == X86OperandImm {
+			return name + " " + s.args[0].imm + ":" + s.args[1].imm
+		}
+	}
+
+	args := make([]string, 0, len(s.args)+1)
+	for _, arg := range s.args {
+		if far && arg.typ == X86OperandMem {
+			// the far pointer size is the offset size, like "dword far" of "m16_32"
+			ptr := *arg
+			ptr.size = 0
+			args = append(args, x86NASMFarSizes[s.kinds[0]]+" far "+ptr.nasm())
+			continue
+		}
+		args = append(args, arg.nasm())
+	}
+	args = s.insertRC(args)
+
+	if len(args) == 0 {
+		return name
+	}
+	return name + " " + strings.Join(args, ", ")
+}
+
+// insertRC inserts the {er} and {sae} decorators to the formatted args.
+func (s *x86Sample) insertRC(args []string) []string {
+	// the rounding control follows the last non-immediate operand
+	rc := ""
+	switch {
+	case s.er:
+		rc = "{rn-sae}"
+	case s.sae:
+		rc = "{sae}"
+	}
+	if rc != "" {
+		i := len(args)
+		for i > 0 && s.args[i-1].typ == X86OperandImm {
+			i--
+		}
+		args = append(args[:i], append([]string{rc}, args[i:]...)...)
+	}
+
+	return args
+}
+
+// intel formats the argument in the Intel syntax.
+func (arg *x86Arg) intel() string {
+	var sb strings.Builder
+	switch arg.typ {
+	case X86OperandReg:
+		sb.WriteString(arg.reg)
+	case X86OperandImm, X86OperandRel:
+		sb.WriteString(arg.imm)
+	case X86OperandMem:
+		if size, ok := x86IntelSizes[arg.size]; ok {
+			sb.WriteString(size + " ptr ")
+		}
+		if arg.seg != "" {
+			sb.WriteString(arg.seg + ":")
+		}
+		sb.WriteByte('[')
+		switch {
+		case arg.abs:
+			sb.WriteString("0x1000")
+		case arg.index != "":
+			sb.WriteString(arg.base + "+" + arg.index)
+		default:
+			sb.WriteString(arg.base)
+		}
+		sb.WriteByte(']')
+		if arg.bcst > 0 {
+			sb.WriteString("{1to" + strconv.Itoa(arg.bcst) + "}")
+		}
+	}
+	if arg.mask != "" {
+		sb.WriteString(" {" + arg.mask + "}")
+	}
+	if arg.zero {
+		sb.WriteString("{z}")
+	}
+
+	return sb.String()
+}
+
+// att formats the argument in the AT&T syntax.
+//
+// The register and memory operands of the indirect branch are prefixed with '*'.
+func (arg *x86Arg) att(indirect bool) string {
+	var sb strings.Builder
+	if indirect && (arg.typ == X86OperandReg || arg.typ == X86OperandMem) {
+		sb.WriteByte('*')
+	}
+	switch arg.typ {
+	case X86OperandReg:
+		sb.WriteString("%" + arg.reg)
+	case X86OperandImm:
+		sb.WriteString("$" + arg.imm)
+	case X86OperandRel:
+		sb.WriteString(arg.imm)
+	case X86OperandMem:
+		if arg.seg != "" {
+			sb.WriteString("%" + arg.seg + ":")
+		}
+		switch {
+		case arg.abs:
+			sb.WriteString("0x1000")
+		case arg.index != "":
+			sb.WriteString("(%" + arg.base + ",%" + arg.index + ")")
+		default:
+			sb.WriteString("(%" + arg.base + ")")
+		}
+		if arg.bcst > 0 {
+			sb.WriteString("{1to" + strconv.Itoa(arg.bcst) + "}")
+		}
+	}
+	if arg.mask != "" {
+		sb.WriteString(" {%" + arg.mask + "}")
+	}
+	if arg.zero {
+		sb.WriteString("{z}")
+	}
+
+	return sb.String()
+}
+
+// x86NASMSizes maps the memory size in bytes to the NASM size keyword.
+var x86NASMSizes = map[int]string{
+	1:  "byte",
+	2:  "word",
+	4:  "dword",
+	8:  "qword",
+	10: "tword",
+	16: "oword",
+	32: "yword",
+	64: "zword",
+}
+
+// x86NASMFarSizes maps the far pointer memory kind to the NASM size keyword.
+var x86NASMFarSizes = map[string]string{
+	"m16_16": "word",
+	"m16_32": "dword",
+	"m16_64": "qword",
+}
+
+// nasm formats the argument in the NASM syntax.
+func (arg *x86Arg) nasm() string {
+	var sb strings.Builder
+	switch arg.typ {
+	case X86OperandReg:
+		// the x87 registers are written without the parentheses, like "st1"
+		sb.WriteString(strings.NewReplacer("(", "", ")", "").Replace(arg.reg))
+	case X86OperandImm, X86OperandRel:
+		sb.WriteString(arg.imm)
+	case X86OperandMem:
+		if size, ok := x86NASMSizes[arg.size]; ok {
+			sb.WriteString(size + " ")
+		}
+		sb.WriteByte('[')
+		if arg.seg != "" {
+			sb.WriteString(arg.seg + ":")
+		}
+		switch {
+		case arg.abs:
+			sb.WriteString("abs 0x1000")
+		case arg.index != "":
+			sb.WriteString(arg.base + "+" + arg.index)
+		default:
+			sb.WriteString(arg.base)
+		}
+		sb.WriteByte(']')
+		if arg.bcst > 0 {
+			sb.WriteString("{1to" + strconv.Itoa(arg.bcst) + "}")
+		}
+	}
+	if arg.mask != "" {
+		sb.WriteString("{" + arg.mask + "}")
+	}
+	if arg.zero {
+		sb.WriteString("{z}")
+	}
+
+	return sb.String()
+}
+
+// x86ATTSuffixes maps the operand kind to the AT&T mnemonic size suffix.
+var x86ATTSuffixes = map[string]string{
+	"r8":     "b",
+	"m8":     "b",
+	"r16":    "w",
+	"m16":    "w",
+	"r32":    "l",
+	"m32":    "l",
+	"r64":    "q",
+	"m64":    "q",
+	"m32fp":  "s",
+	"m64fp":  "l",
+	"m80fp":  "t",
+	"m16int": "s",
+	"m32int": "l",
+	"m64int": "ll",
+	"m16_16": "w",
+	"m16_32": "l",
+	"m16_64": "q",
+}
+
+// x86ATTNames maps the Intel mnemonic to the AT&T mnemonic which differs regardless of the operands.
+var x86ATTNames = map[string]string{
+	"cmpsd":  "cmpsl",
+	"insd":   "insl",
+	"lodsd":  "lodsl",
+	"movsd":  "movsl",
+	"outsd":  "outsl",
+	"scasd":  "scasl",
+	"stosd":  "stosl",
+	"iret":   "iretw",
+	"iretd":  "iretl",
+	"popad":  "popal",
+	"popfd":  "popfl",
+	"pushad": "pushal",
+	"pushfd": "pushfl",
+	"retf":   "lret",
+}
+
+// x86ATTUnreversed is a list of the mnemonics whose operands are written in the Intel order
+// in the AT&T syntax by the GNU assembler.
+var x86ATTUnreversed = map[string]bool{
+	"bound": true,
+}
+
+// x86ATTReversed is a list of the x87 mnemonics that are swapped in the AT&T syntax
+// when the destination is st(i), known as the AT&T fsub/fsubr bug kept by the GNU assembler.
+var x86ATTReversed = map[string]string{
+	"fsub":   "fsubr",
+	"fsubr":  "fsub",
+	"fdiv":   "fdivr",
+	"fdivr":  "fdiv",
+	"fsubp":  "fsubrp",
+	"fsubrp": "fsubp",
+	"fdivp":  "fdivrp",
+	"fdivrp": "fdivp",
+}
+
+// x86ATTVecSuffixes maps the memory size in bytes to the AT&T mnemonic suffix of
+// the vector instructions whose memory operand size can't be inferred from the registers.
+var x86ATTVecSuffixes = map[int]string{
+	16: "x",
+	32: "y",
+	64: "z",
+}
+
+// X86Formatter formats the x86 instruction samples in the syntax flavor.
+type X86Formatter struct {
+	Syntax X86Syntax
+
+	// sized is a set of the instruction names that have the operands of multiple sizes,
+	// so the AT&T mnemonic needs the size suffix unless a register tells the size.
+	sized map[string]bool
+
+	// vecSized is a set of the vector instruction names, destination register classes and memory operand
+	// positions that have the memory of multiple sizes, like "vcvtpd2dq xmm, m128" and "vcvtpd2dq xmm, m256".
+	vecSized map[string]bool
+}
+
+// NewX86Formatter returns the X86Formatter of the syntax for insts.
+func NewX86Formatter(syntax X86Syntax, insts []X86Instruction) (*X86Formatter, error) {
+	suffixes := make(map[string]map[string]bool)
+	vecSizes := make(map[string]map[int]bool)
+	for i := range insts {
+		inst := &insts[i]
+		ops, err := inst.ParseOperands()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inst.Name, err)
+		}
+		for _, name := range inst.Names() {
+			if len(ops) > 1 && !ops[0].Implicit {
+				for _, dst := range ops[0].Kinds {
+					for j, op := range ops[1:] {
+						key := x86VecSizedKey(name, dst, j+1)
+						for _, kind := range op.Kinds {
+							if size := x86MemSizes[kind]; size >= 16 {
+								if vecSizes[key] == nil {
+									vecSizes[key] = make(map[int]bool)
+								}
+								vecSizes[key][size] = true
+							}
+						}
+					}
+				}
+			}
+			for _, op := range ops {
+				if op.Implicit {
+					continue
+				}
+				for _, kind := range op.Kinds {
+					suffix, ok := x86ATTSuffixes[kind]
+					if !ok {
+						continue
+					}
+					if suffixes[name] == nil {
+						suffixes[name] = make(map[string]bool)
+					}
+					suffixes[name][suffix] = true
+				}
+			}
+		}
+	}
+
+	f := &X86Formatter{
+		Syntax:   syntax,
+		sized:    make(map[string]bool),
+		vecSized: make(map[string]bool),
+	}
+	for name, set := range suffixes {
+		f.sized[name] = len(set) > 1
+	}
+	for key, set := range vecSizes {
+		f.vecSized[key] = len(set) > 1
+	}
+
+	return f, nil
+}
+
+// Format formats the sample in the syntax.
+func (f *X86Formatter) Format(s *x86Sample) string {
+	switch f.Syntax {
+	case X86SyntaxATT:
+		return s.ATT(f.ATTMnemonic(s.name, s.kinds))
+	case X86SyntaxNASM:
+		return s.NASM()
+	}
+	return s.Intel()
+}
+
+// ATTMnemonic returns the AT&T mnemonic of the instruction name with the chosen kinds of the explicit operands.
+//
+// The size suffix is added if the instruction has the operands of multiple sizes and no general purpose
+// register tells the size, like "addl" of "add m32, id", same as the GNU disassembler.
+func (f *X86Formatter) ATTMnemonic(name string, kinds []string) string {
+	switch name {
+	case "movsxd":
+		// "movslq" sign extends to 64 bits, "movsxd" of the same sizes is kept
+		if len(kinds) == 2 && kinds[0] == "r64" {
+			return "movslq"
+		}
+		return name
+	case "movsx", "movzx":
+		// the source and destination suffixes, like "movzbl", unless the sizes are the same
+		if len(kinds) == 2 {
+			src, dst := x86ATTSuffixes[kinds[1]], x86ATTSuffixes[kinds[0]]
+			if src == dst {
+				return name
+			}
+			return name[:4] + src + dst
+		}
+	case "crc32":
+		// the source suffix, like "crc32b"
+		if len(kinds) == 2 {
+			return name + x86ATTSuffixes[kinds[1]]
+		}
+	}
+	if strings.Contains(name, "si2s") {
+		// the integer source size, like "cvtsi2sdq"
+		for _, kind := range kinds {
+			if suffix, ok := x86ATTSuffixes[kind]; ok {
+				return name + suffix
+			}
+		}
+	}
+
+	if x86HasVecKind(kinds) {
+		for i, kind := range kinds {
+			if i > 0 && x86MemSizes[kind] >= 16 && f.vecSized[x86VecSizedKey(name, kinds[0], i)] {
+				return name + x86ATTVecSuffix(kinds)
+			}
+		}
+		return name
+	}
+	if att, ok := x86ATTNames[name]; ok {
+		return att
+	}
+	if att, ok := x86ATTReversed[name]; ok && (len(kinds) == 0 || kinds[0] == "st(i)") {
+		return att
+	}
+
+	if !f.sized[name] {
+		return name
+	}
+	for _, kind := range kinds {
+		switch kind {
+		case "r8", "r16", "r32", "r64", "al", "ax", "eax", "rax":
+			return name
+		}
+	}
+	for _, kind := range kinds {
+		if suffix, ok := x86ATTSuffixes[kind]; ok {
+			return name + suffix
+		}
+	}
+
+	return name
+}
+
+// x86HasVecKind reports whether the kinds have a vector register or a vector memory.
+func x86HasVecKind(kinds []string) bool {
+	for _, kind := range kinds {
+		switch x86RegClass(kind) {
+		case "mm", "xmm", "ymm", "zmm", "k", "tmm", "bnd", "m128", "m256", "m512":
+			return true
+		}
+	}
+	return false
+}
+
+// x86VecSizedKey returns the key of X86Formatter.vecSized.
+func x86VecSizedKey(name, dst string, index int) string {
+	return name + " " + x86RegClass(dst) + " " + strconv.Itoa(index)
+}
+
+// x86ATTVecSuffix returns the suffix of the memory size if the memory operand is the only vector source,
+// like "x" of "vcvtpd2dq xmm, m128".
+func x86ATTVecSuffix(kinds []string) string {
+	size := 0
+	for _, kind := range kinds[1:] {
+		switch {
+		case x86VecSizes[x86RegClass(kind)] > 0:
+			return ""
+		case x86MemSizes[kind] >= 16:
+			size = x86MemSizes[kind]
+		}
+	}
+
+	return x86ATTVecSuffixes[size]
+}
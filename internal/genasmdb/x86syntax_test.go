@@ -0,0 +1,214 @@
+// Copyright 2012 The Go Asm Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestATTMnemonic(t *testing.T) {
+	insts := []X86Instruction{
+		{Name: "add", Operands: "X:r8/m8, ib/ub"},
+		{Name: "add", Operands: "X:r32/m32, id/ud"},
+		{Name: "movzx", Operands: "W:r32, r8/m8"},
+		{Name: "movsx", Operands: "W:r64, r16/m16"},
+		{Name: "movsxd", Operands: "W:r16, r16/m16"},
+		{Name: "movsxd", Operands: "W:r32, r32/m32"},
+		{Name: "movsxd", Operands: "W:r64, r32/m32"},
+		{Name: "crc32", Operands: "X:r32, r8/m8"},
+		{Name: "fsub", Operands: "st(i), st(0)"},
+	}
+	f, err := NewX86Formatter(X86SyntaxATT, insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		kinds []string
+		want  string
+	}{
+		{"add", []string{"m32", "id"}, "addl"},
+		{"add", []string{"r32", "id"}, "add"},
+		{"movzx", []string{"r32", "m8"}, "movzbl"},
+		{"movsx", []string{"r64", "r16"}, "movswq"},
+		{"movzx", []string{"r16", "r16"}, "movzx"},
+		{"movsxd", []string{"r16", "r16"}, "movsxd"},
+		{"movsxd", []string{"r32", "m32"}, "movsxd"},
+		{"movsxd", []string{"r64", "r32"}, "movslq"},
+		{"crc32", []string{"r32", "m8"}, "crc32b"},
+		{"cmpsd", nil, "cmpsl"},
+		{"fsub", []string{"st(i)"}, "fsubr"},
+	}
+	for _, tt := range tests {
+		if got := f.ATTMnemonic(tt.name, tt.kinds); got != tt.want {
+			t.Errorf("ATTMnemonic(%q, %q) = %q, want %q", tt.name, tt.kinds, got, tt.want)
+		}
+	}
+}
+
+// gasKnownFailures is the list of the forms whose samples the GNU assembler rejects, with the reason.
+var gasKnownFailures = map[string]string{
+	// the operands of the asmdb data differ from the manuals
+	"vpcmpw W:k {k}, xmm, xmm/m128/b64, ib/ub":      "the word comparisons have no broadcast",
+	"vpcmpw W:k {k}, ymm, ymm/m256/b64, ib/ub":      "the word comparisons have no broadcast",
+	"vpcmpw W:k {k}, zmm, zmm/m512/b64, ib/ub":      "the word comparisons have no broadcast",
+	"vpcmpuw W:k {k}, xmm, xmm/m128/b64, ib/ub":     "the word comparisons have no broadcast",
+	"vpcmpuw W:k {k}, ymm, ymm/m256/b64, ib/ub":     "the word comparisons have no broadcast",
+	"vpcmpuw W:k {k}, zmm, zmm/m512/b64, ib/ub":     "the word comparisons have no broadcast",
+	"vpconflictq W:xmm {kz}, xmm/m128/b32":          "the quadword broadcast is b64",
+	"vpconflictq W:ymm {kz}, ymm/m256/b32":          "the quadword broadcast is b64",
+	"vpconflictq W:zmm {kz}, zmm/m512/b32":          "the quadword broadcast is b64",
+	"vpinsrb W:xmm {kz}, xmm, r32[7:0]/m8, ib/ub":   "the inserts have no masking",
+	"vpinsrw W:xmm {kz}, xmm, r32[15:0]/m16, ib/ub": "the inserts have no masking",
+	"vpinsrd W:xmm {kz}, xmm, r32/m32, ib/ub":       "the inserts have no masking",
+	"vpinsrq W:xmm {kz}, xmm, r64/m64, ib/ub":       "the inserts have no masking",
+	"vmovw W:xmm[15:0] {kz}, r32[15:0]/m16":         "vmovw has no masking",
+	"vcvtps2pd W:zmm {kz}, ymm/m256/b32 {er}":       "the widening conversion has {sae}, not {er}",
+	"vcvtsi2sd W:xmm, xmm[127:64], r32/m32 {er}":    "the exact 32-bit conversion has no rounding",
+	"vcvtusi2sd W:xmm, xmm[127:64], r32/m32 {er}":   "the exact 32-bit conversion has no rounding",
+	"wrssd W:r32/m32, r32":                          "the shadow stack store has only the memory destination",
+	"wrssq W:r64/m64, r64":                          "the shadow stack store has only the memory destination",
+	"wrussd W:r32/m32, r32":                         "the shadow stack store has only the memory destination",
+	"wrussq W:r64/m64, r64":                         "the shadow stack store has only the memory destination",
+	"lsl W:r64, R:r32/m16":                          "the register source of the REX.W form is r64",
+	"movsxd W:r16, r16/m16":                         "the 16-bit source can't be written, the GNU assembler reads m32",
+
+	// the encodings the GNU assembler can't write
+	"bswap X:r16":          "the 16-bit bswap is undefined",
+	"nop R:r16/m16, r16":   "the hint nop with the register operand",
+	"nop R:r32/m32, r32":   "the hint nop with the register operand",
+	"nop R:r64/m64, r64":   "the hint nop with the register operand",
+	"lcall R:m16_16":       "the 16-bit far pointer is the near \"dword ptr\" in the Intel syntax",
+	"ljmp R:m16_16":        "the 16-bit far pointer is the near \"dword ptr\" in the Intel syntax",
+	"lcall R:m16_64":       "the REX.W far pointer is written only with \"rex64\"",
+	"ljmp R:m16_64":        "the REX.W far pointer is written only with \"rex64\"",
+	"lfs X:r64, m16_64":    "the REX.W far pointer is written only with \"rex64\"",
+	"lgs X:r64, m16_64":    "the REX.W far pointer is written only with \"rex64\"",
+	"lss X:r64, m16_64":    "the REX.W far pointer is written only with \"rex64\"",
+	"sysexit ":             "the operand size is ambiguous in the Intel syntax",
+	"sysret ":              "the operand size is ambiguous in the Intel syntax",
+	"jecxz R:<cx>, rel8":   "the numeric target is rejected in the Intel syntax",
+	"jecxz R:<ecx>, rel8":  "the numeric target is rejected in the Intel syntax",
+	"jecxz R:<rcx>, rel8":  "the numeric target is rejected in the Intel syntax",
+	"loop x:<cx>, rel8":    "the numeric target is rejected in the Intel syntax",
+	"loop X:<ecx>, rel8":   "the numeric target is rejected in the Intel syntax",
+	"loop X:<rcx>, rel8":   "the numeric target is rejected in the Intel syntax",
+	"loope x:<cx>, rel8":   "the numeric target is rejected in the Intel syntax",
+	"loope X:<ecx>, rel8":  "the numeric target is rejected in the Intel syntax",
+	"loope X:<rcx>, rel8":  "the numeric target is rejected in the Intel syntax",
+	"loopne x:<cx>, rel8":  "the numeric target is rejected in the Intel syntax",
+	"loopne X:<ecx>, rel8": "the numeric target is rejected in the Intel syntax",
+	"loopne X:<rcx>, rel8": "the numeric target is rejected in the Intel syntax",
+
+	// the instructions unknown to the GNU assembler 2.40
+	"pfrcpv X:mm, mm/m64":   "Geode",
+	"pfrsqrtv X:mm, mm/m64": "Geode",
+	"seamcall ":             "TDX",
+	"seamops ":              "TDX",
+	"seamret ":              "TDX",
+}
+
+// gasErrorRe matches the GNU assembler error message, like "{standard input}:12: Error: operand size mismatch".
+var gasErrorRe = regexp.MustCompile(`^[^:]*:(\d+): Error: (.*)$`)
+
+// gasSample represents a sample assembled by the GNU assembler.
+type gasSample struct {
+	form string
+	asm  string
+}
+
+// runGAS assembles the samples in the mode and syntax and returns the error message of each sample.
+func runGAS(t *testing.T, as string, mode int, syntax X86Syntax, samples []*gasSample) []string {
+	var src bytes.Buffer
+	header := 0
+	if mode == 32 {
+		src.WriteString(".code32\n")
+		header++
+	}
+	if syntax == X86SyntaxIntel {
+		src.WriteString(".intel_syntax noprefix\n")
+		header++
+	}
+	for _, s := range samples {
+		src.WriteString(s.asm + "\n")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(as, "--64", "-o", filepath.Join(t.TempDir(), "x.o"), "-")
+	cmd.Stdin, cmd.Stderr = &src, &stderr
+	err := cmd.Run()
+
+	errs := make([]string, len(samples))
+	failed := false
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		m := gasErrorRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		if i := n - 1 - header; i >= 0 && i < len(samples) && errs[i] == "" {
+			errs[i], failed = m[2], true
+		}
+	}
+	if err != nil && !failed {
+		t.Fatalf("%s: %v: %s", as, err, stderr.Bytes())
+	}
+	return errs
+}
+
+func TestX86SamplesGAS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("assembling all the samples is slow")
+	}
+	as, err := exec.LookPath("as")
+	if err != nil {
+		t.Skip("the GNU assembler isn't installed")
+	}
+	data, _, err := readData("", asmdbX86DataJS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := decodeX86Model(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, syntax := range []X86Syntax{X86SyntaxATT, X86SyntaxIntel} {
+		f, err := NewX86Formatter(syntax, m.X86Instructions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mode := range []int{32, 64} {
+			var samples []*gasSample
+			for i := range m.X86Instructions {
+				inst := &m.X86Instructions[i]
+				if !containsInt(x86FormModes(m.X86, inst), mode) {
+					continue
+				}
+				ops, err := inst.ParseOperands()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, name := range inst.Names() {
+					for _, sample := range x86Samples(name, ops, mode) {
+						samples = append(samples, &gasSample{form: inst.Name + " " + inst.Operands, asm: f.Format(sample)})
+					}
+				}
+			}
+
+			for i, reason := range runGAS(t, as, mode, syntax, samples) {
+				if s := samples[i]; reason != "" && gasKnownFailures[s.form] == "" {
+					t.Errorf("%s: bits %d: %s: %q: %s", syntax, mode, s.form, s.asm, reason)
+				}
+			}
+		}
+	}
+}